@@ -27,11 +27,22 @@ import (
 
 var update = flag.Bool("update", false, "update .golden files")
 
+// testdata is the directory holding the .golden files. It is resolved when
+// the tests start, so it is not affected by tests which change the working
+// directory.
+var testdata = func() string {
+	wd, err := os.Getwd()
+	if err != nil {
+		panic(err)
+	}
+	return filepath.Join(wd, "testdata")
+}()
+
 // Get returns the content of the specified .golden file. If the '-update' flag is
 // set (e.g. `go test ./pkg/... -update`), the specified .golden file is updated
 // with the current output, then the output is returned.
 func Get(t *testing.T, actual []byte, filename string) []byte {
-	golden := filepath.Join("testdata", filename)
+	golden := filepath.Join(testdata, filename)
 	if *update {
 		if err := os.WriteFile(golden, actual, 0644); err != nil {
 			t.Fatal(err)
@@ -0,0 +1,96 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+	"github.com/vapor-ware/synse-client-go/synse"
+	synsegrpc "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// FakeClients implements the clients.Factory interface to allow commands
+// to be tested with fake clients instead of clients created from the CLI
+// configuration.
+type FakeClients struct {
+	// Server is the client returned for HTTP and WebSocket clients.
+	Server synse.Client
+
+	// Plugin is the client returned for gRPC clients.
+	Plugin synsegrpc.V3PluginClient
+
+	// Err, if set, is returned when creating any client.
+	Err error
+}
+
+// HTTP returns the fake server client.
+func (f *FakeClients) HTTP() (synse.Client, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	if f.Server == nil {
+		return nil, errors.New("no fake server client")
+	}
+	return f.Server, nil
+}
+
+// WebSocket returns the fake server client.
+func (f *FakeClients) WebSocket() (synse.Client, error) {
+	return f.HTTP()
+}
+
+// GRPC returns the fake plugin client, along with a connection for it.
+func (f *FakeClients) GRPC() (*grpc.ClientConn, synsegrpc.V3PluginClient, error) {
+	if f.Err != nil {
+		return nil, nil, f.Err
+	}
+	if f.Plugin == nil {
+		return nil, nil, errors.New("no fake plugin client")
+	}
+	return NewFakeConn(), f.Plugin, nil
+}
+
+// ContextClients implements the clients.ContextFactory interface to allow
+// commands which are run against multiple contexts to be tested with fake
+// clients for each context.
+type ContextClients map[string]*FakeClients
+
+// ForContext gets the fake clients for the named context.
+func (c ContextClients) ForContext(name string) clients.Factory {
+	if f, ok := c[name]; ok {
+		return f
+	}
+	return &FakeClients{Err: fmt.Errorf("no fake clients for context '%s'", name)}
+}
+
+// HTTP returns the fake server client for the unnamed context.
+func (c ContextClients) HTTP() (synse.Client, error) {
+	return c.ForContext("").HTTP()
+}
+
+// WebSocket returns the fake server client for the unnamed context.
+func (c ContextClients) WebSocket() (synse.Client, error) {
+	return c.ForContext("").WebSocket()
+}
+
+// GRPC returns the fake plugin client for the unnamed context.
+func (c ContextClients) GRPC() (*grpc.ClientConn, synsegrpc.V3PluginClient, error) {
+	return c.ForContext("").GRPC()
+}
@@ -0,0 +1,107 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// ConfigDirs holds the temporary directories which config files are looked
+// up in for a test.
+type ConfigDirs struct {
+	Home string
+	XDG  string
+	WD   string
+}
+
+// NewConfigDirs sets up temporary home, XDG config and working directories,
+// so the config files which are loaded do not depend on the environment the
+// tests are run in. The original working directory and config are restored
+// when the test completes.
+func NewConfigDirs(t *testing.T) ConfigDirs {
+	dirs := ConfigDirs{
+		Home: t.TempDir(),
+		XDG:  t.TempDir(),
+	}
+	t.Setenv("HOME", dirs.Home)
+	t.Setenv("XDG_CONFIG_HOME", dirs.XDG)
+	t.Setenv(config.EnvConfigFile, "")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(wd); err != nil {
+			t.Fatal(err)
+		}
+		config.SetPath("")
+		config.Unload()
+	})
+
+	// The working directory is reported as the OS resolves it, which may
+	// differ from the temp dir path (e.g. via symlinks).
+	if dirs.WD, err = os.Getwd(); err != nil {
+		t.Fatal(err)
+	}
+	return dirs
+}
+
+// WriteConfig writes a config file with the given contents, returning its path.
+func WriteConfig(t *testing.T, path, contents string) string {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// AddContexts adds the contexts to the CLI configuration, and then sets each
+// of the named contexts as the current context of its type.
+func AddContexts(t *testing.T, ctxs []config.ContextRecord, current ...string) {
+	for _, ctx := range ctxs {
+		ctx := ctx
+		if err := config.AddContext(&ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, name := range current {
+		if err := config.SetCurrentContext(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// UseCacheDir replaces the function which gets the user cache directory,
+// so that a temporary directory is used for the duration of the test. The
+// directory is returned.
+func UseCacheDir(t *testing.T, userCacheDir *func() (string, error)) string {
+	dir := t.TempDir()
+	original := *userCacheDir
+	*userCacheDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { *userCacheDir = original })
+	return dir
+}
@@ -18,13 +18,17 @@ package test
 
 import (
 	"bytes"
+	"context"
+	"io"
 	"os"
+	"strings"
 	"testing"
 
 	"bou.ke/monkey"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-cli/internal/golden"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 )
 
 type Result struct {
@@ -46,16 +50,23 @@ func (r *Result) AssertGolden(filename string) {
 	golden.Check(r.t, r.out, filename)
 }
 
+// Out gets the output of the command run.
+func (r *Result) Out() []byte {
+	return r.out
+}
+
 func (r *Result) AssertExited() {
 	assert.True(r.t, r.exited)
 }
 
 type Builder struct {
-	cmd  *cobra.Command
-	root string
-	name string
-	args []string
-	t    *testing.T
+	cmd     *cobra.Command
+	root    string
+	name    string
+	args    []string
+	clients clients.Factory
+	in      io.Reader
+	t       *testing.T
 }
 
 func (b *Builder) Args(args ...string) *Builder {
@@ -68,12 +79,25 @@ func (b *Builder) WithRoot(root string) *Builder {
 	return b
 }
 
+// Input sets the input the command reads from, e.g. the answers to prompts.
+func (b *Builder) Input(in string) *Builder {
+	b.in = strings.NewReader(in)
+	return b
+}
+
+// WithClients sets the factory the command uses to create clients.
+func (b *Builder) WithClients(factory clients.Factory) *Builder {
+	b.clients = factory
+	return b
+}
+
 func (b *Builder) Run(t *testing.T) (result *Result) {
 	b.t = t
 
 	cmdOut := bytes.Buffer{}
 	b.cmd.SetErr(&cmdOut)
 	b.cmd.SetOut(&cmdOut)
+	b.cmd.SetIn(b.in)
 
 	var exitCalled bool
 	patch := monkey.Patch(os.Exit, func(code int) {
@@ -113,6 +137,14 @@ func (b *Builder) Run(t *testing.T) (result *Result) {
 	}
 	args = append(args, b.args...)
 
+	// Always set the command context, so a client factory set for a
+	// previous run of the command does not carry over.
+	ctx := context.Background()
+	if b.clients != nil {
+		ctx = clients.WithFactory(ctx, b.clients)
+	}
+	b.cmd.SetContext(ctx)
+
 	os.Args = args
 	err := b.cmd.Execute()
 	result = &Result{
@@ -132,3 +164,16 @@ func Cmd(cmd *cobra.Command) *Builder {
 		args: []string{cmd.Name()},
 	}
 }
+
+// AddToRoot creates the root command for a command group (e.g. the 'server'
+// command) with newRoot, which adds the command to it, so that the persistent
+// flags defined on the root can be used. The command is removed from the
+// root when the test completes. The command must be run with the root's name
+// (see Builder.WithRoot).
+func AddToRoot(t *testing.T, newRoot func() *cobra.Command, cmd *cobra.Command) *cobra.Command {
+	root := newRoot()
+	t.Cleanup(func() {
+		root.RemoveCommand(root.Commands()...)
+	})
+	return cmd
+}
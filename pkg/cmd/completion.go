@@ -112,8 +112,11 @@ __custom_func() {
 			__synse_server_list_plugins
 			return
 			;;
+		synse_context_copy | \
 		synse_context_remove | \
-		synse_context_set)
+		synse_context_rename | \
+		synse_context_set | \
+		synse_context_update)
 			__synse_list_ctxs
 			return
 			;;
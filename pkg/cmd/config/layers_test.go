@@ -0,0 +1,245 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"gopkg.in/yaml.v2"
+)
+
+const homeConfig = `
+contexts:
+- name: home-only
+  type: server
+  context:
+    address: localhost:5000
+- name: shared
+  type: server
+  context:
+    address: home:5000
+current_context:
+  server: home-only
+`
+
+const projectConfig = `
+contexts:
+- name: shared
+  type: server
+  context:
+    address: project:5000
+- name: project-only
+  type: plugin
+  context:
+    address: localhost:5001
+current_context:
+  plugin: project-only
+`
+
+// setupLayers writes a home and project config file, returning their paths.
+func setupLayers(t *testing.T) (string, string) {
+	dirs := test.NewConfigDirs(t)
+	home := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), homeConfig)
+	project := test.WriteConfig(t, filepath.Join(dirs.WD, ".synse.yml"), projectConfig)
+	assert.NoError(t, config.Load())
+	return home, project
+}
+
+// readConfig reads the config file at the path, without merging it.
+func readConfig(t *testing.T, path string) config.Config {
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+
+	var c config.Config
+	assert.NoError(t, yaml.Unmarshal(data, &c))
+	return c
+}
+
+// contextNames gets the names of the contexts in a config file, with their
+// addresses.
+func contextNames(c config.Config) map[string]string {
+	names := map[string]string{}
+	for _, ctx := range c.Contexts {
+		names[ctx.Name] = ctx.Context.Address
+	}
+	return names
+}
+
+func TestLoad_mergesLayers(t *testing.T) {
+	setupLayers(t)
+
+	assert.Len(t, config.GetContexts(), 3)
+	assert.Equal(t, "project:5000", config.GetContext("shared").Context.Address)
+	assert.Equal(t, "home", config.SourceLayer(config.GetContext("home-only")))
+	assert.Equal(t, "project", config.SourceLayer(config.GetContext("shared")))
+	assert.Equal(t, "project", config.SourceLayer(config.GetContext("project-only")))
+
+	current := config.GetCurrentContext()
+	assert.Equal(t, "home-only", current["server"].Name)
+	assert.Equal(t, "project-only", current["plugin"].Name)
+}
+
+func TestLoad_envOverridesProject(t *testing.T) {
+	setupLayers(t)
+	env := test.WriteConfig(t, filepath.Join(t.TempDir(), "env.yml"), `
+contexts:
+- name: shared
+  type: server
+  context:
+    address: env:5000
+current_context:
+  server: shared
+`)
+	t.Setenv(config.EnvConfigFile, env)
+	assert.NoError(t, config.Load())
+
+	assert.Len(t, config.GetContexts(), 3)
+	assert.Equal(t, "env:5000", config.GetContext("shared").Context.Address)
+	assert.Equal(t, "env", config.SourceLayer(config.GetContext("shared")))
+	assert.Equal(t, "shared", config.GetCurrentContext()["server"].Name)
+}
+
+func TestPersist_writesToSourceLayer(t *testing.T) {
+	home, project := setupLayers(t)
+
+	config.RemoveContext("home-only")
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "new",
+		Type: "plugin",
+		Context: config.Context{
+			Address: "localhost:5002",
+		},
+	}))
+	assert.NoError(t, config.Persist())
+
+	// The context removed from the home layer is removed from its file, and
+	// the context shadowed by the project layer is kept unchanged.
+	homeFile := readConfig(t, home)
+	assert.Equal(t, map[string]string{"shared": "home:5000"}, contextNames(homeFile))
+	assert.Empty(t, homeFile.CurrentContext)
+
+	// New contexts are written to the highest precedence layer.
+	projectFile := readConfig(t, project)
+	assert.Equal(t, map[string]string{
+		"shared":       "project:5000",
+		"project-only": "localhost:5001",
+		"new":          "localhost:5002",
+	}, contextNames(projectFile))
+	assert.Equal(t, map[string]string{"plugin": "project-only"}, projectFile.CurrentContext)
+}
+
+func TestPersist_editKeepsSourceLayer(t *testing.T) {
+	home, project := setupLayers(t)
+
+	ctx := config.GetContext("home-only")
+	ctx.Source = ""
+	ctx.Context.Address = "localhost:6000"
+	assert.Empty(t, config.ReplaceContexts([]string{"home-only"}, []config.ContextRecord{*ctx}))
+	assert.NoError(t, config.Persist())
+
+	assert.Equal(t, map[string]string{
+		"home-only": "localhost:6000",
+		"shared":    "home:5000",
+	}, contextNames(readConfig(t, home)))
+	assert.Len(t, readConfig(t, project).Contexts, 2)
+}
+
+func TestPersist_currentContext(t *testing.T) {
+	home, project := setupLayers(t)
+
+	// The current server context is set in the home layer, so it is changed
+	// there. The current plugin context is unset, so it is removed.
+	assert.NoError(t, config.SetCurrentContext("shared"))
+	assert.NoError(t, config.UnsetCurrentContext("project-only"))
+	assert.NoError(t, config.Persist())
+
+	assert.Equal(t, map[string]string{"server": "shared"}, readConfig(t, home).CurrentContext)
+	assert.Empty(t, readConfig(t, project).CurrentContext)
+
+	assert.NoError(t, config.Load())
+	current := config.GetCurrentContext()
+	assert.Len(t, current, 1)
+	assert.Equal(t, "shared", current["server"].Name)
+}
+
+func TestPersist_currentContextFromHigherLayer(t *testing.T) {
+	home, project := setupLayers(t)
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "project-server",
+		Type: "server",
+		Context: config.Context{
+			Address: "project:5002",
+		},
+	}))
+	assert.NoError(t, config.Persist())
+	assert.NoError(t, config.Load())
+
+	// The current server context is set in the home layer, but the context
+	// is only defined in the project layer, so it is set there. Otherwise the
+	// home layer would have no valid current context outside the project.
+	assert.NoError(t, config.SetCurrentContext("project-server"))
+	assert.NoError(t, config.Persist())
+
+	assert.Equal(t, map[string]string{"server": "home-only"}, readConfig(t, home).CurrentContext)
+	assert.Equal(t, map[string]string{
+		"server": "project-server",
+		"plugin": "project-only",
+	}, readConfig(t, project).CurrentContext)
+
+	assert.NoError(t, config.Load())
+	assert.Equal(t, "project-server", config.GetCurrentContext()["server"].Name)
+
+	// Outside of the project, the home layer's current context is used.
+	assert.NoError(t, os.Chdir(t.TempDir()))
+	assert.NoError(t, config.Load())
+	assert.Equal(t, "home-only", config.GetCurrentContext()["server"].Name)
+}
+
+func TestPersist_purge(t *testing.T) {
+	home, project := setupLayers(t)
+
+	config.Purge()
+	assert.NoError(t, config.Persist())
+
+	assert.Empty(t, readConfig(t, home).Contexts)
+	assert.Empty(t, readConfig(t, project).Contexts)
+}
+
+func TestPersist_createsExplicitFile(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	home := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), homeConfig)
+	path := filepath.Join(t.TempDir(), "new", "config.yml")
+	config.SetPath(path)
+
+	assert.NoError(t, config.Load())
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "new",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	}))
+	assert.NoError(t, config.Persist())
+
+	assert.Equal(t, map[string]string{"new": "localhost:5000"}, contextNames(readConfig(t, path)))
+	assert.Len(t, readConfig(t, home).Contexts, 2)
+}
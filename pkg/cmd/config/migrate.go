@@ -0,0 +1,114 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+)
+
+func init() {
+	cmdMigrate.Flags().BoolVarP(&flagDryRun, "dry-run", "", false, "show the changes which would be made without migrating the config files")
+}
+
+var cmdMigrate = &cobra.Command{
+	Use:   "migrate",
+	Short: "Migrate config files to the current schema version",
+	Long: utils.Doc(`
+		Migrate the loaded config files to the current config schema version.
+
+		Config files with an older schema version are migrated automatically
+		when a change is next written to them, so this command only needs to
+		be used to migrate them ahead of time, or to preview the changes a
+		migration will make with the --dry-run flag.
+
+		Before a config file is migrated, it is backed up to a file with the
+		same path and a '.v<VERSION>.bak' suffix, where VERSION is the schema
+		version of the original file.
+	`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(configMigrate(cmd.OutOrStdout(), flagDryRun))
+	},
+
+	// Config files are written by the migration itself. Override the root
+	// command's hook so a dry run does not migrate them when persisting.
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {},
+}
+
+func configMigrate(out io.Writer, dryRun bool) error {
+	log.WithField("dry-run", dryRun).Debug("migrating config files")
+
+	var migrations []config.Migration
+	var err error
+	if dryRun {
+		migrations, err = config.Migrations()
+	} else {
+		migrations, err = config.Migrate()
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(migrations) == 0 {
+		_, err := fmt.Fprintf(out, "Config files are up to date (version %d)\n", config.CurrentVersion)
+		return err
+	}
+
+	for _, m := range migrations {
+		if !dryRun {
+			if _, err := fmt.Fprintf(out, "Migrated %s (%s) from version %d to %d, backup: %s\n", m.Path, m.Name, m.From, m.To, m.Backup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        splitLines(m.Before),
+			B:        splitLines(m.After),
+			FromFile: fmt.Sprintf("%s (version %d)", m.Path, m.From),
+			ToFile:   fmt.Sprintf("%s (version %d)", m.Path, m.To),
+			Context:  3,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(out, diff); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLines splits the data into lines for a diff. Each line keeps its
+// trailing newline, which is added to the last line if it is missing.
+func splitLines(data []byte) []string {
+	lines := strings.SplitAfter(string(data), "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
@@ -0,0 +1,102 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+const legacyConfig = `contexts:
+- name: test
+  type: server
+  context:
+    address: localhost:5000
+current_context:
+  server: test
+`
+
+func TestCmdMigrate_extraArgs(t *testing.T) {
+	result := test.Cmd(cmdMigrate).Args("foo").Run(t)
+	result.AssertErr()
+}
+
+func TestCmdMigrate_upToDate(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), "version: 1\ncontexts: []\n")
+	assert.NoError(t, config.Load())
+
+	result := test.Cmd(cmdMigrate).Run(t)
+	result.AssertNoErr()
+	assert.Equal(t, "Config files are up to date (version 1)\n", string(result.Out()))
+}
+
+func TestCmdMigrate_dryRun(t *testing.T) {
+	defer resetFlags()
+
+	dirs := test.NewConfigDirs(t)
+	path := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), legacyConfig)
+	assert.NoError(t, config.Load())
+
+	result := test.Cmd(cmdMigrate).Args("--dry-run").Run(t)
+	result.AssertNoErr()
+	assert.Equal(t, "--- "+path+" (version 0)\n"+
+		"+++ "+path+" (version 1)\n"+
+		"@@ -1,7 +1,9 @@\n"+
+		"+version: 1\n"+
+		" contexts:\n"+
+		" - name: test\n"+
+		"   type: server\n"+
+		"   context:\n"+
+		"     address: localhost:5000\n"+
+		"+    client_cert: \"\"\n"+
+		" current_context:\n"+
+		"   server: test\n",
+		string(result.Out()),
+	)
+
+	// The config file is not modified.
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(data))
+	_, err = os.Stat(path + ".v0.bak")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCmdMigrate(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	path := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), legacyConfig)
+	assert.NoError(t, config.Load())
+
+	result := test.Cmd(cmdMigrate).Run(t)
+	result.AssertNoErr()
+	assert.Equal(t, "Migrated "+path+" (home) from version 0 to 1, backup: "+path+".v0.bak\n", string(result.Out()))
+
+	backup, err := os.ReadFile(path + ".v0.bak")
+	assert.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(backup))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "version: 1\n")
+	assert.Len(t, readConfig(t, path).Contexts, 1)
+}
@@ -0,0 +1,78 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"fmt"
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+)
+
+var cmdPath = &cobra.Command{
+	Use:   "path",
+	Short: "Display the config file locations",
+	Long: utils.Doc(`
+		Display the paths of the config files which were loaded, along with
+		their layer, and the path of the file which new contexts will be
+		written to.
+
+		Config files are listed from lowest to highest precedence. The file
+		new contexts are written to is the highest precedence file, which
+		may not exist yet.
+	`),
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(configPath(cmd.OutOrStdout()))
+	},
+}
+
+func configPath(out io.Writer) error {
+	log.Debug("getting config path")
+
+	layers, err := config.Layers()
+	if err != nil {
+		return err
+	}
+	write, err := config.WritePath()
+	if err != nil {
+		return err
+	}
+
+	var loaded int
+	for _, l := range layers {
+		if !l.Loaded {
+			continue
+		}
+		loaded++
+		if _, err := fmt.Fprintf(out, "Loaded: %s (%s)\n", l.Path, l.Name); err != nil {
+			return err
+		}
+	}
+	if loaded == 0 {
+		if _, err := fmt.Fprintln(out, "Loaded: none"); err != nil {
+			return err
+		}
+	}
+
+	_, err = fmt.Fprintf(out, "Write:  %s\n", write)
+	return err
+}
@@ -0,0 +1,156 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+const emptyConfig = "contexts: []\n"
+
+// assertPaths loads the config and checks the output of 'config path'.
+func assertPaths(t *testing.T, expected ...string) {
+	assert.NoError(t, config.Load())
+
+	result := test.Cmd(cmdPath).Run(t)
+	result.AssertNoErr()
+	assert.Equal(t, strings.Join(expected, "\n")+"\n", string(result.Out()))
+}
+
+func TestCmdPath_extraArgs(t *testing.T) {
+	result := test.Cmd(cmdPath).Args("foo").Run(t)
+	result.AssertErr()
+}
+
+func TestCmdPath_noConfigFile(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+
+	assertPaths(t,
+		"Loaded: none",
+		"Write:  "+filepath.Join(dirs.Home, ".synse.yml"),
+	)
+}
+
+func TestCmdPath_home(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	path := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), emptyConfig)
+
+	assertPaths(t,
+		"Loaded: "+path+" (home)",
+		"Write:  "+path,
+	)
+}
+
+func TestCmdPath_xdg(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), emptyConfig)
+	path := test.WriteConfig(t, filepath.Join(dirs.XDG, "synse", "config.yml"), emptyConfig)
+
+	// The XDG config file is preferred over the one in the home directory.
+	assertPaths(t,
+		"Loaded: "+path+" (home)",
+		"Write:  "+path,
+	)
+}
+
+func TestCmdPath_project(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	home := test.WriteConfig(t, filepath.Join(dirs.XDG, "synse", "config.yml"), emptyConfig)
+	project := test.WriteConfig(t, filepath.Join(dirs.WD, ".synse.yml"), emptyConfig)
+
+	assertPaths(t,
+		"Loaded: "+home+" (home)",
+		"Loaded: "+project+" (project)",
+		"Write:  "+project,
+	)
+}
+
+func TestCmdPath_env(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	home := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), emptyConfig)
+	project := test.WriteConfig(t, filepath.Join(dirs.WD, ".synse.yml"), emptyConfig)
+	env := test.WriteConfig(t, filepath.Join(t.TempDir(), "env.yml"), emptyConfig)
+	t.Setenv(config.EnvConfigFile, env)
+
+	assertPaths(t,
+		"Loaded: "+home+" (home)",
+		"Loaded: "+project+" (project)",
+		"Loaded: "+env+" (env)",
+		"Write:  "+env,
+	)
+}
+
+func TestCmdPath_envMultiple(t *testing.T) {
+	test.NewConfigDirs(t)
+	dir := t.TempDir()
+	first := test.WriteConfig(t, filepath.Join(dir, "first.yml"), emptyConfig)
+	second := test.WriteConfig(t, filepath.Join(dir, "second.yml"), emptyConfig)
+	t.Setenv(config.EnvConfigFile, first+string(os.PathListSeparator)+second)
+
+	// Files listed first take precedence, so they are loaded last.
+	assertPaths(t,
+		"Loaded: "+second+" (env)",
+		"Loaded: "+first+" (env)",
+		"Write:  "+first,
+	)
+}
+
+func TestCmdPath_envNotExist(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	home := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), emptyConfig)
+	env := filepath.Join(t.TempDir(), "new", "config.yml")
+	t.Setenv(config.EnvConfigFile, env)
+
+	// A file given explicitly is written to even if it does not exist yet.
+	assertPaths(t,
+		"Loaded: "+home+" (home)",
+		"Write:  "+env,
+	)
+}
+
+func TestCmdPath_flag(t *testing.T) {
+	test.NewConfigDirs(t)
+	env := test.WriteConfig(t, filepath.Join(t.TempDir(), "env.yml"), emptyConfig)
+	t.Setenv(config.EnvConfigFile, env)
+	flag := test.WriteConfig(t, filepath.Join(t.TempDir(), "flag.yml"), emptyConfig)
+	config.SetPath(flag)
+
+	// The file set via the flag replaces those set via the environment.
+	assertPaths(t,
+		"Loaded: "+flag+" (flag)",
+		"Write:  "+flag,
+	)
+}
+
+func TestCmdPath_sameFileInMultipleLayers(t *testing.T) {
+	dirs := test.NewConfigDirs(t)
+	home := test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), emptyConfig)
+	t.Setenv(config.EnvConfigFile, home)
+
+	// The file is only loaded once, at its highest precedence.
+	assertPaths(t,
+		"Loaded: "+home+" (env)",
+		"Write:  "+home,
+	)
+}
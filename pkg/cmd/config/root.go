@@ -0,0 +1,71 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+)
+
+// Define variables which hold values passed in via flags.
+var (
+	flagDryRun bool
+)
+
+// resetFlags resets the flag values. This is useful for tests.
+func resetFlags() {
+	flagDryRun = false
+}
+
+// New returns a new instance of the 'config' command.
+func New() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the CLI configuration file",
+		Long: utils.Doc(`
+			Manage the files which hold the CLI configuration.
+
+			The configuration is merged from the following layers, from lowest
+			to highest precedence:
+
+			<bold>home</>    : $XDG_CONFIG_HOME/synse/config.yml (default ~/.config/synse/config.yml)
+			          if it exists, otherwise ~/.synse.yml
+			<bold>project</> : ./.synse.yml, if it exists
+			<bold>env</>     : the files in the <bold>SYNSE_CONFIG</> environment variable, separated
+			          by ':', with earlier files taking precedence
+			<bold>flag</>    : the file given by the --config flag, which replaces the env layer
+
+			Contexts with the same name in more than one layer are taken from the
+			highest precedence layer, as is the current context for each type.
+			Changes to a context are written back to the file it was loaded from.
+			New contexts are written to the highest precedence file.
+
+			Config files record the version of their schema. Files with an older
+			schema version are migrated when a change is next written to them, and
+			a backup of the original file is kept alongside it. Unknown fields are
+			ignored with a warning, and are dropped if the file is rewritten.
+		`),
+	}
+
+	// Add sub-commands
+	cmd.AddCommand(
+		cmdMigrate,
+		cmdPath,
+	)
+
+	return cmd
+}
@@ -17,8 +17,6 @@
 package context
 
 import (
-	"fmt"
-
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/config"
@@ -29,6 +27,14 @@ import (
 func init() {
 	cmdAdd.Flags().BoolVarP(&flagSet, "set", "", false, "set as the current context after adding")
 	cmdAdd.Flags().StringVarP(&flagClientCert, "tlscert", "", "", "path to TLS certificate file (e.g. ./synse.pem)")
+	cmdAdd.Flags().StringVarP(&flagClientKey, "tlskey", "", "", "path to TLS client key file, for use with --tlscert (e.g. ./synse-key.pem)")
+	cmdAdd.Flags().StringVarP(&flagCACert, "cacert", "", "", "path to CA certificate bundle used to verify the component (e.g. ./ca.pem)")
+	cmdAdd.Flags().StringVarP(&flagServerName, "server-name", "", "", "server name used to verify the component's certificate")
+	cmdAdd.Flags().BoolVarP(&flagSkipVerify, "insecure-skip-verify", "", false, "do not verify the component's certificate (insecure)")
+	cmdAdd.Flags().StringVarP(&flagProxy, "proxy", "", "", "URL of the proxy used to connect to the component (e.g. socks5://bastion:1080)")
+	cmdAdd.Flags().BoolVarP(&flagProtected, "protected", "", false, "protect the context from writes (see 'synse server write --help')")
+	addAuthFlags(cmdAdd.Flags())
+	addDefaultsFlags(cmdAdd.Flags())
 }
 
 var cmdAdd = &cobra.Command{
@@ -50,40 +56,99 @@ var cmdAdd = &cobra.Command{
 		Currently, the supported types are:
 		- plugin
 		- server
+
+		The address is either a TCP address in the form host[:port], or the
+		path of a unix socket in the form unix:///path for a component which
+		listens on a unix socket (e.g. unix:///tmp/synse/plugin.sock).
+
+		Connections use TLS if any TLS flags are set. To use mutual TLS,
+		provide a client certificate with --tlscert and its key with --tlskey.
+		The --cacert flag specifies the CA bundle used to verify the component
+		and --server-name overrides the name it is verified against, which is
+		useful when the component is behind a TLS-terminating proxy.
+
+		A --tlscert given without a --tlskey is used as a CA certificate, for
+		compatibility with earlier versions of the CLI.
+
+		Connections are made through the proxy given with --proxy, which is
+		an http://, https:// or socks5:// URL (e.g. socks5://bastion:1080).
+		Without it, the proxy is taken from the HTTPS_PROXY, HTTP_PROXY and
+		NO_PROXY environment variables. Proxies are not used for unix
+		sockets.
+
+		A context added with --protected refuses writes, such as those made by
+		'synse server write' and 'synse plugin write', unless the write is
+		forced with --force-protected and confirmed by typing the name of the
+		context. This guards production components against accidental writes.
+
+		Requests to a server context may be authenticated, e.g. for a server
+		behind an authenticating proxy. The --header flag sets a header which
+		is sent with each HTTP and WebSocket request, and the --token flag sets
+		a bearer token. The --credential-helper flag sets a command which is
+		run to get credentials, following the protocol of git credential
+		helpers: it is run with the "get" argument, and writes key=value lines
+		with a token, a username and password, or header=Name: value entries.
+		A ttl (in seconds or as a duration, e.g. 5m) caches its credentials
+		until they expire. Header values and tokens are redacted when the
+		context is displayed.
+
+		The --default-* flags set defaults for the flags of server and plugin
+		commands run against the context, which are used when the flag is not
+		given: --default-ns for --ns, --default-output for -o/--output,
+		--default-no-header for --no-header, --default-timeout for --timeout
+		and --default-tags for --tag. Default tags are not used when devices
+		are given.
 	`),
 	SuggestFor: []string{
 		"new",
 	},
 	Args: cobra.ExactArgs(3),
 	Run: func(cmd *cobra.Command, args []string) {
+		var settings config.Context
+		_, err := contextAuth(cmd.Flags(), &settings)
+		exit.FromCmd(cmd).Err(err)
+
+		defaults, _ := contextDefaults(cmd.Flags(), nil)
 		exit.FromCmd(cmd).Err(
-			addContext(args[0], args[1], args[2]),
+			addContext(args[0], args[1], args[2], settings, defaults),
 		)
 	},
 }
 
-func addContext(ctxType, ctxName, ctxAddress string) error {
+func addContext(ctxType, ctxName, ctxAddress string, auth config.Context, defaults *config.Defaults) error {
 	log.WithFields(log.Fields{
 		"type":    ctxType,
 		"name":    ctxName,
 		"address": ctxAddress,
 		"tlscert": flagClientCert,
+		"tlskey":  flagClientKey,
+		"cacert":  flagCACert,
 	}).Debug("adding new context")
 
-	// Verify that the provided context type is supported.
-	if ctxType != "plugin" && ctxType != "server" {
-		return fmt.Errorf("unsupported context type: %s", ctxType)
-	}
-
-	err := config.AddContext(&config.ContextRecord{
-		Name: ctxName,
-		Type: ctxType,
+	record := &config.ContextRecord{
+		Name:      ctxName,
+		Type:      ctxType,
+		Protected: flagProtected,
 		Context: config.Context{
-			Address:    ctxAddress,
-			ClientCert: flagClientCert,
+			Address:            ctxAddress,
+			ClientCert:         flagClientCert,
+			ClientKey:          flagClientKey,
+			CACert:             flagCACert,
+			ServerName:         flagServerName,
+			InsecureSkipVerify: flagSkipVerify,
+			Proxy:              flagProxy,
+			Headers:            auth.Headers,
+			Token:              auth.Token,
+			CredentialHelper:   auth.CredentialHelper,
 		},
-	})
-	if err != nil {
+		Defaults: defaults,
+	}
+
+	// Verify that the provided context is valid prior to adding it.
+	if err := config.ValidateContext(record); err != nil {
+		return err
+	}
+	if err := config.AddContext(record); err != nil {
 		return err
 	}
 
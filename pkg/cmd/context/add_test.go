@@ -140,3 +140,269 @@ func TestCmdAdd_addAndSetContext(t *testing.T) {
 	assert.False(t, ok)
 	assert.Nil(t, pluginCtx)
 }
+
+func TestCmdAdd_addContextTLS(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	certs := test.NewCerts(t)
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"test-address",
+		"--tlscert", certs.ClientCert,
+		"--tlskey", certs.ClientKey,
+		"--cacert", certs.CACert,
+		"--server-name", "synse.test",
+		"--insecure-skip-verify",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Len(t, config.GetContexts(), 1)
+	ctx := config.GetContexts()[0]
+	assert.Equal(t, certs.ClientCert, ctx.Context.ClientCert)
+	assert.Equal(t, certs.ClientKey, ctx.Context.ClientKey)
+	assert.Equal(t, certs.CACert, ctx.Context.CACert)
+	assert.Equal(t, "synse.test", ctx.Context.ServerName)
+	assert.True(t, ctx.Context.InsecureSkipVerify)
+}
+
+func TestCmdAdd_keyWithoutCert(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	certs := test.NewCerts(t)
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"test-address",
+		"--tlskey", certs.ClientKey,
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.key-without-cert.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_unreadableCACert(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"test-address",
+		"--cacert", "/tmp/test/does-not-exist.pem",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.unreadable-ca.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_unixSocket(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"unix:///tmp/synse/plugin.sock",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Len(t, config.GetContexts(), 1)
+	assert.Equal(t, "unix:///tmp/synse/plugin.sock", config.GetContext("test-name").Context.Address)
+}
+
+func TestCmdAdd_invalidUnixSocket(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"unix://plugin.sock",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.invalid-unix-socket.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_defaults(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"server",
+		"test-name",
+		"test-address",
+		"--default-ns", "vapor",
+		"--default-output", "yaml",
+		"--default-timeout", "10s",
+		"--default-tags", "type:temperature,vapor/rack:1",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Equal(t, &config.Defaults{
+		Namespace: "vapor",
+		Output:    "yaml",
+		Timeout:   "10s",
+		Tags:      []string{"type:temperature", "vapor/rack:1"},
+	}, config.GetContext("test-name").Defaults)
+}
+
+func TestCmdAdd_badDefaultOutput(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"server",
+		"test-name",
+		"test-address",
+		"--default-output", "xml",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.bad-default-output.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_auth(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"server",
+		"test-name",
+		"test-address",
+		"--header", "X-Api-Key: 123",
+		"--header", "X-Tenant:vapor, io",
+		"--token", "abc",
+		"--credential-helper", "synse-credentials --profile dev",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	ctx := config.GetContext("test-name").Context
+	assert.Equal(t, map[string]string{"X-Api-Key": "123", "X-Tenant": "vapor, io"}, ctx.Headers)
+	assert.Equal(t, "abc", ctx.Token)
+	assert.Equal(t, "synse-credentials --profile dev", ctx.CredentialHelper)
+}
+
+func TestCmdAdd_authPlugin(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"test-address",
+		"--token", "abc",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.auth-plugin.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_malformedHeader(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"server",
+		"test-name",
+		"test-address",
+		"--header", "X-Api-Key=123",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.malformed-header.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_proxy(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"server",
+		"test-name",
+		"10.1.0.5:5000",
+		"--proxy", "socks5://bastion:1080",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Equal(t, "socks5://bastion:1080", config.GetContext("test-name").Context.Proxy)
+}
+
+func TestCmdAdd_invalidProxy(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"plugin",
+		"test-name",
+		"unix:///tmp/synse.sock",
+		"--proxy", "socks5://bastion:1080",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("add.invalid-proxy.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdAdd_protected(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdAdd).Args(
+		"server",
+		"test-name",
+		"10.1.0.5:5000",
+		"--protected",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.True(t, config.GetContext("test-name").Protected)
+}
@@ -0,0 +1,84 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+var (
+	flagHeaders          []string
+	flagToken            string
+	flagCredentialHelper string
+)
+
+// addAuthFlags adds the flags which set the authentication settings of a
+// context to the flag set.
+func addAuthFlags(flags *pflag.FlagSet) {
+	flags.StringArrayVarP(&flagHeaders, "header", "", []string{}, "header to send with requests to the server, as 'Name: value' (may be repeated)")
+	flags.StringVarP(&flagToken, "token", "", "", "bearer token to send with requests to the server")
+	flags.StringVarP(&flagCredentialHelper, "credential-helper", "", "", "command run to get credentials for requests to the server")
+}
+
+// contextAuth applies the authentication flags which were set to the
+// context settings. It reports whether any authentication flags were set.
+// The headers given with --header replace any existing headers, and empty
+// header flags are ignored, so --header "" clears the headers.
+func contextAuth(flags *pflag.FlagSet, settings *config.Context) (bool, error) {
+	var changed bool
+	if flags.Changed("header") {
+		headers, err := parseHeaders(flagHeaders)
+		if err != nil {
+			return false, err
+		}
+		settings.Headers = headers
+		changed = true
+	}
+	if flags.Changed("token") {
+		settings.Token = flagToken
+		changed = true
+	}
+	if flags.Changed("credential-helper") {
+		settings.CredentialHelper = flagCredentialHelper
+		changed = true
+	}
+	return changed, nil
+}
+
+// parseHeaders parses headers given as 'Name: value'. The result is nil if
+// no headers are given.
+func parseHeaders(values []string) (map[string]string, error) {
+	var headers map[string]string
+	for _, v := range values {
+		if v == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(v, ":")
+		if !ok {
+			return nil, fmt.Errorf("invalid header '%s' (expected 'Name: value')", v)
+		}
+		if headers == nil {
+			headers = map[string]string{}
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	return headers, nil
+}
@@ -0,0 +1,183 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+)
+
+// checkTimeout is the time allowed for each request made when probing a
+// context.
+const checkTimeout = 5 * time.Second
+
+// checkClientOptions gets the client options for probing the named context.
+// Failed requests are not retried, so that an unreachable context fails
+// quickly and the latency is that of a single attempt.
+func checkClientOptions(name string) utils.ClientOptions {
+	return utils.ClientOptions{
+		Context: name,
+		Timeout: checkTimeout,
+		NoRetry: true,
+	}
+}
+
+// timeSince measures the latency of a probe. It is a variable so that
+// tests can fix the latency.
+var timeSince = time.Since
+
+// contextCheck holds the result of probing a context.
+type contextCheck struct {
+	Reachable bool   `json:"reachable" yaml:"reachable"`
+	Latency   string `json:"latency,omitempty" yaml:"latency,omitempty"`
+	Version   string `json:"version,omitempty" yaml:"version,omitempty"`
+	TLS       string `json:"tls" yaml:"tls"`
+	Error     string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// checkContexts probes each of the contexts concurrently, using the clients
+// created for it by the factory. As with requests made against multiple
+// contexts, at most utils.MaxConcurrentRequests contexts are probed at once.
+// The results are returned in the same order as the contexts.
+func checkContexts(contexts []config.ContextRecord, factory func(name string) clients.Factory) []*contextCheck {
+	checks := make([]*contextCheck, len(contexts))
+	sem := make(chan struct{}, utils.MaxConcurrentRequests)
+
+	var wg sync.WaitGroup
+	for i := range contexts {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			checks[i] = checkContext(&contexts[i], factory(contexts[i].Name))
+		}(i)
+	}
+	wg.Wait()
+	return checks
+}
+
+// checkContext probes the context. Server contexts are probed with the HTTP
+// status and version requests, and plugin contexts with the gRPC test and
+// version requests. The latency is that of the status or test request.
+func checkContext(ctx *config.ContextRecord, factory clients.Factory) *contextCheck {
+	check := &contextCheck{
+		TLS: tlsStatus(ctx.Context),
+	}
+
+	var err error
+	switch ctx.Type {
+	case "server":
+		err = checkServer(factory, check)
+	case "plugin":
+		err = checkPlugin(factory, check)
+	default:
+		err = fmt.Errorf("unsupported context type: %s", ctx.Type)
+	}
+	if err != nil {
+		log.WithFields(log.Fields{
+			"context": ctx.Name,
+			"error":   err,
+		}).Debug("context check failed")
+		check.Error = err.Error()
+	}
+	return check
+}
+
+func checkServer(factory clients.Factory, check *contextCheck) error {
+	client, err := factory.HTTP()
+	if err != nil {
+		return err
+	}
+
+	start := time.Now()
+	if _, err := client.Status(); err != nil {
+		return err
+	}
+	check.Reachable = true
+	check.Latency = formatLatency(timeSince(start))
+
+	version, err := client.Version()
+	if err != nil {
+		return err
+	}
+	check.Version = version.Version
+	return nil
+}
+
+func checkPlugin(factory clients.Factory, check *contextCheck) error {
+	conn, client, err := factory.GRPC()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	status, err := client.Test(ctx, &synse.Empty{})
+	if err != nil {
+		return err
+	}
+	if !status.Ok {
+		return fmt.Errorf("plugin test status is not ok")
+	}
+	check.Reachable = true
+	check.Latency = formatLatency(timeSince(start))
+
+	version, err := client.Version(ctx, &synse.Empty{})
+	if err != nil {
+		return err
+	}
+	check.Version = version.PluginVersion
+	return nil
+}
+
+// tlsStatus describes the TLS settings of the context: "off" if TLS is
+// not configured, "insecure" if the component's certificate is not
+// verified, "mutual" if a client key pair is used, or "on" otherwise.
+func tlsStatus(ctx config.Context) string {
+	switch {
+	case !ctx.TLSEnabled():
+		return "off"
+	case ctx.InsecureSkipVerify:
+		return "insecure"
+	case ctx.ClientCert != "" && ctx.ClientKey != "":
+		return "mutual"
+	default:
+		return "on"
+	}
+}
+
+// formatLatency rounds the latency for display, to the millisecond, or to
+// the microsecond if it is under a millisecond.
+func formatLatency(d time.Duration) string {
+	if d < time.Millisecond {
+		return d.Round(time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
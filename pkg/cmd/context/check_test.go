@@ -0,0 +1,133 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+func TestCheckContext_unsupportedType(t *testing.T) {
+	check := checkContext(&config.ContextRecord{Name: "test", Type: "foo"}, &test.FakeClients{})
+	assert.False(t, check.Reachable)
+	assert.Equal(t, "unsupported context type: foo", check.Error)
+}
+
+func TestCheckContext_serverVersionErr(t *testing.T) {
+	// The server is reachable, but its version could not be read.
+	check := checkContext(&config.ContextRecord{Name: "test", Type: "server"}, &test.FakeClients{
+		Server: &versionErrClient{test.NewFakeHTTPClientV3()},
+	})
+	assert.True(t, check.Reachable)
+	assert.Empty(t, check.Version)
+	assert.Equal(t, "version unavailable", check.Error)
+}
+
+func TestCheckClientOptions(t *testing.T) {
+	opts := checkClientOptions("local")
+	assert.Equal(t, "local", opts.Context)
+	assert.Equal(t, checkTimeout, opts.Timeout)
+	assert.True(t, opts.NoRetry)
+}
+
+func TestCheckContexts_concurrency(t *testing.T) {
+	client := &slowStatusClient{Client: test.NewFakeHTTPClientV3()}
+
+	var contexts []config.ContextRecord
+	for i := 0; i < utils.MaxConcurrentRequests*2; i++ {
+		contexts = append(contexts, config.ContextRecord{Name: fmt.Sprintf("server-%d", i), Type: "server"})
+	}
+
+	checks := checkContexts(contexts, func(string) clients.Factory {
+		return &test.FakeClients{Server: client}
+	})
+	assert.Len(t, checks, len(contexts))
+	for _, check := range checks {
+		assert.True(t, check.Reachable)
+	}
+	assert.LessOrEqual(t, client.peak, utils.MaxConcurrentRequests)
+}
+
+func TestTLSStatus(t *testing.T) {
+	tests := []struct {
+		status string
+		ctx    config.Context
+	}{
+		{"off", config.Context{}},
+		{"on", config.Context{ClientCert: "ca.pem"}},
+		{"mutual", config.Context{ClientCert: "cert.pem", ClientKey: "key.pem"}},
+		{"insecure", config.Context{ClientCert: "ca.pem", InsecureSkipVerify: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.status, func(t *testing.T) {
+			assert.Equal(t, tt.status, tlsStatus(tt.ctx))
+		})
+	}
+}
+
+func TestFormatLatency(t *testing.T) {
+	assert.Equal(t, "12ms", formatLatency(12345*time.Microsecond))
+	assert.Equal(t, "2s", formatLatency(2*time.Second))
+	assert.Equal(t, "123µs", formatLatency(123456*time.Nanosecond))
+}
+
+// versionErrClient is a fake server client which fails to get the version.
+type versionErrClient struct {
+	synse.Client
+}
+
+func (c *versionErrClient) Version() (*scheme.Version, error) {
+	return nil, errors.New("version unavailable")
+}
+
+// slowStatusClient is a fake server client whose status requests take a
+// while, and which records the most status requests made at once.
+type slowStatusClient struct {
+	synse.Client
+
+	mu     sync.Mutex
+	active int
+	peak   int
+}
+
+func (c *slowStatusClient) Status() (*scheme.Status, error) {
+	c.mu.Lock()
+	c.active++
+	if c.active > c.peak {
+		c.peak = c.active
+	}
+	c.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	c.mu.Lock()
+	c.active--
+	c.mu.Unlock()
+	return c.Client.Status()
+}
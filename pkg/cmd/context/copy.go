@@ -0,0 +1,65 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+)
+
+func init() {
+	cmdCopy.Flags().BoolVarP(&flagSet, "set", "", false, "set as the current context after copying")
+}
+
+var cmdCopy = &cobra.Command{
+	Use:   "copy SOURCE_NAME NEW_NAME",
+	Short: "Copy a context",
+	Long: utils.Doc(`
+		Add a new context with the same settings as an existing context.
+
+		This is useful for creating a context which differs only slightly
+		from another, e.g. a copy which is then changed with
+		'synse context update'.
+	`),
+	Aliases: []string{
+		"cp",
+	},
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(copyContext(args[0], args[1]))
+	},
+}
+
+func copyContext(name, newName string) error {
+	log.WithFields(log.Fields{
+		"name":     name,
+		"new_name": newName,
+	}).Debug("copying context")
+
+	if err := config.CopyContext(name, newName); err != nil {
+		return err
+	}
+
+	if flagSet {
+		log.Debug("setting copied context as current context")
+		return config.SetCurrentContext(newName)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+func TestCmdCopy_notEnoughArgs(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdCopy).Args("foo").Run(t)
+	result.AssertErr()
+	result.AssertGolden("copy.not-enough-args.golden")
+}
+
+func TestCmdCopy_nonexisting(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdCopy).Args("foo", "bar").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("copy.nonexisting.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdCopy_nameExists(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	for _, name := range []string{"server-ctx", "other-ctx"} {
+		assert.NoError(t, config.AddContext(&config.ContextRecord{
+			Name:    name,
+			Type:    "server",
+			Context: config.Context{Address: "0.0.0.0"},
+		}))
+	}
+
+	result := test.Cmd(cmdCopy).Args("server-ctx", "other-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("copy.name-exists.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+}
+
+func TestCmdCopy_copy(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "server-ctx",
+		Type:    "server",
+		Context: config.Context{Address: "0.0.0.0", ServerName: "synse.local"},
+	}))
+	assert.NoError(t, config.SetCurrentContext("server-ctx"))
+
+	result := test.Cmd(cmdCopy).Args("server-ctx", "new-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	ctx := config.GetContext("new-ctx")
+	assert.Equal(t, "server", ctx.Type)
+	assert.Equal(t, config.Context{Address: "0.0.0.0", ServerName: "synse.local"}, ctx.Context)
+	assert.Equal(t, "server-ctx", config.GetCurrentContext()["server"].Name)
+}
+
+func TestCmdCopy_copyAndSet(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "server-ctx",
+		Type:    "server",
+		Context: config.Context{Address: "0.0.0.0"},
+	}))
+	assert.NoError(t, config.SetCurrentContext("server-ctx"))
+
+	result := test.Cmd(cmdCopy).Args("server-ctx", "new-ctx", "--set").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, "new-ctx", config.GetCurrentContext()["server"].Name)
+}
@@ -30,8 +30,7 @@ import (
 
 func init() {
 	cmdCurrent.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdCurrent.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdCurrent.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdCurrent.Flags(), &flagOutput)
 }
 
 var cmdCurrent = &cobra.Command{
@@ -54,11 +53,6 @@ var cmdCurrent = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		exiter := exit.FromCmd(cmd)
 
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
 		var ctxType string
 		if len(args) != 0 {
 			ctxType = args[0]
@@ -89,14 +83,14 @@ func getCurrentContext(out io.Writer, ctxType string) error {
 		if !ok {
 			return fmt.Errorf("no current context is set for type '%s' (see 'synse context set')", ctxType)
 		}
-		ctxs = append(ctxs, *c)
+		ctxs = append(ctxs, redactRecord(*c))
 	} else {
 		for _, v := range currentContexts {
-			ctxs = append(ctxs, *v)
+			ctxs = append(ctxs, redactRecord(*v))
 		}
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("CURRENT", "NAME", "TYPE", "ADDRESS")
 	printer.SetRowFunc(contextRowFunc)
 
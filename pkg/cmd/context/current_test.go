@@ -34,8 +34,7 @@ func TestCmdCurrent_multipleFmtFlags(t *testing.T) {
 		"--yaml",
 		"--json",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
+	result.AssertErr()
 	result.AssertGolden("current.multiple-fmt-flags.golden")
 
 	assert.Len(t, config.GetContexts(), 0)
@@ -338,3 +337,33 @@ func TestCmdCurrent_json2(t *testing.T) {
 	assert.Len(t, config.GetContexts(), 2)
 	assert.Len(t, config.GetCurrentContext(), 2)
 }
+
+func TestCmdCurrent_yamlDefaults(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "test-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "0.0.0.0",
+		},
+		Defaults: &config.Defaults{
+			Namespace: "vapor",
+			Output:    "json",
+			NoHeader:  true,
+			Timeout:   "10s",
+			Tags:      []string{"type:temperature"},
+		},
+	}))
+	assert.NoError(t, config.SetCurrentContext("test-ctx"))
+
+	result := test.Cmd(cmdCurrent).Args(
+		"server",
+		"--yaml",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("current.yaml-defaults.golden")
+}
@@ -0,0 +1,79 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+var (
+	flagDefaultNS       string
+	flagDefaultOutput   string
+	flagDefaultNoHeader bool
+	flagDefaultTimeout  time.Duration
+	flagDefaultTags     []string
+)
+
+// addDefaultsFlags adds the flags which set the command defaults of a
+// context to the flag set.
+func addDefaultsFlags(flags *pflag.FlagSet) {
+	flags.StringVarP(&flagDefaultNS, "default-ns", "", "", "default tag namespace for server and plugin commands")
+	flags.StringVarP(&flagDefaultOutput, "default-output", "", "", "default output format for server and plugin commands (table, wide, json, yaml)")
+	flags.BoolVarP(&flagDefaultNoHeader, "default-no-header", "", false, "do not print out column headers for server and plugin commands by default")
+	flags.DurationVarP(&flagDefaultTimeout, "default-timeout", "", 0, "default timeout for requests made by server and plugin commands (e.g. 10s)")
+	flags.StringSliceVarP(&flagDefaultTags, "default-tags", "", []string{}, "default tags to use as device selectors for server and plugin commands")
+}
+
+// contextDefaults applies the defaults flags which were set to a copy of
+// the given defaults. It reports whether any defaults flags were set. The
+// result is nil if no defaults are set.
+func contextDefaults(flags *pflag.FlagSet, defaults *config.Defaults) (*config.Defaults, bool) {
+	var updated config.Defaults
+	if defaults != nil {
+		updated = *defaults
+	}
+
+	var changed bool
+	for _, f := range []struct {
+		flag  string
+		apply func()
+	}{
+		{"default-ns", func() { updated.Namespace = flagDefaultNS }},
+		{"default-output", func() { updated.Output = flagDefaultOutput }},
+		{"default-no-header", func() { updated.NoHeader = flagDefaultNoHeader }},
+		{"default-timeout", func() {
+			updated.Timeout = ""
+			if flagDefaultTimeout != 0 {
+				updated.Timeout = flagDefaultTimeout.String()
+			}
+		}},
+		{"default-tags", func() { updated.Tags = append([]string(nil), flagDefaultTags...) }},
+	} {
+		if flags.Changed(f.flag) {
+			f.apply()
+			changed = true
+		}
+	}
+
+	if updated.IsZero() {
+		return nil, changed
+	}
+	return &updated, changed
+}
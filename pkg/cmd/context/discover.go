@@ -0,0 +1,482 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+)
+
+const (
+	// defaultServerPort and defaultPluginPort are the ports Synse Server and
+	// Synse plugins listen on by default.
+	defaultServerPort = 5000
+	defaultPluginPort = 5001
+
+	// maxDiscoverHosts is the maximum number of hosts which may be scanned
+	// at once, to guard against accidentally scanning a large network.
+	maxDiscoverHosts = 4096
+
+	// maxConcurrentProbes is the maximum number of endpoints probed at once.
+	maxConcurrentProbes = 64
+)
+
+var (
+	flagPorts   []int
+	flagTimeout time.Duration
+	flagYes     bool
+)
+
+func init() {
+	cmdDiscover.Flags().IntSliceVarP(&flagPorts, "ports", "p", []int{defaultServerPort, defaultPluginPort}, "ports to probe on each host")
+	cmdDiscover.Flags().DurationVarP(&flagTimeout, "timeout", "", 2*time.Second, "time limit for each probe")
+	cmdDiscover.Flags().BoolVarP(&flagYes, "yes", "y", false, "add the discovered contexts without prompting")
+	cmdDiscover.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
+	utils.AddOutputFlags(cmdDiscover.Flags(), &flagOutput)
+}
+
+var cmdDiscover = &cobra.Command{
+	Use:   "discover TARGET...",
+	Short: "Discover Synse servers and plugins on the network",
+	Long: utils.Doc(`
+		Discover Synse servers and plugins on the network and add them
+		as contexts.
+
+		Each TARGET is a host name, an IP address, or a CIDR range (e.g.
+		10.1.0.0/24). Multiple targets may also be given as a comma
+		separated list. Each target is probed on the ports given by the
+		--ports flag (by default, 5000 and 5001).
+
+		An endpoint is identified as a Synse server if it responds to the
+		HTTP /test endpoint, or as a Synse plugin if it responds to the gRPC
+		Test request. Probes are made without TLS.
+
+		Contexts are named after the component and the host (and the port,
+		if it is not the component's default), e.g. "server-10-1-0-5" or
+		"emulator-plugin-10-1-0-6". Components which already have a context
+		with the same address are listed, but not added again.
+
+		Once the scan completes, you are prompted to add the new contexts.
+		The --yes flag adds them without prompting. When the output is JSON
+		or YAML, the new contexts are only added if --yes is set.
+	`),
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(discoverContexts(cmd.InOrStdin(), cmd.OutOrStdout(), args))
+	},
+}
+
+// discovered describes a Synse component found on the network.
+type discovered struct {
+	Name    string `json:"name" yaml:"name"`
+	Type    string `json:"type" yaml:"type"`
+	Address string `json:"address" yaml:"address"`
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Exists is set if a context for the component already exists, in
+	// which case Name is the name of that context.
+	Exists bool `json:"exists" yaml:"exists"`
+}
+
+func discoverContexts(in io.Reader, out io.Writer, targets []string) error {
+	hosts, err := discoverHosts(targets)
+	if err != nil {
+		return err
+	}
+	for _, port := range flagPorts {
+		if port <= 0 || port > 65535 {
+			return fmt.Errorf("invalid port: %d", port)
+		}
+	}
+
+	var endpoints []string
+	for _, host := range hosts {
+		for _, port := range flagPorts {
+			endpoints = append(endpoints, net.JoinHostPort(host, strconv.Itoa(port)))
+		}
+	}
+	log.WithFields(log.Fields{
+		"hosts":     len(hosts),
+		"endpoints": len(endpoints),
+	}).Debug("discovering synse components")
+
+	found := nameDiscovered(probeEndpoints(endpoints))
+	if len(found) == 0 {
+		_, err := fmt.Fprintln(out, "no Synse servers or plugins found")
+		return err
+	}
+
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	printer.SetHeader("NAME", "TYPE", "ADDRESS", "VERSION", "STATUS")
+	printer.SetRowFunc(discoveredRowFunc)
+	if err := printer.Write(found); err != nil {
+		return err
+	}
+
+	var added []discovered
+	for _, d := range found {
+		if !d.Exists {
+			added = append(added, d)
+		}
+	}
+	if len(added) == 0 {
+		if !flagOutput.IsTable() {
+			return nil
+		}
+		_, err := fmt.Fprintln(out, "no new contexts to add")
+		return err
+	}
+
+	if !flagYes {
+		if !flagOutput.IsTable() {
+			return nil
+		}
+		ok, err := confirm(in, out, fmt.Sprintf("Add %d new context(s)?", len(added)))
+		if err != nil || !ok {
+			return err
+		}
+	}
+
+	for _, d := range added {
+		record := &config.ContextRecord{
+			Name: d.Name,
+			Type: d.Type,
+			Context: config.Context{
+				Address: d.Address,
+			},
+		}
+		if err := config.AddContext(record); err != nil {
+			return err
+		}
+		if !flagOutput.IsTable() {
+			continue
+		}
+		if _, err := fmt.Fprintf(out, "context '%s' added\n", d.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// discoverHosts expands the targets into the list of hosts to probe.
+func discoverHosts(targets []string) ([]string, error) {
+	var hosts []string
+	seen := map[string]bool{}
+	add := func(host string) error {
+		if seen[host] {
+			return nil
+		}
+		if len(hosts) == maxDiscoverHosts {
+			return fmt.Errorf("too many hosts to scan (the maximum is %d)", maxDiscoverHosts)
+		}
+		seen[host] = true
+		hosts = append(hosts, host)
+		return nil
+	}
+
+	for _, arg := range targets {
+		for _, target := range strings.Split(arg, ",") {
+			target = strings.TrimSpace(target)
+			if target == "" {
+				continue
+			}
+			if !strings.Contains(target, "/") {
+				if err := add(target); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			ips, err := cidrHosts(target)
+			if err != nil {
+				return nil, err
+			}
+			for _, ip := range ips {
+				if err := add(ip); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return nil, fmt.Errorf("no hosts to scan")
+	}
+	return hosts, nil
+}
+
+// cidrHosts gets the host addresses in a CIDR range. For IPv4 ranges with
+// more than two addresses, the network and broadcast addresses are skipped.
+func cidrHosts(cidr string) ([]string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CIDR range '%s': %v", cidr, err)
+	}
+	ones, bits := network.Mask.Size()
+	if size := bits - ones; size >= 32 || 1<<size > maxDiscoverHosts+2 {
+		return nil, fmt.Errorf("CIDR range '%s' is too large (the maximum is %d hosts)", cidr, maxDiscoverHosts)
+	}
+
+	var hosts []string
+	ip := network.IP
+	for ; network.Contains(ip); ip = nextIP(ip) {
+		hosts = append(hosts, ip.String())
+	}
+	if ip.To4() != nil && bits-ones > 1 {
+		hosts = hosts[1 : len(hosts)-1]
+	}
+	return hosts, nil
+}
+
+// nextIP gets the IP address following the given one.
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// probeEndpoints probes each endpoint concurrently, returning the Synse
+// components found in the same order as the endpoints.
+func probeEndpoints(endpoints []string) []discovered {
+	results := make([]*discovered, len(endpoints))
+	sem := make(chan struct{}, maxConcurrentProbes)
+
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			results[i] = probeEndpoint(endpoint)
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	var found []discovered
+	for _, r := range results {
+		if r != nil {
+			found = append(found, *r)
+		}
+	}
+	return found
+}
+
+// probeEndpoint fingerprints the endpoint, first as a Synse server and then
+// as a Synse plugin. If it is neither, nil is returned. The Name of a found
+// component is the base for its context name.
+func probeEndpoint(address string) *discovered {
+	logger := log.WithField("address", address)
+
+	d, err := probeServer(address)
+	if err == nil {
+		logger.Debug("found synse server")
+		return d
+	}
+	logger.WithError(err).Debug("not a synse server")
+
+	d, err = probePlugin(address)
+	if err == nil {
+		logger.Debug("found synse plugin")
+		return d
+	}
+	logger.WithError(err).Debug("not a synse plugin")
+	return nil
+}
+
+func probeServer(address string) (*discovered, error) {
+	client, err := utils.NewClientFactory(utils.ClientOptions{
+		Record: &config.ContextRecord{
+			Type:    "server",
+			Context: config.Context{Address: address},
+		},
+		Timeout: flagTimeout,
+		NoRetry: true,
+	}).HTTP()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status.Status != "ok" {
+		return nil, fmt.Errorf("unexpected status: %s", status.Status)
+	}
+
+	d := &discovered{
+		Name:    "server",
+		Type:    "server",
+		Address: address,
+	}
+	if version, err := client.Version(); err == nil {
+		d.Version = version.Version
+	}
+	return d, nil
+}
+
+func probePlugin(address string) (*discovered, error) {
+	conn, client, err := utils.NewClientFactory(utils.ClientOptions{
+		Record: &config.ContextRecord{
+			Type:    "plugin",
+			Context: config.Context{Address: address},
+		},
+	}).GRPC()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), flagTimeout)
+	defer cancel()
+
+	status, err := client.Test(ctx, &synse.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	if !status.Ok {
+		return nil, fmt.Errorf("plugin test status is not ok")
+	}
+
+	d := &discovered{
+		Name:    "plugin",
+		Type:    "plugin",
+		Address: address,
+	}
+	if meta, err := client.Metadata(ctx, &synse.Empty{}); err == nil {
+		for _, name := range []string{meta.Name, meta.Tag} {
+			if name = contextName(name); name != "" {
+				d.Name = name
+				break
+			}
+		}
+	}
+	if version, err := client.Version(ctx, &synse.Empty{}); err == nil {
+		d.Version = version.PluginVersion
+	}
+	return d, nil
+}
+
+// nameDiscovered names the discovered components. Components with the
+// address of an existing context of the same type take the name of that
+// context. Otherwise, the name is generated from the component's base name
+// and its host, and the port if it is not the default for the type.
+func nameDiscovered(found []discovered) []discovered {
+	taken := map[string]bool{}
+	for _, ctx := range config.GetContexts() {
+		taken[ctx.Name] = true
+	}
+
+	for i, d := range found {
+		if existing := contextForAddress(d.Type, d.Address); existing != "" {
+			found[i].Name = existing
+			found[i].Exists = true
+			continue
+		}
+
+		host, port, _ := net.SplitHostPort(d.Address)
+		name := d.Name + "-" + contextName(host)
+		if (d.Type == "server" && port != strconv.Itoa(defaultServerPort)) ||
+			(d.Type == "plugin" && port != strconv.Itoa(defaultPluginPort)) {
+			name += "-" + port
+		}
+		if taken[name] {
+			name = config.UniqueName(name, taken)
+		}
+		taken[name] = true
+		found[i].Name = name
+	}
+	return found
+}
+
+// contextForAddress gets the name of the context of the given type with
+// the given address, if there is one.
+func contextForAddress(ctxType, address string) string {
+	for _, ctx := range config.GetContexts() {
+		if ctx.Type == ctxType && ctx.Context.Address == address {
+			return ctx.Name
+		}
+	}
+	return ""
+}
+
+// contextName converts a string into a form suitable for use in a context
+// name: lower case, with runs of characters other than letters and digits
+// replaced by a single dash.
+func contextName(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if dash && b.Len() != 0 {
+				b.WriteRune('-')
+			}
+			b.WriteRune(r)
+			dash = false
+		} else {
+			dash = true
+		}
+	}
+	return b.String()
+}
+
+// confirm prompts for a yes or no answer, returning whether the answer was
+// yes. No answer is taken as no.
+func confirm(in io.Reader, out io.Writer, prompt string) (bool, error) {
+	if _, err := fmt.Fprintf(out, "%s [y/N]: ", prompt); err != nil {
+		return false, err
+	}
+
+	answer, err := bufio.NewReader(in).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	if err == io.EOF {
+		// Terminate the prompt line, since no newline was entered.
+		if _, err := fmt.Fprintln(out); err != nil {
+			return false, err
+		}
+	}
+
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,305 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// newServerStandIn starts an HTTP server which responds like Synse Server,
+// returning its address.
+func newServerStandIn(t *testing.T) string {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/test", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"status": "ok", "timestamp": "2019-01-24T14:34:24Z"}`)
+	})
+	mux.HandleFunc("/version", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"version": "3.1.0", "api_version": "v3"}`)
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+// pluginStandIn implements the parts of the plugin gRPC API used to
+// identify a plugin.
+type pluginStandIn struct {
+	synse.V3PluginServer
+}
+
+func (p *pluginStandIn) Test(context.Context, *synse.Empty) (*synse.V3TestStatus, error) {
+	return &synse.V3TestStatus{Ok: true}, nil
+}
+
+func (p *pluginStandIn) Metadata(context.Context, *synse.Empty) (*synse.V3Metadata, error) {
+	return &synse.V3Metadata{Name: "Emulator Plugin", Tag: "vaporio/emulator-plugin"}, nil
+}
+
+func (p *pluginStandIn) Version(context.Context, *synse.Empty) (*synse.V3Version, error) {
+	return &synse.V3Version{PluginVersion: "3.2.1"}, nil
+}
+
+// newPluginStandIn starts an in-process gRPC server which responds like a
+// Synse plugin, returning its address.
+func newPluginStandIn(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	synse.RegisterV3PluginServer(server, &pluginStandIn{})
+	go server.Serve(lis) // nolint: errcheck
+	t.Cleanup(server.Stop)
+	return lis.Addr().String()
+}
+
+// closedPort gets a local port which nothing is listening on.
+func closedPort(t *testing.T) string {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(lis.Addr().String())
+	lis.Close()
+	return port
+}
+
+func portOf(address string) string {
+	_, port, _ := net.SplitHostPort(address)
+	return port
+}
+
+func TestCmdDiscover_noArgs(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdDiscover).Run(t)
+	result.AssertErr()
+	result.AssertGolden("discover.no-args.golden")
+}
+
+func TestCmdDiscover_invalidCIDR(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdDiscover).Args("10.0.0.0/33").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("discover.invalid-cidr.golden")
+}
+
+func TestCmdDiscover_tooManyHosts(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdDiscover).Args("10.0.0.0/16").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("discover.too-many-hosts.golden")
+}
+
+func TestCmdDiscover_nothingFound(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdDiscover).Args(
+		"127.0.0.1",
+		"--ports", closedPort(t),
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("discover.nothing-found.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdDiscover_add(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	server := newServerStandIn(t)
+	plugin := newPluginStandIn(t)
+	closed := closedPort(t)
+
+	result := test.Cmd(cmdDiscover).Args(
+		"127.0.0.1",
+		"--ports", strings.Join([]string{portOf(server), portOf(plugin), closed}, ","),
+		"--no-header",
+	).Input("y\n").Run(t)
+	result.AssertNoErr()
+
+	serverName := "server-127-0-0-1-" + portOf(server)
+	pluginName := "emulator-plugin-127-0-0-1-" + portOf(plugin)
+	out := string(result.Out())
+	assert.Regexp(t, serverName+` +server +`+regexp.QuoteMeta(server)+` +3\.1\.0 +new\n`, out)
+	assert.Regexp(t, pluginName+` +plugin +`+regexp.QuoteMeta(plugin)+` +3\.2\.1 +new\n`, out)
+	assert.Contains(t, out, "Add 2 new context(s)? [y/N]: ")
+	assert.Contains(t, out, "context '"+serverName+"' added\n")
+	assert.Contains(t, out, "context '"+pluginName+"' added\n")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, server, config.GetContext(serverName).Context.Address)
+	assert.Equal(t, "server", config.GetContext(serverName).Type)
+	assert.Equal(t, plugin, config.GetContext(pluginName).Context.Address)
+	assert.Equal(t, "plugin", config.GetContext(pluginName).Type)
+}
+
+func TestCmdDiscover_declined(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	server := newServerStandIn(t)
+
+	result := test.Cmd(cmdDiscover).Args(
+		"127.0.0.1",
+		"--ports", portOf(server),
+	).Input("n\n").Run(t)
+	result.AssertNoErr()
+
+	assert.Contains(t, string(result.Out()), "Add 1 new context(s)? [y/N]: ")
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdDiscover_existing(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	server := newServerStandIn(t)
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "local",
+		Type:    "server",
+		Context: config.Context{Address: server},
+	}))
+
+	result := test.Cmd(cmdDiscover).Args(
+		"127.0.0.1",
+		"--ports", portOf(server),
+		"--no-header",
+	).Run(t)
+	result.AssertNoErr()
+
+	out := string(result.Out())
+	assert.Regexp(t, `local +server +`+regexp.QuoteMeta(server)+` +3\.1\.0 +exists\n`, out)
+	assert.Contains(t, out, "no new contexts to add\n")
+	assert.NotContains(t, out, "[y/N]")
+	assert.Len(t, config.GetContexts(), 1)
+}
+
+func TestCmdDiscover_jsonYes(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	plugin := newPluginStandIn(t)
+	name := "emulator-plugin-127-0-0-1-" + portOf(plugin)
+
+	// A context with the generated name already exists, so another is used.
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    name,
+		Type:    "plugin",
+		Context: config.Context{Address: "10.0.0.1:5001"},
+	}))
+
+	result := test.Cmd(cmdDiscover).Args(
+		"127.0.0.1",
+		"--ports", portOf(plugin),
+		"--json",
+		"--yes",
+	).Run(t)
+	result.AssertNoErr()
+
+	assert.JSONEq(t, `[{
+		"name": "`+name+`-1",
+		"type": "plugin",
+		"address": "`+plugin+`",
+		"version": "3.2.1",
+		"exists": false
+	}]`, string(result.Out()))
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, plugin, config.GetContext(name+"-1").Context.Address)
+}
+
+func TestDiscoverHosts(t *testing.T) {
+	hosts, err := discoverHosts([]string{"10.0.0.0/30,localhost", "10.0.0.1", "10.0.1.0/31", "fd00::/127"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"10.0.0.1", "10.0.0.2", "localhost", "10.0.1.0", "10.0.1.1", "fd00::", "fd00::1"}, hosts)
+}
+
+func TestDiscoverHosts_errors(t *testing.T) {
+	tests := []struct {
+		targets []string
+		err     string
+	}{
+		{[]string{" , "}, "no hosts to scan"},
+		{[]string{"10.0.0.0/40"}, "invalid CIDR range '10.0.0.0/40': invalid CIDR address: 10.0.0.0/40"},
+		{[]string{"fd00::/64"}, "CIDR range 'fd00::/64' is too large (the maximum is 4096 hosts)"},
+		{[]string{"10.0.0.0/20", "10.1.0.0/20"}, "too many hosts to scan (the maximum is 4096)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			_, err := discoverHosts(tt.targets)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestContextName(t *testing.T) {
+	tests := map[string]string{
+		"":                        "",
+		"server":                  "server",
+		"Emulator Plugin":         "emulator-plugin",
+		"vaporio/emulator-plugin": "vaporio-emulator-plugin",
+		"fd00::1":                 "fd00-1",
+		"--a__b--":                "a-b",
+	}
+
+	for in, expected := range tests {
+		assert.Equal(t, expected, contextName(in), strconv.Quote(in))
+	}
+}
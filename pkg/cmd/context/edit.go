@@ -17,29 +17,169 @@
 package context
 
 import (
+	"bufio"
+	"bytes"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+	"gopkg.in/yaml.v2"
 )
 
-var cmdEdit = &cobra.Command{
-
-	// FIXME: this needs to be implemented. Until then, make this hidden.
-	Hidden: true,
+// editHeader is the comment block written at the top of the file opened
+// in the editor.
+const editHeader = `# Please edit the context records below. Lines beginning with a '#' will be
+# ignored, and an empty file will abort the edit. If an error occurs while saving,
+# this file will be reopened with the relevant failures.
+#
+`
 
-	Use:   "edit",
-	Short: "Edit a context record",
+var cmdEdit = &cobra.Command{
+	Use:   "edit [CONTEXT_NAME...]",
+	Short: "Edit context records",
 	Long: utils.Doc(`
 		Edit context records in the synse configuration.
+
+		The context records are opened as YAML in your editor. The editor
+		is taken from the $VISUAL or $EDITOR environment variables, falling
+		back to 'vi' if neither is set. If no context names are given, all
+		context records are opened for editing.
+
+		When the editor is closed, the edited records are validated with the
+		same checks used by 'synse context add'. If validation fails, the
+		editor is re-opened with the errors listed at the top of the file.
+		Changes are only saved once they are valid. Saving an empty file
+		cancels the edit.
+
+		If an edited context was a current context, it will remain current
+		so long as its name and type are unchanged.
 	`),
 	SuggestFor: []string{
 		"update",
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		// todo: implement editing.
-		// see: https://github.com/kubernetes/kubernetes/blob/aef117999658b24628c6ba49685b4d5ca5998308/pkg/kubectl/cmd/util/editor/editor.go
-		// for reference around how kubectl does this.
-		fmt.Println("not yet implemented")
+		exit.FromCmd(cmd).Err(
+			editContexts(cmd.OutOrStdout(), args),
+		)
 	},
 }
+
+func editContexts(out io.Writer, names []string) error {
+	log.WithFields(log.Fields{
+		"names": names,
+	}).Debug("editing contexts")
+
+	var records []config.ContextRecord
+	if len(names) == 0 {
+		records = config.GetContexts()
+		for _, ctx := range records {
+			names = append(names, ctx.Name)
+		}
+	} else {
+		for _, name := range names {
+			ctx := config.GetContext(name)
+			if ctx == nil {
+				return fmt.Errorf("cannot edit '%s': no such context", name)
+			}
+			records = append(records, *ctx)
+		}
+	}
+	if len(records) == 0 {
+		return fmt.Errorf("no contexts to edit (see 'synse context add')")
+	}
+
+	original, err := yaml.Marshal(records)
+	if err != nil {
+		return err
+	}
+
+	editor := utils.NewDefaultEditor()
+	content := original
+	var errs []error
+
+	for {
+		edited, path, err := editor.LaunchTempFile("synse-context-", ".yaml", bytes.NewReader(editBuffer(content, errs)))
+		if path != "" {
+			defer os.Remove(path)
+		}
+		if err != nil {
+			return err
+		}
+
+		edited = stripComments(edited)
+		if len(bytes.TrimSpace(edited)) == 0 {
+			_, err = fmt.Fprintln(out, "Edit cancelled, saved file was empty.")
+			return err
+		}
+		if bytes.Equal(edited, original) {
+			_, err = fmt.Fprintln(out, "Edit cancelled, no changes made.")
+			return err
+		}
+
+		// If the file was saved with errors that were not addressed, stop
+		// rather than re-opening the editor indefinitely.
+		if len(errs) != 0 && bytes.Equal(edited, content) {
+			return fmt.Errorf("edit cancelled, the following errors were not corrected:\n%s", strings.TrimSuffix(formatErrors(errs, ""), "\n"))
+		}
+		content = edited
+
+		var updated []config.ContextRecord
+		if err := yaml.UnmarshalStrict(edited, &updated); err != nil {
+			log.WithField("error", err).Debug("failed to parse edited contexts")
+			errs = []error{err}
+			continue
+		}
+
+		errs = config.ReplaceContexts(names, updated)
+		if len(errs) == 0 {
+			log.Debug("edited contexts are valid")
+			return nil
+		}
+		log.WithField("errors", errs).Debug("edited contexts failed validation")
+	}
+}
+
+// editBuffer builds the contents of the file opened in the editor, adding
+// the header and any validation errors from a previous edit as comments.
+func editBuffer(content []byte, errs []error) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(editHeader)
+	if len(errs) != 0 {
+		buf.WriteString("# The edited contexts could not be saved:\n")
+		buf.WriteString(formatErrors(errs, "# "))
+		buf.WriteString("#\n")
+	}
+	buf.Write(content)
+	return buf.Bytes()
+}
+
+// formatErrors formats each error as a list item on its own line, with
+// every line prefixed by the given prefix.
+func formatErrors(errs []error, prefix string) string {
+	var sb strings.Builder
+	for _, err := range errs {
+		msg := strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", "\n"+prefix+"  ")
+		sb.WriteString(prefix + "* " + msg + "\n")
+	}
+	return sb.String()
+}
+
+// stripComments removes all comment lines from the edited content.
+func stripComments(data []byte) []byte {
+	var buf bytes.Buffer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		buf.WriteString(line + "\n")
+	}
+	return buf.Bytes()
+}
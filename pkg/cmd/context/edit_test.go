@@ -0,0 +1,274 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// setEditor configures a fake editor which replaces the edited file with each
+// of the given contents in turn, one per launch. The file as it was presented
+// to the editor on each launch is saved, and the directory holding those files
+// is returned.
+func setEditor(t *testing.T, contents ...string) string {
+	dir := t.TempDir()
+	for i, c := range contents {
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("edit.%d", i)), []byte(c), 0644))
+	}
+
+	script := filepath.Join(dir, "editor.sh")
+	assert.NoError(t, os.WriteFile(script, []byte(fmt.Sprintf(`#!/bin/sh
+n=$(cat "%[1]s/count" 2>/dev/null || echo 0)
+cp "$1" "%[1]s/seen.$n"
+if [ -f "%[1]s/edit.$n" ]; then cp "%[1]s/edit.$n" "$1"; fi
+echo $((n+1)) > "%[1]s/count"
+`, dir)), 0755))
+
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", script)
+	return dir
+}
+
+var editTestContexts = []config.ContextRecord{
+	{
+		Name: "server-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	},
+	{
+		Name: "plugin-ctx",
+		Type: "plugin",
+		Context: config.Context{
+			Address: "localhost:5001",
+		},
+	},
+}
+
+func TestCmdEdit_noContexts(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	setEditor(t)
+
+	result := test.Cmd(cmdEdit).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("edit.no-contexts.golden")
+}
+
+func TestCmdEdit_nonexistent(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	setEditor(t)
+
+	result := test.Cmd(cmdEdit).Args("foo").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("edit.nonexisting.golden")
+}
+
+func TestCmdEdit_noChanges(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	dir := setEditor(t)
+
+	result := test.Cmd(cmdEdit).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("edit.no-changes.golden")
+
+	seen, err := os.ReadFile(filepath.Join(dir, "seen.0"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(seen), "name: server-ctx")
+	assert.Contains(t, string(seen), "name: plugin-ctx")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Len(t, config.GetCurrentContext(), 1)
+}
+
+func TestCmdEdit_emptyFile(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	setEditor(t, "# only a comment\n")
+
+	result := test.Cmd(cmdEdit).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("edit.empty.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+}
+
+func TestCmdEdit_namedContext(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	dir := setEditor(t, `
+- name: server-ctx
+  type: server
+  context:
+    address: 10.1.2.3:5000
+`)
+
+	result := test.Cmd(cmdEdit).Args("server-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	seen, err := os.ReadFile(filepath.Join(dir, "seen.0"))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(seen), "plugin-ctx")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, "10.1.2.3:5000", config.GetContext("server-ctx").Context.Address)
+	assert.Equal(t, "localhost:5001", config.GetContext("plugin-ctx").Context.Address)
+
+	// The edited context should remain the current context.
+	current := config.GetCurrentContext()
+	assert.Len(t, current, 1)
+	assert.Equal(t, "server-ctx", current["server"].Name)
+}
+
+func TestCmdEdit_renameDropsCurrent(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	setEditor(t, `
+- name: other-ctx
+  type: server
+  context:
+    address: localhost:5000
+`)
+
+	result := test.Cmd(cmdEdit).Args("server-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Nil(t, config.GetContext("server-ctx"))
+	assert.NotNil(t, config.GetContext("other-ctx"))
+	assert.Len(t, config.GetCurrentContext(), 0)
+}
+
+func TestCmdEdit_invalidThenValid(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	dir := setEditor(t, `
+- name: server-ctx
+  type: bad-type
+  context:
+    address: localhost:5000
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: localhost:abc
+`, `
+- name: server-ctx
+  type: server
+  context:
+    address: localhost:5000
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: localhost:5002
+`)
+
+	result := test.Cmd(cmdEdit).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	// The second launch should include the errors from the first.
+	seen, err := os.ReadFile(filepath.Join(dir, "seen.1"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(seen), "# * unsupported context type: bad-type\n")
+	assert.Contains(t, string(seen), "# * context 'plugin-ctx' has an invalid address")
+	assert.Contains(t, string(seen), "type: bad-type")
+
+	assert.Equal(t, "localhost:5002", config.GetContext("plugin-ctx").Context.Address)
+	assert.Len(t, config.GetCurrentContext(), 1)
+}
+
+func TestCmdEdit_duplicateName(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	dir := setEditor(t, `
+- name: plugin-ctx
+  type: server
+  context:
+    address: localhost:5000
+`)
+
+	result := test.Cmd(cmdEdit).Args("server-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("edit.duplicate.golden")
+
+	seen, err := os.ReadFile(filepath.Join(dir, "seen.1"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(seen), "# * context 'plugin-ctx': name already exists\n")
+
+	// Nothing should have changed.
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, "server", config.GetContext("server-ctx").Type)
+	assert.Len(t, config.GetCurrentContext(), 1)
+}
+
+func TestCmdEdit_unparsable(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, editTestContexts, "server-ctx")
+	setEditor(t, `
+- name: server-ctx
+  type: server
+  unknown: field
+`)
+
+	result := test.Cmd(cmdEdit).Args("server-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("edit.unparsable.golden")
+
+	assert.Equal(t, "localhost:5000", config.GetContext("server-ctx").Context.Address)
+}
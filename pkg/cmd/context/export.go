@@ -0,0 +1,81 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"io"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	cmdExport.Flags().BoolVarP(&flagEmbedCerts, "embed-certs", "", false, "include TLS certificates and keys inline as base64 data")
+	cmdExport.Flags().BoolVarP(&flagIncludeCredentials, "include-credentials", "", false, "include the authentication settings and proxy passwords of the contexts")
+}
+
+var cmdExport = &cobra.Command{
+	Use:   "export [CONTEXT_NAME...]",
+	Short: "Export contexts as a shareable bundle",
+	Long: utils.Doc(`
+		Export context records as a YAML bundle which can be imported into
+		another synse configuration with 'synse context import'.
+
+		If no context names are given, all contexts are exported. Any of the
+		exported contexts which are current are recorded in the bundle.
+
+		TLS certificates and keys are referenced by path by default. If the
+		--embed-certs flag is set, the files are read and included in the
+		bundle, so the bundle can be used on machines which do not have
+		them at the same path.
+
+		The authentication settings of the contexts (headers, tokens and
+		credential helpers) and any proxy passwords are left out of the
+		bundle, so that it can be shared safely. They are included if the
+		--include-credentials flag is set, in which case the bundle holds
+		them in plain text.
+	`),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(
+			exportContexts(cmd.OutOrStdout(), args),
+		)
+	},
+}
+
+func exportContexts(out io.Writer, names []string) error {
+	log.WithFields(log.Fields{
+		"names":               names,
+		"embed-certs":         flagEmbedCerts,
+		"include-credentials": flagIncludeCredentials,
+	}).Debug("exporting contexts")
+
+	bundle, err := config.Export(names, flagEmbedCerts, flagIncludeCredentials)
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(bundle)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
@@ -0,0 +1,122 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+	"gopkg.in/yaml.v2"
+)
+
+func init() {
+	cmdImport.Flags().BoolVarP(&flagSet, "set", "", false, "set the bundle's current contexts as the current contexts")
+	cmdImport.Flags().StringVarP(&flagOnConflict, "on-conflict", "", string(config.MergeSkip), "how to handle contexts which already exist (skip, overwrite, rename)")
+	cmdImport.Flags().StringVarP(&flagCertDir, "cert-dir", "", "", "directory to write embedded certificates to (default ~/.synse/certs)")
+}
+
+var cmdImport = &cobra.Command{
+	Use:   "import FILE",
+	Short: "Import contexts from a bundle",
+	Long: utils.Doc(`
+		Import context records from a bundle created by 'synse context export'.
+
+		The bundle is read from FILE. If FILE is '-', the bundle is read from
+		stdin.
+
+		If an imported context has the same name as an existing context, the
+		--on-conflict flag determines how it is handled:
+
+		<bold>skip</>      : Keep the existing context and ignore the imported one.
+		<bold>overwrite</> : Replace the existing context with the imported one.
+		<bold>rename</>    : Import the context under a new name (e.g. "name-1").
+
+		TLS certificates and keys embedded in the bundle are written to new
+		files in the cert directory and the imported contexts are updated to
+		reference them. Existing files are never overwritten; if a file name
+		is taken, a numeric suffix is added (e.g. "name-1.pem").
+
+		If the --set flag is given, the contexts which were current when the
+		bundle was exported are set as current, following any renames.
+
+		All imported contexts are validated before any are added or any
+		certificates are written, so an invalid bundle does not partially
+		modify the configuration.
+	`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(
+			importContexts(cmd.InOrStdin(), cmd.OutOrStdout(), args[0]),
+		)
+	},
+}
+
+func importContexts(in io.Reader, out io.Writer, file string) error {
+	log.WithFields(log.Fields{
+		"file":        file,
+		"on-conflict": flagOnConflict,
+		"cert-dir":    flagCertDir,
+		"set":         flagSet,
+	}).Debug("importing contexts")
+
+	var (
+		data []byte
+		err  error
+	)
+	if file == "-" {
+		data, err = io.ReadAll(in)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+
+	var bundle config.Bundle
+	if err := yaml.UnmarshalStrict(data, &bundle); err != nil {
+		return fmt.Errorf("failed to parse context bundle: %v", err)
+	}
+
+	certDir := flagCertDir
+	if certDir == "" {
+		certDir = config.DefaultCertDir()
+	}
+
+	results, err := config.Import(&bundle, config.MergeStrategy(flagOnConflict), certDir, flagSet)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range results {
+		switch r.Action {
+		case "renamed":
+			_, err = fmt.Fprintf(out, "context '%s' imported as '%s'\n", r.Original, r.Name)
+		default:
+			_, err = fmt.Fprintf(out, "context '%s' %s\n", r.Name, r.Action)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
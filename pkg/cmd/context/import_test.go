@@ -0,0 +1,400 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+const testBundle = `
+contexts:
+- name: server-ctx
+  type: server
+  context:
+    address: 10.0.0.1:5000
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: 10.0.0.1:5001
+current_context:
+  server: server-ctx
+`
+
+// writeBundle writes the bundle contents to a temporary file, returning
+// the path to the file.
+func writeBundle(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "bundle.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(contents), 0644))
+	return path
+}
+
+// conflictingTestContexts are contexts which conflict with the test bundle.
+var conflictingTestContexts = []config.ContextRecord{
+	{
+		Name: "server-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	},
+}
+
+func TestCmdImport_noArgs(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdImport).Run(t)
+	result.AssertErr()
+	result.AssertGolden("import.no-args.golden")
+}
+
+func TestCmdImport_noFile(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdImport).Args("/tmp/test/does-not-exist.yaml").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("import.no-file.golden")
+}
+
+func TestCmdImport_badStrategy(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdImport).Args(
+		writeBundle(t, testBundle),
+		"--on-conflict", "merge",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("import.bad-strategy.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdImport_invalidContext(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdImport).Args(writeBundle(t, `
+contexts:
+- name: server-ctx
+  type: server
+  context:
+    address: 10.0.0.1:5000
+- name: bad-ctx
+  type: foo
+  context:
+    address: 10.0.0.1:5001
+`)).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("import.invalid.golden")
+
+	// Nothing should be imported if any context is invalid.
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdImport_add(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdImport).Args(writeBundle(t, testBundle)).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.add.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Len(t, config.GetCurrentContext(), 0)
+}
+
+func TestCmdImport_addAndSet(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdImport).Args(writeBundle(t, testBundle), "--set").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.add.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	current := config.GetCurrentContext()
+	assert.Len(t, current, 1)
+	assert.Equal(t, "server-ctx", current["server"].Name)
+}
+
+func TestCmdImport_skip(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, conflictingTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdImport).Args(writeBundle(t, testBundle), "--set").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.skip.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, "localhost:5000", config.GetContext("server-ctx").Context.Address)
+	assert.Equal(t, "server-ctx", config.GetCurrentContext()["server"].Name)
+}
+
+func TestCmdImport_overwrite(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, conflictingTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdImport).Args(
+		writeBundle(t, testBundle),
+		"--on-conflict", "overwrite",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.overwrite.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+	assert.Equal(t, "10.0.0.1:5000", config.GetContext("server-ctx").Context.Address)
+
+	// The overwritten context has the same name and type, so it stays current.
+	assert.Equal(t, "server-ctx", config.GetCurrentContext()["server"].Name)
+}
+
+func TestCmdImport_renameAndSet(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, conflictingTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdImport).Args(
+		writeBundle(t, testBundle),
+		"--on-conflict", "rename",
+		"--set",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.rename.golden")
+
+	assert.Len(t, config.GetContexts(), 3)
+	assert.Equal(t, "localhost:5000", config.GetContext("server-ctx").Context.Address)
+	assert.Equal(t, "10.0.0.1:5000", config.GetContext("server-ctx-1").Context.Address)
+
+	// The bundle's current context should follow the rename.
+	assert.Equal(t, "server-ctx-1", config.GetCurrentContext()["server"].Name)
+}
+
+func TestCmdImport_stdin(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	data, err := os.ReadFile(writeBundle(t, testBundle))
+	assert.NoError(t, err)
+
+	result := test.Cmd(cmdImport).Args("-").Input(string(data)).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.add.golden")
+
+	assert.Len(t, config.GetContexts(), 2)
+}
+
+func TestCmdImport_embeddedCert(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	certDir := filepath.Join(t.TempDir(), "certs")
+	result := test.Cmd(cmdImport).Args(writeBundle(t, `
+contexts:
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: 10.0.0.1:5001
+  client_cert_data: dGVzdCBjZXJ0aWZpY2F0ZSBkYXRh
+`), "--cert-dir", certDir).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.embedded-cert.golden")
+
+	ctx := config.GetContext("plugin-ctx")
+	assert.NotNil(t, ctx)
+	assert.Equal(t, filepath.Join(certDir, "plugin-ctx.pem"), ctx.Context.ClientCert)
+
+	data, err := os.ReadFile(ctx.Context.ClientCert)
+	assert.NoError(t, err)
+	assert.Equal(t, "test certificate data", string(data))
+}
+
+func TestCmdImport_embeddedCertInvalidContext(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	certDir := filepath.Join(t.TempDir(), "certs")
+	result := test.Cmd(cmdImport).Args(writeBundle(t, `
+contexts:
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: 10.0.0.1:5001
+  client_cert_data: dGVzdCBjZXJ0aWZpY2F0ZSBkYXRh
+- name: bad-ctx
+  type: foo
+  context:
+    address: 10.0.0.1:5002
+`), "--cert-dir", certDir).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("import.invalid.golden")
+
+	// No certs should be written if any context is invalid.
+	assert.Len(t, config.GetContexts(), 0)
+	_, err := os.Stat(certDir)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestCmdImport_embeddedCertExistingFile(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	certDir := filepath.Join(t.TempDir(), "certs")
+	existing := filepath.Join(certDir, "plugin-ctx.pem")
+	assert.NoError(t, os.MkdirAll(certDir, 0700))
+	assert.NoError(t, os.WriteFile(existing, []byte("existing certificate data"), 0600))
+
+	result := test.Cmd(cmdImport).Args(writeBundle(t, `
+contexts:
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: 10.0.0.1:5001
+  client_cert_data: dGVzdCBjZXJ0aWZpY2F0ZSBkYXRh
+`), "--cert-dir", certDir).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("import.embedded-cert.golden")
+
+	// The existing file must not be overwritten.
+	ctx := config.GetContext("plugin-ctx")
+	assert.NotNil(t, ctx)
+	assert.Equal(t, filepath.Join(certDir, "plugin-ctx-1.pem"), ctx.Context.ClientCert)
+
+	data, err := os.ReadFile(existing)
+	assert.NoError(t, err)
+	assert.Equal(t, "existing certificate data", string(data))
+
+	data, err = os.ReadFile(ctx.Context.ClientCert)
+	assert.NoError(t, err)
+	assert.Equal(t, "test certificate data", string(data))
+}
+
+func TestCmdImport_roundTrip(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	dir := t.TempDir()
+	cert := filepath.Join(dir, "cert.pem")
+	assert.NoError(t, os.WriteFile(cert, []byte("test certificate data"), 0600))
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "plugin-ctx",
+		Type: "plugin",
+		Context: config.Context{
+			Address:    "localhost:5001",
+			ClientCert: cert,
+		},
+	}))
+
+	bundle, err := config.Export(nil, true, false)
+	assert.NoError(t, err)
+	config.Purge()
+
+	results, err := config.Import(bundle, config.MergeSkip, filepath.Join(dir, "certs"), false)
+	assert.NoError(t, err)
+	assert.Equal(t, []config.ImportResult{{Name: "plugin-ctx", Original: "plugin-ctx", Action: "added"}}, results)
+
+	data, err := os.ReadFile(config.GetContext("plugin-ctx").Context.ClientCert)
+	assert.NoError(t, err)
+	assert.Equal(t, "test certificate data", string(data))
+}
+
+func TestCmdImport_roundTripKeyPair(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	certs := test.NewCerts(t)
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "plugin-ctx",
+		Type: "plugin",
+		Context: config.Context{
+			Address:    "localhost:5001",
+			ClientCert: certs.ClientCert,
+			ClientKey:  certs.ClientKey,
+			CACert:     certs.CACert,
+		},
+	}))
+
+	bundle, err := config.Export(nil, true, false)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, bundle.Contexts[0].ClientKeyData)
+	assert.NotEmpty(t, bundle.Contexts[0].CACertData)
+	config.Purge()
+
+	certDir := filepath.Join(t.TempDir(), "certs")
+	_, err = config.Import(bundle, config.MergeSkip, certDir, false)
+	assert.NoError(t, err)
+
+	ctx := config.GetContext("plugin-ctx").Context
+	assert.Equal(t, filepath.Join(certDir, "plugin-ctx.pem"), ctx.ClientCert)
+	assert.Equal(t, filepath.Join(certDir, "plugin-ctx-key.pem"), ctx.ClientKey)
+	assert.Equal(t, filepath.Join(certDir, "plugin-ctx-ca.pem"), ctx.CACert)
+
+	for original, imported := range map[string]string{
+		certs.ClientCert: ctx.ClientCert,
+		certs.ClientKey:  ctx.ClientKey,
+		certs.CACert:     ctx.CACert,
+	} {
+		expected, err := os.ReadFile(original)
+		assert.NoError(t, err)
+		actual, err := os.ReadFile(imported)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
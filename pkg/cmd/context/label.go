@@ -0,0 +1,84 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+)
+
+var cmdLabel = &cobra.Command{
+	Use:   "label CONTEXT_NAME KEY=VALUE... [KEY-...]",
+	Short: "Set or remove labels on a context",
+	Long: utils.Doc(`
+		Set or remove the labels on a context.
+
+		Labels are arbitrary key/value pairs, such as the site, environment
+		or region of a context. A label is set with KEY=VALUE, replacing any
+		existing value, and removed with KEY- (a key followed by a dash).
+		Label keys and values may contain letters, digits, '-', '_' and '.',
+		and keys may also contain '/'. For example:
+
+		  synse context label site-1 env=prod site=atl region-
+
+		Contexts can then be selected by their labels with the -l/--selector
+		flag of 'synse context list', 'synse context set' and the server and
+		plugin commands, e.g. -l env=prod,site!=lab.
+	`),
+	Args: cobra.MinimumNArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(labelContext(args[0], args[1:]))
+	},
+}
+
+func labelContext(name string, labels []string) error {
+	log.WithFields(log.Fields{
+		"name":   name,
+		"labels": labels,
+	}).Debug("labeling context")
+
+	set, remove, err := parseLabels(labels)
+	if err != nil {
+		return err
+	}
+	return config.LabelContext(name, set, remove)
+}
+
+// parseLabels parses the label arguments into the labels to set, given as
+// KEY=VALUE, and the keys of the labels to remove, given as KEY-.
+func parseLabels(labels []string) (map[string]string, []string, error) {
+	set := map[string]string{}
+	var remove []string
+	for _, label := range labels {
+		if key, value, ok := strings.Cut(label, "="); ok {
+			set[key] = value
+			continue
+		}
+		if key := strings.TrimSuffix(label, "-"); key != label && key != "" {
+			remove = append(remove, key)
+			continue
+		}
+		return nil, nil, fmt.Errorf("invalid label '%s' (expected 'KEY=VALUE' or 'KEY-')", label)
+	}
+	return set, remove, nil
+}
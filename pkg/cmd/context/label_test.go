@@ -0,0 +1,134 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// labeledTestContexts are labeled server and plugin contexts.
+var labeledTestContexts = []config.ContextRecord{
+	{Name: "atl-prod", Type: "server", Context: config.Context{Address: "10.1.0.5:5000"}, Labels: map[string]string{"env": "prod", "site": "atl"}},
+	{Name: "atl-lab", Type: "server", Context: config.Context{Address: "10.1.1.5:5000"}, Labels: map[string]string{"env": "lab", "site": "atl"}},
+	{Name: "ord-prod", Type: "server", Context: config.Context{Address: "10.2.0.5:5000"}, Labels: map[string]string{"env": "prod", "site": "ord"}},
+	{Name: "atl-emulator", Type: "plugin", Context: config.Context{Address: "10.1.0.6:5001"}, Labels: map[string]string{"env": "prod", "site": "atl"}},
+	{Name: "unlabeled", Type: "server", Context: config.Context{Address: "localhost:5000"}},
+}
+
+func TestCmdLabel(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdLabel).Args(
+		"atl-prod",
+		"env=staging",
+		"region=us-east",
+		"site-",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Equal(t, map[string]string{"env": "staging", "region": "us-east"}, config.GetContext("atl-prod").Labels)
+}
+
+func TestCmdLabel_notEnoughArgs(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdLabel).Args(
+		"atl-prod",
+	).Run(t)
+	result.AssertErr()
+	result.AssertGolden("label.not-enough-args.golden")
+}
+
+func TestCmdLabel_malformed(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdLabel).Args(
+		"atl-prod",
+		"env=staging",
+		"site",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("label.malformed.golden")
+
+	assert.Equal(t, "prod", config.GetContext("atl-prod").Labels["env"])
+}
+
+func TestCmdLabel_invalid(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdLabel).Args(
+		"atl-prod",
+		"env=prod,lab",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("label.invalid.golden")
+
+	assert.Equal(t, "prod", config.GetContext("atl-prod").Labels["env"])
+}
+
+func TestCmdLabel_nonexisting(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdLabel).Args(
+		"missing",
+		"env=prod",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("label.nonexisting.golden")
+}
+
+func TestParseLabels(t *testing.T) {
+	set, remove, err := parseLabels([]string{"env=prod", "tier=", "site-", "region-name=x", "old-name-"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod", "tier": "", "region-name": "x"}, set)
+	assert.Equal(t, []string{"site", "old-name"}, remove)
+}
+
+func TestParseLabels_errors(t *testing.T) {
+	for _, label := range []string{"env", "-", ""} {
+		_, _, err := parseLabels([]string{label})
+		assert.EqualError(t, err, "invalid label '"+label+"' (expected 'KEY=VALUE' or 'KEY-')")
+	}
+}
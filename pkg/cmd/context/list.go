@@ -17,20 +17,25 @@
 package context
 
 import (
+	"fmt"
 	"io"
 	"sort"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/config"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
 func init() {
 	cmdList.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdList.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdList.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdList.Flags(), &flagOutput)
+	cmdList.Flags().BoolVarP(&flagCheck, "check", "", false, "probe each context and show whether it is reachable")
+	cmdList.Flags().StringVarP(&flagSelector, "selector", "l", "", "only list the contexts matching a label selector (e.g. env=prod,site!=lab)")
+	cmdList.Flags().BoolVarP(&flagShowLabels, "show-labels", "", false, "show the labels of each context")
 }
 
 var cmdList = &cobra.Command{
@@ -39,7 +44,23 @@ var cmdList = &cobra.Command{
 	Long: utils.Doc(`
 		List all configured contexts.
 
-		This will display each configured context record.
+		This will display each configured context record, along with the
+		config layer (e.g. home, project) it is loaded from and persisted
+		to. See 'synse config --help' for details on config layers.
+
+		With the --check flag, each context is probed concurrently and the
+		output includes whether it is reachable, the latency of the probe,
+		the version it reports and its TLS mode. Server contexts are probed
+		with the HTTP status and version requests, and plugin contexts with
+		the gRPC test and version requests. Failed requests are not retried,
+		and each times out after 5 seconds. The command exits with an error
+		if any current context is unreachable, so it can be used as a quick
+		pre-flight check.
+
+		The contexts can be filtered by their labels with -l/--selector. A
+		selector is a comma separated list of requirements, which must all be
+		met: key=value, key!=value, key (the label is set) and !key (the label
+		is not set). See 'synse context label --help' for setting labels.
 	`),
 	Aliases: []string{
 		"ls",
@@ -47,26 +68,78 @@ var cmdList = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		exiter := exit.FromCmd(cmd)
 
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(listContexts(cmd.OutOrStdout()))
+		exiter.Err(listContexts(cmd.OutOrStdout(), func(name string) clients.Factory {
+			return clients.ForContext(cmd, name, utils.NewClientFactory(checkClientOptions(name)))
+		}))
 	},
 }
 
-func listContexts(out io.Writer) error {
+// listRecord is a context record along with the name of the config layer
+// it is persisted to.
+type listRecord struct {
+	config.ContextRecord `yaml:",inline"`
+
+	Source string `json:"source,omitempty" yaml:"source,omitempty"`
+
+	// Check holds the result of probing the context, if it was checked.
+	Check *contextCheck `json:"check,omitempty" yaml:"check,omitempty"`
+}
+
+func listContexts(out io.Writer, factory func(name string) clients.Factory) error {
 	contexts := config.GetContexts()
+	if flagSelector != "" {
+		selector, err := config.ParseSelector(flagSelector)
+		if err != nil {
+			return err
+		}
+		contexts = config.SelectContexts(selector, "")
+	}
 	if len(contexts) == 0 {
 		log.Debug("no contexts found")
 		return nil
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
-	printer.SetHeader("CURRENT", "NAME", "TYPE", "ADDRESS")
-	printer.SetRowFunc(contextRowFunc)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	header := []string{"CURRENT", "NAME", "TYPE", "ADDRESS", "SOURCE", "PROTECTED"}
+	rowFunc := contextListRowFunc
+	if flagCheck {
+		header = append(header, "REACHABLE", "LATENCY", "VERSION", "TLS")
+		rowFunc = contextCheckRowFunc
+	}
+	if flagShowLabels {
+		header = append(header, "LABELS")
+		rowFunc = withLabelsRowFunc(rowFunc)
+	}
+	printer.SetHeader(header...)
+	printer.SetRowFunc(rowFunc)
 
 	sort.Sort(Records(contexts))
-	return printer.Write(contexts)
+
+	var records []listRecord
+	for i := range contexts {
+		records = append(records, listRecord{
+			ContextRecord: redactRecord(contexts[i]),
+			Source:        config.SourceLayer(&contexts[i]),
+		})
+	}
+	if !flagCheck {
+		return printer.Write(records)
+	}
+
+	log.Debug("checking contexts")
+	var unreachable []string
+	for i, check := range checkContexts(contexts, factory) {
+		records[i].Check = check
+		if !check.Reachable && config.IsCurrentContext(&contexts[i]) {
+			unreachable = append(unreachable, contexts[i].Name)
+		}
+	}
+	if err := printer.Write(records); err != nil {
+		return err
+	}
+
+	if len(unreachable) != 0 {
+		return fmt.Errorf("current context unreachable: %s", strings.Join(unreachable, ", "))
+	}
+	return nil
 }
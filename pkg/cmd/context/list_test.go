@@ -17,7 +17,10 @@
 package context
 
 import (
+	"errors"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-cli/internal/test"
@@ -34,8 +37,7 @@ func TestCmdList_multipleFmtFlags(t *testing.T) {
 		"--yaml",
 		"--json",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
+	result.AssertErr()
 	result.AssertGolden("list.multiple-fmt-flags.golden")
 
 	assert.Len(t, config.GetContexts(), 0)
@@ -201,3 +203,296 @@ func TestCmdList_json(t *testing.T) {
 	assert.Len(t, config.GetContexts(), 2)
 	assert.Len(t, config.GetCurrentContext(), 1)
 }
+
+func TestCmdList_jsonPath(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "server-ctx",
+		Type:    "server",
+		Context: config.Context{Address: "0.0.0.0"},
+	}))
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "plugin-ctx",
+		Type:    "plugin",
+		Context: config.Context{Address: "foo/bar"},
+	}))
+
+	result := test.Cmd(cmdList).Args(
+		"-o", `jsonpath={range [*]}{.name}{"\t"}{.context.address}{"\n"}{end}`,
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.jsonpath.golden")
+}
+
+func TestCmdList_goTemplate(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "server-ctx",
+		Type:    "server",
+		Context: config.Context{Address: "0.0.0.0"},
+	}))
+
+	result := test.Cmd(cmdList).Args(
+		"--output", `go-template={{range .}}{{.name}} ({{.type}}){{"\n"}}{{end}}`,
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.go-template.golden")
+}
+
+func TestCmdList_badOutput(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdList).Args(
+		"-o", "xml",
+	).Run(t)
+	result.AssertErr()
+	result.AssertGolden("list.bad-output.golden")
+}
+
+func TestCmdList_sourceLayers(t *testing.T) {
+	defer resetFlags()
+	dirs := test.NewConfigDirs(t)
+	test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), `
+contexts:
+- name: server-ctx
+  type: server
+  context:
+    address: 0.0.0.0
+current_context:
+  server: server-ctx
+`)
+	test.WriteConfig(t, filepath.Join(dirs.WD, ".synse.yml"), `
+contexts:
+- name: plugin-ctx
+  type: plugin
+  context:
+    address: foo/bar
+`)
+	assert.NoError(t, config.Load())
+
+	result := test.Cmd(cmdList).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.source-layers.golden")
+}
+
+func TestCmdList_sourceLayersYaml(t *testing.T) {
+	defer resetFlags()
+	dirs := test.NewConfigDirs(t)
+	test.WriteConfig(t, filepath.Join(dirs.Home, ".synse.yml"), `
+contexts:
+- name: server-ctx
+  type: server
+  context:
+    address: 0.0.0.0
+`)
+	assert.NoError(t, config.Load())
+
+	result := test.Cmd(cmdList).Args("--yaml").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.source-layers-yaml.golden")
+}
+
+// checkTestContexts are a server context with TLS, an unreachable plugin context
+// and a reachable plugin context.
+var checkTestContexts = []config.ContextRecord{
+	{
+		Name: "server-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address:    "0.0.0.0",
+			ClientCert: "/tmp/test/dir",
+		},
+	},
+	{
+		Name:    "plugin-down",
+		Type:    "plugin",
+		Context: config.Context{Address: "10.0.0.2:5001"},
+	},
+	{
+		Name:    "plugin-up",
+		Type:    "plugin",
+		Context: config.Context{Address: "10.0.0.1:5001"},
+	},
+}
+
+func fixLatency(t *testing.T) {
+	timeSince = func(time.Time) time.Duration { return 12345 * time.Microsecond }
+	t.Cleanup(func() { timeSince = time.Since })
+}
+
+func TestCmdList_check(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	fixLatency(t)
+	test.NewConfigDirs(t)
+	assert.NoError(t, config.Load())
+	test.AddContexts(t, checkTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdList).WithClients(test.ContextClients{
+		"server-ctx":  {Server: test.NewFakeHTTPClientV3()},
+		"plugin-up":   {Plugin: test.NewFakeGRPCClientV3()},
+		"plugin-down": {Plugin: test.NewFakeGRPCClientV3Err()},
+	}).Args(
+		"--check",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.check.golden")
+}
+
+func TestCmdList_checkJSON(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	fixLatency(t)
+	test.NewConfigDirs(t)
+	assert.NoError(t, config.Load())
+	test.AddContexts(t, checkTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdList).WithClients(test.ContextClients{
+		"server-ctx":  {Server: test.NewFakeHTTPClientV3()},
+		"plugin-up":   {Plugin: test.NewFakeGRPCClientV3()},
+		"plugin-down": {Plugin: test.NewFakeGRPCClientV3Err()},
+	}).Args(
+		"--check",
+		"--json",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.check.json.golden")
+}
+
+func TestCmdList_checkCurrentUnreachable(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	fixLatency(t)
+	test.NewConfigDirs(t)
+	assert.NoError(t, config.Load())
+	test.AddContexts(t, checkTestContexts, "server-ctx")
+	assert.NoError(t, config.SetCurrentContext("plugin-down"))
+
+	result := test.Cmd(cmdList).WithClients(test.ContextClients{
+		"server-ctx":  {Server: test.NewFakeHTTPClientV3Err()},
+		"plugin-up":   {Plugin: test.NewFakeGRPCClientV3()},
+		"plugin-down": {Err: errors.New("connection refused")},
+	}).Args(
+		"--check",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("list.check.unreachable.golden")
+}
+
+func TestCmdList_yamlRedacted(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "server-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address:          "0.0.0.0",
+			Headers:          map[string]string{"X-Api-Key": "123"},
+			Token:            "abc",
+			CredentialHelper: "synse-credentials",
+		},
+	}))
+
+	result := test.Cmd(cmdList).Args(
+		"--yaml",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.yaml-redacted.golden")
+
+	// The secrets are only redacted for display.
+	assert.Equal(t, "abc", config.GetContext("server-ctx").Context.Token)
+}
+
+func TestCmdList_selector(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdList).Args(
+		"-l", "env=prod,site!=ord",
+		"--show-labels",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.selector.golden")
+}
+
+func TestCmdList_selectorNoMatch(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdList).Args(
+		"-l", "env=staging",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+}
+
+func TestCmdList_invalidSelector(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdList).Args(
+		"-l", "env in (prod)",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("list.invalid-selector.golden")
+}
+
+func TestCmdList_showLabels(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdList).Args(
+		"--show-labels",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.show-labels.golden")
+}
+
+func TestCmdList_selectorYaml(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdList).Args(
+		"-l", "site=ord",
+		"--yaml",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("list.selector-yaml.golden")
+}
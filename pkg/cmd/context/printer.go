@@ -18,16 +18,24 @@ package context
 
 import (
 	"fmt"
+	"reflect"
 
 	"github.com/vapor-ware/synse-cli/pkg/config"
 )
 
+// redactRecord gets a copy of the context record with its secret settings
+// redacted, for display.
+func redactRecord(ctx config.ContextRecord) config.ContextRecord {
+	ctx.Context = ctx.Context.Redacted()
+	return ctx
+}
+
 func contextRowFunc(data interface{}) ([]interface{}, error) {
 	i, ok := data.(config.ContextRecord)
 	if !ok {
 		return nil, fmt.Errorf("invalid row data: %T", data)
 	}
-	if i == (config.ContextRecord{}) {
+	if reflect.DeepEqual(i, config.ContextRecord{}) {
 		return nil, fmt.Errorf("got empty context record")
 	}
 
@@ -43,3 +51,97 @@ func contextRowFunc(data interface{}) ([]interface{}, error) {
 		i.Context.Address,
 	}, nil
 }
+
+func contextListRowFunc(data interface{}) ([]interface{}, error) {
+	i, ok := data.(listRecord)
+	if !ok {
+		return nil, fmt.Errorf("invalid row data: %T", data)
+	}
+
+	row, err := contextRowFunc(i.ContextRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	protected := "no"
+	if i.Protected {
+		protected = "yes"
+	}
+	return append(row, valueOrDash(i.Source), protected), nil
+}
+
+func contextCheckRowFunc(data interface{}) ([]interface{}, error) {
+	row, err := contextListRowFunc(data)
+	if err != nil {
+		return nil, err
+	}
+
+	check := data.(listRecord).Check
+	if check == nil {
+		return nil, fmt.Errorf("context has not been checked")
+	}
+
+	reachable := "no"
+	if check.Reachable {
+		reachable = "yes"
+	}
+	return append(row,
+		reachable,
+		valueOrDash(check.Latency),
+		valueOrDash(check.Version),
+		check.TLS,
+	), nil
+}
+
+// withLabelsRowFunc adds a column with the labels of the context to the
+// rows of a context list row func.
+func withLabelsRowFunc(rowFunc func(data interface{}) ([]interface{}, error)) func(data interface{}) ([]interface{}, error) {
+	return func(data interface{}) ([]interface{}, error) {
+		row, err := rowFunc(data)
+		if err != nil {
+			return nil, err
+		}
+		return append(row, valueOrDash(config.FormatLabels(data.(listRecord).Labels))), nil
+	}
+}
+
+// valueOrDash gets the value, or "-" if it is empty.
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+func discoveredRowFunc(data interface{}) ([]interface{}, error) {
+	i, ok := data.(discovered)
+	if !ok {
+		return nil, fmt.Errorf("invalid row data: %T", data)
+	}
+
+	status := "new"
+	if i.Exists {
+		status = "exists"
+	}
+	return []interface{}{
+		i.Name,
+		i.Type,
+		i.Address,
+		valueOrDash(i.Version),
+		status,
+	}, nil
+}
+
+func syncResultRowFunc(data interface{}) ([]interface{}, error) {
+	i, ok := data.(syncResult)
+	if !ok {
+		return nil, fmt.Errorf("invalid row data: %T", data)
+	}
+
+	return []interface{}{
+		valueOrDash(i.Name),
+		i.Address,
+		valueOrDash(i.Plugin),
+		i.Action,
+	}, nil
+}
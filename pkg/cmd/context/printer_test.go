@@ -73,3 +73,147 @@ func TestContextRowFunc_notCurrent(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, []interface{}{" ", "test", "server", "123"}, res)
 }
+
+func TestContextListRowFunc_err(t *testing.T) {
+	var data config.ContextRecord
+
+	res, err := contextListRowFunc(data)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestContextListRowFunc(t *testing.T) {
+	defer config.Purge()
+
+	var data = listRecord{
+		ContextRecord: config.ContextRecord{
+			Name: "test",
+			Type: "server",
+			Context: config.Context{
+				Address: "123",
+			},
+		},
+		Source: "project",
+	}
+
+	res, err := contextListRowFunc(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{" ", "test", "server", "123", "project", "no"}, res)
+}
+
+func TestContextListRowFunc_protected(t *testing.T) {
+	defer config.Purge()
+
+	var data = listRecord{
+		ContextRecord: config.ContextRecord{
+			Name:      "test",
+			Type:      "server",
+			Protected: true,
+			Context: config.Context{
+				Address: "123",
+			},
+		},
+		Source: "project",
+	}
+
+	res, err := contextListRowFunc(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{" ", "test", "server", "123", "project", "yes"}, res)
+}
+
+func TestContextListRowFunc_noSource(t *testing.T) {
+	defer config.Purge()
+
+	var data = listRecord{
+		ContextRecord: config.ContextRecord{
+			Name: "test",
+			Type: "server",
+			Context: config.Context{
+				Address: "123",
+			},
+		},
+	}
+
+	res, err := contextListRowFunc(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{" ", "test", "server", "123", "-", "no"}, res)
+}
+
+func TestContextCheckRowFunc_notChecked(t *testing.T) {
+	defer config.Purge()
+
+	var data = listRecord{
+		ContextRecord: config.ContextRecord{
+			Name: "test",
+			Type: "server",
+		},
+	}
+
+	res, err := contextCheckRowFunc(data)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestContextCheckRowFunc(t *testing.T) {
+	defer config.Purge()
+
+	var data = listRecord{
+		ContextRecord: config.ContextRecord{
+			Name: "test",
+			Type: "server",
+			Context: config.Context{
+				Address: "123",
+			},
+		},
+		Check: &contextCheck{
+			Reachable: true,
+			Latency:   "5ms",
+			Version:   "3.0.0",
+			TLS:       "off",
+		},
+	}
+
+	res, err := contextCheckRowFunc(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{" ", "test", "server", "123", "-", "no", "yes", "5ms", "3.0.0", "off"}, res)
+}
+
+func TestDiscoveredRowFunc_err(t *testing.T) {
+	var data config.ContextRecord
+
+	res, err := discoveredRowFunc(data)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestDiscoveredRowFunc(t *testing.T) {
+	var data = discovered{
+		Name:    "local",
+		Type:    "server",
+		Address: "localhost:5000",
+		Exists:  true,
+	}
+
+	res, err := discoveredRowFunc(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"local", "server", "localhost:5000", "-", "exists"}, res)
+}
+
+func TestSyncResultRowFunc_err(t *testing.T) {
+	var data config.ContextRecord
+
+	res, err := syncResultRowFunc(data)
+	assert.Error(t, err)
+	assert.Nil(t, res)
+}
+
+func TestSyncResultRowFunc(t *testing.T) {
+	var data = syncResult{
+		Address: "10.0.0.3:5001",
+		Action:  "unregistered",
+	}
+
+	res, err := syncResultRowFunc(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{"-", "10.0.0.3:5001", "-", "unregistered"}, res)
+}
@@ -0,0 +1,53 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+)
+
+var cmdRename = &cobra.Command{
+	Use:   "rename OLD_NAME NEW_NAME",
+	Short: "Rename a context",
+	Long: utils.Doc(`
+		Rename a context record in the synse configuration.
+
+		The context keeps all of its settings and stays in the config file
+		it was loaded from. If it is the current context, it remains the
+		current context under its new name.
+	`),
+	Aliases: []string{
+		"mv",
+	},
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(renameContext(args[0], args[1]))
+	},
+}
+
+func renameContext(name, newName string) error {
+	log.WithFields(log.Fields{
+		"name":     name,
+		"new_name": newName,
+	}).Debug("renaming context")
+
+	return config.RenameContext(name, newName)
+}
@@ -0,0 +1,96 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+func TestCmdRename_notEnoughArgs(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdRename).Args("foo").Run(t)
+	result.AssertErr()
+	result.AssertGolden("rename.not-enough-args.golden")
+}
+
+func TestCmdRename_nonexisting(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdRename).Args("foo", "bar").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("rename.nonexisting.golden")
+
+	assert.Len(t, config.GetContexts(), 0)
+}
+
+func TestCmdRename_nameExists(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	for _, name := range []string{"server-ctx", "other-ctx"} {
+		assert.NoError(t, config.AddContext(&config.ContextRecord{
+			Name:    name,
+			Type:    "server",
+			Context: config.Context{Address: "0.0.0.0"},
+		}))
+	}
+
+	result := test.Cmd(cmdRename).Args("server-ctx", "other-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("rename.name-exists.golden")
+
+	assert.NotNil(t, config.GetContext("server-ctx"))
+	assert.Len(t, config.GetContexts(), 2)
+}
+
+func TestCmdRename_current(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "server-ctx",
+		Type:    "server",
+		Context: config.Context{Address: "0.0.0.0"},
+	}))
+	assert.NoError(t, config.SetCurrentContext("server-ctx"))
+
+	result := test.Cmd(cmdRename).Args("server-ctx", "new-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Len(t, config.GetContexts(), 1)
+	assert.Nil(t, config.GetContext("server-ctx"))
+	assert.Equal(t, "0.0.0.0", config.GetContext("new-ctx").Context.Address)
+	assert.Equal(t, "new-ctx", config.GetCurrentContext()["server"].Name)
+}
@@ -17,7 +17,11 @@
 package context
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vapor-ware/synse-cli/pkg/config"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
 )
 
@@ -26,18 +30,67 @@ import (
 var (
 	flagNoHeader   bool
 	flagSet        bool
-	flagJSON       bool
-	flagYaml       bool
+	flagOutput     utils.Output
+	flagEmbedCerts bool
 	flagClientCert string
+	flagOnConflict string
+	flagCertDir    string
+
+	flagClientKey  string
+	flagCACert     string
+	flagServerName string
+	flagSkipVerify bool
+	flagProxy      string
+	flagProtected  bool
+
+	flagCheck              bool
+	flagIncludeCredentials bool
+
+	flagSelector   string
+	flagShowLabels bool
 )
 
 // resetFlags resets the flag values. This is useful for tests.
 func resetFlags() {
 	flagNoHeader = false
 	flagSet = false
-	flagJSON = false
-	flagYaml = false
+	flagOutput = utils.Output{}
+	flagEmbedCerts = false
 	flagClientCert = ""
+	flagOnConflict = string(config.MergeSkip)
+	flagCertDir = ""
+	flagClientKey = ""
+	flagCACert = ""
+	flagServerName = ""
+	flagSkipVerify = false
+	flagProxy = ""
+	flagProtected = false
+	flagCheck = false
+	flagIncludeCredentials = false
+	flagSelector = ""
+	flagShowLabels = false
+	flagAll = false
+	flagPorts = []int{defaultServerPort, defaultPluginPort}
+	flagTimeout = 2 * time.Second
+	flagYes = false
+	flagPrune = false
+	flagAddress = ""
+	flagDefaultNS = ""
+	flagDefaultOutput = ""
+	flagDefaultNoHeader = false
+	flagDefaultTimeout = 0
+	flagDefaultTags = []string{}
+	flagHeaders = []string{}
+	flagToken = ""
+	flagCredentialHelper = ""
+
+	// The add and update commands only use the settings for the flags
+	// which were set, so their changed state is reset too.
+	for _, cmd := range []*cobra.Command{cmdAdd, cmdUpdate} {
+		cmd.Flags().VisitAll(func(f *pflag.Flag) {
+			f.Changed = false
+		})
+	}
 }
 
 // New returns a new instance of the 'hosts' command.
@@ -54,12 +107,20 @@ func New() *cobra.Command {
 	// Add sub-commands
 	cmd.AddCommand(
 		cmdAdd,
+		cmdCopy,
 		cmdCurrent,
+		cmdDiscover,
 		cmdEdit,
+		cmdExport,
+		cmdImport,
+		cmdLabel,
 		cmdList,
 		cmdRemove,
+		cmdRename,
 		cmdSet,
+		cmdSyncPlugins,
 		cmdUnset,
+		cmdUpdate,
 	)
 
 	return cmd
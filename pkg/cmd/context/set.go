@@ -17,6 +17,8 @@
 package context
 
 import (
+	"errors"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/config"
@@ -24,18 +26,41 @@ import (
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
+func init() {
+	cmdSet.Flags().StringVarP(&flagSelector, "selector", "l", "", "set the context matching a label selector (e.g. env=prod,site=atl) instead of a named context")
+}
+
 var cmdSet = &cobra.Command{
 	Use:   "set CONTEXT_NAME",
 	Short: "Set the current context",
 	Long: utils.Doc(`
 		Set the current active context for the CLI.
+
+		Instead of naming the context, it can be selected by its labels with
+		-l/--selector. The selector must match exactly one context.
 	`),
 	SuggestFor: []string{
 		"change",
 	},
-	Args: cobra.ExactArgs(1),
+	Args: func(cmd *cobra.Command, args []string) error {
+		if flagSelector == "" {
+			return cobra.ExactArgs(1)(cmd, args)
+		}
+		if len(args) != 0 {
+			return errors.New("cannot use --selector with a context name")
+		}
+		return nil
+	},
 	Run: func(cmd *cobra.Command, args []string) {
-		exit.FromCmd(cmd).Err(setContext(args[0]))
+		exiter := exit.FromCmd(cmd)
+
+		if flagSelector == "" {
+			exiter.Err(setContext(args[0]))
+			return
+		}
+		name, err := utils.SelectContext(flagSelector, "")
+		exiter.Err(err)
+		exiter.Err(setContext(name))
 	},
 }
 
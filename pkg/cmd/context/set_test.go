@@ -97,3 +97,71 @@ func TestCmdSet_nonexistingCtx(t *testing.T) {
 	assert.Len(t, config.GetContexts(), 1)
 	assert.Len(t, config.GetCurrentContext(), 0)
 }
+
+func TestCmdSet_selector(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdSet).Args(
+		"-l", "site=atl,env=lab",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Equal(t, "atl-lab", config.GetCurrentContext()["server"].Name)
+	assert.Len(t, config.GetCurrentContext(), 1)
+}
+
+func TestCmdSet_selectorAmbiguous(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdSet).Args(
+		"-l", "site=atl,env=prod",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("set.selector-ambiguous.golden")
+
+	assert.Len(t, config.GetCurrentContext(), 0)
+}
+
+func TestCmdSet_selectorNoMatch(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdSet).Args(
+		"-l", "env=staging",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("set.selector-no-match.golden")
+
+	assert.Len(t, config.GetCurrentContext(), 0)
+}
+
+func TestCmdSet_selectorAndName(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, labeledTestContexts)
+
+	result := test.Cmd(cmdSet).Args(
+		"atl-lab",
+		"-l", "env=lab",
+	).Run(t)
+	result.AssertErr()
+	result.AssertGolden("set.selector-and-name.golden")
+
+	assert.Len(t, config.GetCurrentContext(), 0)
+}
@@ -0,0 +1,302 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+var flagPrune bool
+
+func init() {
+	cmdSyncPlugins.Flags().BoolVarP(&flagPrune, "prune", "", false, "remove plugin contexts synced from the server whose plugin is no longer registered")
+	cmdSyncPlugins.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
+	utils.AddOutputFlags(cmdSyncPlugins.Flags(), &flagOutput)
+}
+
+var cmdSyncPlugins = &cobra.Command{
+	Use:   "sync-plugins [SERVER]",
+	Short: "Create plugin contexts for the plugins registered with a server",
+	Long: utils.Doc(`
+		Create or update plugin contexts for the plugins registered with a
+		Synse Server.
+
+		The plugins are read from the named server context, or from the
+		current server context if no name is given. Each plugin context is
+		named after the server context and the plugin's metadata tag (e.g.
+		"vaporio/emulator-plugin" on the server context "local" becomes
+		"local-vaporio-emulator-plugin"), or its name if it has no tag, so
+		that servers running the same plugins each get their own contexts. If
+		more than one plugin registered with a server has the same tag, the
+		start of the plugin ID is added to the name.
+
+		A plugin context which was synced from the same server before has its
+		address updated to the address the server uses for the plugin; any
+		other settings (e.g. TLS) are kept. Contexts with the generated name
+		which were not synced from the server (e.g. contexts created by hand)
+		are never changed; the plugin is listed as a conflict and skipped. Note that the address
+		is the one the server connects to, which may not be reachable from the
+		host the CLI is run on.
+
+		Plugins which the server connects to over a unix socket get a
+		unix:///path address, which is only usable if the CLI runs on the same
+		host as the server. Plugin addresses which are in the server's
+		configuration but have no registered plugin are listed as
+		unregistered. Plugins with any other protocol are skipped.
+
+		Contexts created by a sync remember the server they were synced from.
+		Those whose plugin is no longer registered are listed as stale, and
+		are removed if the --prune flag is set. Contexts which were not
+		created by a sync are never removed.
+	`),
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exiter := exit.FromCmd(cmd)
+
+		var server string
+		if len(args) != 0 {
+			server = args[0]
+		}
+		exiter.Err(syncPlugins(cmd.OutOrStdout(), server, clients.FromCmd(cmd, utils.NewClientFactory(utils.ClientOptions{
+			Context: server,
+		}))))
+	},
+}
+
+// syncResult describes the outcome of syncing a plugin context.
+type syncResult struct {
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	Address string `json:"address" yaml:"address"`
+	Plugin  string `json:"plugin,omitempty" yaml:"plugin,omitempty"`
+
+	// Action is "added", "updated", "unchanged", "conflict", "unregistered",
+	// "skipped", "stale" or "pruned".
+	Action string `json:"action" yaml:"action"`
+}
+
+func syncPlugins(out io.Writer, server string, factory clients.Factory) error {
+	serverCtx, err := utils.ResolveContext(server, "server")
+	if err != nil {
+		return err
+	}
+
+	client, err := factory.HTTP()
+	if err != nil {
+		return err
+	}
+
+	plugins, err := client.Plugins()
+	if err != nil {
+		return err
+	}
+	var registered []*scheme.Plugin
+	for _, meta := range plugins {
+		plugin, err := client.Plugin(meta.ID)
+		if err != nil {
+			return err
+		}
+		registered = append(registered, plugin)
+	}
+
+	cfg, err := client.Config()
+	if err != nil {
+		return err
+	}
+
+	log.WithFields(log.Fields{
+		"server":  serverCtx.Name,
+		"plugins": len(registered),
+	}).Debug("syncing plugin contexts")
+
+	configured := cfg.Plugin.TCP
+	for _, path := range cfg.Plugin.Unix {
+		configured = append(configured, unixAddress(path))
+	}
+
+	results, err := syncPluginContexts(serverCtx.Name, registered, configured)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		_, err := fmt.Fprintln(out, "no plugins registered with the server")
+		return err
+	}
+
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	printer.SetHeader("NAME", "ADDRESS", "PLUGIN", "ACTION")
+	printer.SetRowFunc(syncResultRowFunc)
+	return printer.Write(results)
+}
+
+// syncPluginContexts creates or updates the plugin contexts for the plugins
+// registered with the named server, pruning stale ones if flagPrune is set.
+// The configured plugin addresses of the server are used to report plugins
+// which are not registered.
+func syncPluginContexts(server string, plugins []*scheme.Plugin, configured []string) ([]syncResult, error) {
+	// Plugins with the same tag are distinguished by their ID.
+	tags := map[string]int{}
+	for _, p := range plugins {
+		tags[pluginContextName(p, false)]++
+	}
+
+	servers := map[string]bool{}
+	for _, ctx := range config.GetContexts() {
+		if ctx.Type != "plugin" {
+			servers[ctx.Name] = true
+		}
+	}
+
+	var results []syncResult
+	synced := map[string]bool{}
+	registered := map[string]bool{}
+	for _, p := range plugins {
+		result := syncResult{
+			Address: p.Network.Address,
+			Plugin:  p.Tag,
+		}
+		if p.Network.Protocol == "unix" {
+			result.Address = unixAddress(p.Network.Address)
+		}
+		registered[result.Address] = true
+
+		if p.Network.Protocol != "tcp" && p.Network.Protocol != "unix" {
+			log.WithFields(log.Fields{
+				"plugin":   p.ID,
+				"protocol": p.Network.Protocol,
+			}).Debug("skipping plugin with unsupported protocol")
+			result.Action = "skipped"
+			results = append(results, result)
+			continue
+		}
+
+		// If a server context has the name, a numeric suffix is added. Only
+		// server contexts are skipped, so the same name is found again by
+		// later syncs.
+		result.Name = server + "-" + pluginContextName(p, tags[pluginContextName(p, false)] > 1)
+		if servers[result.Name] {
+			result.Name = config.UniqueName(result.Name, servers)
+		}
+
+		existing := config.GetContext(result.Name)
+		if existing != nil && existing.SyncedFrom != server {
+			log.WithFields(log.Fields{
+				"plugin":     p.ID,
+				"context":    existing.Name,
+				"syncedFrom": existing.SyncedFrom,
+			}).Debug("skipping plugin whose context name is used by a context not synced from the server")
+			result.Action = "conflict"
+			results = append(results, result)
+			continue
+		}
+		synced[result.Name] = true
+
+		switch {
+		case existing == nil:
+			result.Action = "added"
+			if err := config.AddContext(&config.ContextRecord{
+				Name:       result.Name,
+				Type:       "plugin",
+				SyncedFrom: server,
+				Context: config.Context{
+					Address: result.Address,
+				},
+			}); err != nil {
+				return nil, err
+			}
+		case existing.Context.Address == result.Address:
+			result.Action = "unchanged"
+		default:
+			result.Action = "updated"
+			existing.Context.Address = result.Address
+			if errs := config.ReplaceContexts([]string{existing.Name}, []config.ContextRecord{*existing}); len(errs) != 0 {
+				return nil, errs[0]
+			}
+		}
+		results = append(results, result)
+	}
+
+	for _, address := range configured {
+		if registered[address] {
+			continue
+		}
+		registered[address] = true
+		results = append(results, syncResult{
+			Address: address,
+			Action:  "unregistered",
+		})
+	}
+
+	var stale []syncResult
+	for _, ctx := range config.GetContexts() {
+		if ctx.Type != "plugin" || ctx.SyncedFrom != server || synced[ctx.Name] {
+			continue
+		}
+		stale = append(stale, syncResult{
+			Name:    ctx.Name,
+			Address: ctx.Context.Address,
+			Action:  "stale",
+		})
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Name < stale[j].Name })
+	for i := range stale {
+		if flagPrune {
+			config.RemoveContext(stale[i].Name)
+			stale[i].Action = "pruned"
+		}
+	}
+	return append(results, stale...), nil
+}
+
+// pluginContextName gets the name of the context for a plugin, from its tag
+// or, if it has none, its name. If withID is set, the start of the plugin ID
+// is added to distinguish plugins with the same tag.
+func pluginContextName(p *scheme.Plugin, withID bool) string {
+	name := contextName(p.Tag)
+	if name == "" {
+		name = contextName(p.Name)
+	}
+	if name == "" {
+		name = "plugin"
+	}
+	if withID {
+		id := contextName(p.ID)
+		if len(id) > 8 {
+			id = strings.TrimRight(id[:8], "-")
+		}
+		name += "-" + id
+	}
+	return name
+}
+
+// unixAddress gets the context address for the unix socket path of a plugin.
+func unixAddress(path string) string {
+	if _, ok := config.UnixSocketPath(path); ok {
+		return path
+	}
+	return config.UnixScheme + path
+}
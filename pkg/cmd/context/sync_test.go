@@ -0,0 +1,363 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+// pluginsClient is a fake server client with the given registered plugins
+// and configured TCP and unix plugin addresses.
+type pluginsClient struct {
+	synse.Client
+
+	plugins        []*scheme.Plugin
+	configured     []string
+	configuredUnix []string
+}
+
+func (c *pluginsClient) Plugins() ([]*scheme.PluginMeta, error) {
+	var meta []*scheme.PluginMeta
+	for _, p := range c.plugins {
+		m := p.PluginMeta
+		meta = append(meta, &m)
+	}
+	return meta, nil
+}
+
+func (c *pluginsClient) Plugin(id string) (*scheme.Plugin, error) {
+	for _, p := range c.plugins {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, fmt.Errorf("plugin not found: %s", id)
+}
+
+func (c *pluginsClient) Config() (*scheme.Config, error) {
+	return &scheme.Config{
+		Plugin: scheme.PluginOptions{
+			TCP:  c.configured,
+			Unix: c.configuredUnix,
+		},
+	}, nil
+}
+
+func newPlugin(id, tag, protocol, address string) *scheme.Plugin {
+	return &scheme.Plugin{
+		PluginMeta: scheme.PluginMeta{
+			ID:   id,
+			Name: "plugin " + id,
+			Tag:  tag,
+		},
+		Network: scheme.NetworkOptions{
+			Protocol: protocol,
+			Address:  address,
+		},
+	}
+}
+
+var serverTestContexts = []config.ContextRecord{
+	{
+		Name:    "local",
+		Type:    "server",
+		Context: config.Context{Address: "localhost:5000"},
+	},
+}
+
+func TestCmdSyncPlugins_noServer(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdSyncPlugins).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("sync-plugins.no-server.golden")
+}
+
+func TestCmdSyncPlugins_clientErr(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: test.NewFakeHTTPClientV3Err(),
+	}).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("sync-plugins.client-err.golden")
+}
+
+func TestCmdSyncPlugins_noPlugins(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{Client: test.NewFakeHTTPClientV3()},
+	}).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.no-plugins.golden")
+}
+
+func TestCmdSyncPlugins_add(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("1234", "vaporio/emulator-plugin", "tcp", "emulator:5001"),
+				newPlugin("5678", "", "tcp", "10.0.0.2:5001"),
+				newPlugin("9abc", "vaporio/socket-plugin", "unix", "/tmp/synse/plugin.sock"),
+				newPlugin("def0", "vaporio/other-plugin", "udp", "10.0.0.4:5001"),
+			},
+			configured:     []string{"emulator:5001", "10.0.0.3:5001"},
+			configuredUnix: []string{"/tmp/synse/plugin.sock", "/tmp/synse/other.sock"},
+		},
+	}).Args("local").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.add.golden")
+
+	assert.Len(t, config.GetContexts(), 4)
+	ctx := config.GetContext("local-vaporio-emulator-plugin")
+	assert.Equal(t, "plugin", ctx.Type)
+	assert.Equal(t, "emulator:5001", ctx.Context.Address)
+	assert.Equal(t, "local", ctx.SyncedFrom)
+	ctx = config.GetContext("local-plugin-5678")
+	assert.Equal(t, "10.0.0.2:5001", ctx.Context.Address)
+	assert.Equal(t, "local", ctx.SyncedFrom)
+	ctx = config.GetContext("local-vaporio-socket-plugin")
+	assert.Equal(t, "unix:///tmp/synse/plugin.sock", ctx.Context.Address)
+}
+
+func TestCmdSyncPlugins_updateAndPrune(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+	test.AddContexts(t, []config.ContextRecord{
+		{Name: "local-vaporio-emulator-plugin", Type: "plugin", SyncedFrom: "local", Context: config.Context{Address: "10.0.0.1:5001", ServerName: "emulator.local"}},
+		{Name: "local-vaporio-unchanged", Type: "plugin", SyncedFrom: "local", Context: config.Context{Address: "10.0.0.4:5001"}},
+		{Name: "old-plugin", Type: "plugin", SyncedFrom: "local", Context: config.Context{Address: "10.0.0.5:5001"}},
+		{Name: "other-server-plugin", Type: "plugin", SyncedFrom: "other", Context: config.Context{Address: "10.0.0.6:5001"}},
+		{Name: "manual", Type: "plugin", Context: config.Context{Address: "10.0.0.7:5001"}},
+	}, "old-plugin")
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("1234", "vaporio/emulator-plugin", "tcp", "emulator:5001"),
+				newPlugin("5678", "vaporio/unchanged", "tcp", "10.0.0.4:5001"),
+			},
+		},
+	}).Args("--prune").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.update-and-prune.golden")
+
+	assert.Len(t, config.GetContexts(), 5)
+	assert.Nil(t, config.GetContext("old-plugin"))
+	assert.NotNil(t, config.GetContext("other-server-plugin"))
+	assert.NotNil(t, config.GetContext("manual"))
+	assert.Nil(t, config.GetCurrentContext()["plugin"])
+
+	// Settings other than the address are kept.
+	ctx := config.GetContext("local-vaporio-emulator-plugin")
+	assert.Equal(t, "emulator:5001", ctx.Context.Address)
+	assert.Equal(t, "emulator.local", ctx.Context.ServerName)
+}
+
+func TestCmdSyncPlugins_stale(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:       "old-plugin",
+		Type:       "plugin",
+		SyncedFrom: "local",
+		Context:    config.Context{Address: "10.0.0.5:5001"},
+	}))
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("1234", "vaporio/emulator-plugin", "tcp", "emulator:5001"),
+			},
+		},
+	}).Args("--no-header").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.stale.golden")
+
+	assert.NotNil(t, config.GetContext("old-plugin"))
+}
+
+func TestCmdSyncPlugins_nameConflicts(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "local-vaporio-rack",
+		Type:    "server",
+		Context: config.Context{Address: "10.0.0.1:5000"},
+	}))
+
+	plugins := &pluginsClient{
+		Client: test.NewFakeHTTPClientV3(),
+		plugins: []*scheme.Plugin{
+			newPlugin("a1b2c3d4-e5f6", "vaporio/modbus", "tcp", "10.0.0.2:5001"),
+			newPlugin("f6e5d4c3-b2a1", "vaporio/modbus", "tcp", "10.0.0.3:5001"),
+			newPlugin("1234", "vaporio/rack", "tcp", "10.0.0.4:5001"),
+		},
+	}
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: plugins,
+	}).Args("--json").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.name-conflicts.json.golden")
+
+	assert.Equal(t, "10.0.0.2:5001", config.GetContext("local-vaporio-modbus-a1b2c3d4").Context.Address)
+	assert.Equal(t, "10.0.0.3:5001", config.GetContext("local-vaporio-modbus-f6e5d4c3").Context.Address)
+	assert.Equal(t, "10.0.0.4:5001", config.GetContext("local-vaporio-rack-1").Context.Address)
+
+	// A second sync finds the same contexts.
+	resetFlags()
+	result = test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: plugins,
+	}).Args("--no-header").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.name-conflicts.resync.golden")
+	assert.Len(t, config.GetContexts(), 5)
+}
+
+func TestCmdSyncPlugins_manualContextConflict(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "local-vaporio-emulator-plugin",
+		Type:    "plugin",
+		Context: config.Context{Address: "10.0.0.1:5001"},
+	}))
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("1234", "vaporio/emulator-plugin", "tcp", "emulator:5001"),
+			},
+		},
+	}).Args("--prune").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.manual-conflict.golden")
+
+	// The hand-made context is left as it is.
+	ctx := config.GetContext("local-vaporio-emulator-plugin")
+	assert.Equal(t, "10.0.0.1:5001", ctx.Context.Address)
+	assert.Equal(t, "", ctx.SyncedFrom)
+}
+
+func TestCmdSyncPlugins_serversWithSameTag(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, serverTestContexts, "local")
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "remote",
+		Type:    "server",
+		Context: config.Context{Address: "10.0.0.1:5000"},
+	}))
+
+	result := test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("1234", "vaporio/emulator-plugin", "tcp", "10.0.0.2:5001"),
+			},
+		},
+	}).Args("local").Run(t)
+	result.AssertNoErr()
+
+	// The other server's plugin with the same tag gets its own context, and
+	// the context synced from the first server is neither updated nor pruned.
+	resetFlags()
+	result = test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("5678", "vaporio/emulator-plugin", "tcp", "10.0.0.3:5001"),
+			},
+		},
+	}).Args("remote", "--prune").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("sync-plugins.servers-same-tag.golden")
+
+	ctx := config.GetContext("local-vaporio-emulator-plugin")
+	assert.Equal(t, "10.0.0.2:5001", ctx.Context.Address)
+	assert.Equal(t, "local", ctx.SyncedFrom)
+	ctx = config.GetContext("remote-vaporio-emulator-plugin")
+	assert.Equal(t, "10.0.0.3:5001", ctx.Context.Address)
+	assert.Equal(t, "remote", ctx.SyncedFrom)
+
+	// A resync and prune of the first server keeps the other server's context.
+	resetFlags()
+	result = test.Cmd(cmdSyncPlugins).WithClients(&test.FakeClients{
+		Server: &pluginsClient{
+			Client: test.NewFakeHTTPClientV3(),
+			plugins: []*scheme.Plugin{
+				newPlugin("1234", "vaporio/emulator-plugin", "tcp", "10.0.0.2:5001"),
+			},
+		},
+	}).Args("local", "--prune", "--no-header").Run(t)
+	result.AssertNoErr()
+	assert.Equal(t, "local-vaporio-emulator-plugin   10.0.0.2:5001   vaporio/emulator-plugin   unchanged\n", string(result.Out()))
+	assert.NotNil(t, config.GetContext("remote-vaporio-emulator-plugin"))
+}
+
+func TestPluginContextName(t *testing.T) {
+	assert.Equal(t, "vaporio-emulator-plugin", pluginContextName(newPlugin("1", "vaporio/emulator-plugin", "tcp", ""), false))
+	assert.Equal(t, "plugin-1", pluginContextName(newPlugin("1", "", "tcp", ""), false))
+	assert.Equal(t, "plugin", pluginContextName(&scheme.Plugin{}, false))
+	assert.Equal(t, "vaporio-fake-plugin-123-456", pluginContextName(newPlugin("123-456-789", "vaporio/fake_plugin", "tcp", ""), true))
+}
@@ -0,0 +1,123 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
+)
+
+var flagAddress string
+
+func init() {
+	cmdUpdate.Flags().StringVarP(&flagAddress, "address", "", "", "address of the component")
+	cmdUpdate.Flags().StringVarP(&flagClientCert, "tlscert", "", "", "path to TLS certificate file (e.g. ./synse.pem)")
+	cmdUpdate.Flags().StringVarP(&flagClientKey, "tlskey", "", "", "path to TLS client key file, for use with --tlscert (e.g. ./synse-key.pem)")
+	cmdUpdate.Flags().StringVarP(&flagCACert, "cacert", "", "", "path to CA certificate bundle used to verify the component (e.g. ./ca.pem)")
+	cmdUpdate.Flags().StringVarP(&flagServerName, "server-name", "", "", "server name used to verify the component's certificate")
+	cmdUpdate.Flags().BoolVarP(&flagSkipVerify, "insecure-skip-verify", "", false, "do not verify the component's certificate (insecure)")
+	cmdUpdate.Flags().StringVarP(&flagProxy, "proxy", "", "", "URL of the proxy used to connect to the component (e.g. socks5://bastion:1080)")
+	cmdUpdate.Flags().BoolVarP(&flagProtected, "protected", "", false, "protect the context from writes (see 'synse server write --help')")
+	addAuthFlags(cmdUpdate.Flags())
+	addDefaultsFlags(cmdUpdate.Flags())
+}
+
+var cmdUpdate = &cobra.Command{
+	Use:   "update CONTEXT_NAME",
+	Short: "Update the settings of a context",
+	Long: utils.Doc(`
+		Update the address, TLS settings, proxy, authentication settings,
+		command defaults or protection of an existing context.
+
+		Only the settings for the flags which are given are changed. A setting
+		is cleared by giving it an empty value, e.g. --cacert "" or
+		--default-ns "". Headers given with --header replace all of the
+		existing headers, and --header "" clears them. A protected context is
+		unprotected with --protected=false. The context keeps its name and
+		config file, and remains the current context if it is one.
+
+		The flags have the same meaning as for 'synse context add'.
+	`),
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(updateContext(cmd.Flags(), args[0]))
+	},
+}
+
+func updateContext(flags *pflag.FlagSet, name string) error {
+	ctx := config.GetContext(name)
+	if ctx == nil {
+		return fmt.Errorf("cannot update context '%s': no such context", name)
+	}
+
+	var changed bool
+	settings := ctx.Context
+	for _, f := range []struct {
+		flag  string
+		value *string
+		from  string
+	}{
+		{"address", &settings.Address, flagAddress},
+		{"tlscert", &settings.ClientCert, flagClientCert},
+		{"tlskey", &settings.ClientKey, flagClientKey},
+		{"cacert", &settings.CACert, flagCACert},
+		{"server-name", &settings.ServerName, flagServerName},
+		{"proxy", &settings.Proxy, flagProxy},
+	} {
+		if flags.Changed(f.flag) {
+			*f.value = f.from
+			changed = true
+		}
+	}
+	if flags.Changed("insecure-skip-verify") {
+		settings.InsecureSkipVerify = flagSkipVerify
+		changed = true
+	}
+	authChanged, err := contextAuth(flags, &settings)
+	if err != nil {
+		return err
+	}
+	defaults, defaultsChanged := contextDefaults(flags, ctx.Defaults)
+	protectedChanged := flags.Changed("protected")
+	if !changed && !authChanged && !defaultsChanged && !protectedChanged {
+		return fmt.Errorf("no settings given to update for context '%s'", name)
+	}
+
+	log.WithFields(log.Fields{
+		"name":    name,
+		"address": settings.Address,
+		"tlscert": settings.ClientCert,
+		"tlskey":  settings.ClientKey,
+		"cacert":  settings.CACert,
+	}).Debug("updating context")
+
+	if changed || authChanged || defaultsChanged {
+		if err := config.UpdateContext(name, settings, defaults); err != nil {
+			return err
+		}
+	}
+	if protectedChanged {
+		return config.ProtectContext(name, flagProtected)
+	}
+	return nil
+}
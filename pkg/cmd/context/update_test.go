@@ -0,0 +1,248 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package context
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+var updateTestContexts = []config.ContextRecord{
+	{
+		Name: "server-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address:    "0.0.0.0",
+			ServerName: "synse.local",
+		},
+	},
+}
+
+func TestCmdUpdate_noArgs(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdUpdate).Run(t)
+	result.AssertErr()
+	result.AssertGolden("update.no-args.golden")
+}
+
+func TestCmdUpdate_nonexisting(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	result := test.Cmd(cmdUpdate).Args("foo", "--address", "localhost:5000").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("update.nonexisting.golden")
+}
+
+func TestCmdUpdate_noSettings(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdUpdate).Args("server-ctx").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("update.no-settings.golden")
+}
+
+func TestCmdUpdate_address(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdUpdate).Args("server-ctx", "--address", "10.0.0.1:5000").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	ctx := config.GetContext("server-ctx")
+	assert.Equal(t, "10.0.0.1:5000", ctx.Context.Address)
+	assert.Equal(t, "synse.local", ctx.Context.ServerName)
+	assert.Equal(t, "server-ctx", config.GetCurrentContext()["server"].Name)
+	assert.Len(t, config.GetContexts(), 1)
+}
+
+func TestCmdUpdate_tls(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+	certs := test.NewCerts(t)
+
+	result := test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--tlscert", certs.ClientCert,
+		"--tlskey", certs.ClientKey,
+		"--cacert", certs.CACert,
+		"--server-name", "",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.Equal(t, config.Context{
+		Address:    "0.0.0.0",
+		ClientCert: certs.ClientCert,
+		ClientKey:  certs.ClientKey,
+		CACert:     certs.CACert,
+	}, config.GetContext("server-ctx").Context)
+}
+
+func TestCmdUpdate_keyWithoutCert(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+	certs := test.NewCerts(t)
+
+	result := test.Cmd(cmdUpdate).Args("server-ctx", "--tlskey", certs.ClientKey).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("update.key-without-cert.golden")
+
+	assert.Empty(t, config.GetContext("server-ctx").Context.ClientKey)
+}
+
+func TestCmdUpdate_defaults(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--default-ns", "vapor",
+		"--default-no-header",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	ctx := config.GetContext("server-ctx")
+	assert.Equal(t, &config.Defaults{Namespace: "vapor", NoHeader: true}, ctx.Defaults)
+	assert.Equal(t, "0.0.0.0", ctx.Context.Address)
+
+	// Unset defaults are kept, and clearing all of them removes them.
+	resetFlags()
+	result = test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--default-timeout", "5s",
+	).Run(t)
+	result.AssertNoErr()
+	assert.Equal(t, &config.Defaults{Namespace: "vapor", NoHeader: true, Timeout: "5s"}, config.GetContext("server-ctx").Defaults)
+
+	resetFlags()
+	result = test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--default-ns", "",
+		"--default-no-header=false",
+		"--default-timeout", "0",
+	).Run(t)
+	result.AssertNoErr()
+	assert.Nil(t, config.GetContext("server-ctx").Defaults)
+}
+
+func TestCmdUpdate_auth(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name: "server-ctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "0.0.0.0",
+			Headers: map[string]string{"X-Api-Key": "123"},
+			Token:   "abc",
+		},
+	}))
+
+	result := test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--header", "",
+		"--credential-helper", "synse-credentials",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	ctx := config.GetContext("server-ctx").Context
+	assert.Nil(t, ctx.Headers)
+	assert.Equal(t, "abc", ctx.Token)
+	assert.Equal(t, "synse-credentials", ctx.CredentialHelper)
+}
+
+func TestCmdUpdate_proxy(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--proxy", "http://proxy:3128",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	ctx := config.GetContext("server-ctx").Context
+	assert.Equal(t, "http://proxy:3128", ctx.Proxy)
+	assert.Equal(t, "synse.local", ctx.ServerName)
+}
+
+func TestCmdUpdate_protected(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, updateTestContexts, "server-ctx")
+
+	result := test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--protected",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	ctx := config.GetContext("server-ctx")
+	assert.True(t, ctx.Protected)
+	assert.Equal(t, "synse.local", ctx.Context.ServerName)
+
+	resetFlags()
+	result = test.Cmd(cmdUpdate).Args(
+		"server-ctx",
+		"--protected=false",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("empty.golden")
+
+	assert.False(t, config.GetContext("server-ctx").Protected)
+}
@@ -19,19 +19,20 @@ package plugin
 import (
 	"context"
 	"io"
-	"sort"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdDevices.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdDevices.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdDevices.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdDevices.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdDevices.Flags(), &flagOutput)
+	cmdDevices.Flags().BoolVarP(&flagNoSort, "no-sort", "", false, "print results as they are received, without sorting them")
 	cmdDevices.Flags().StringSliceVarP(&flagTags, "tag", "t", []string{}, "specify tags to use as device selectors")
 }
 
@@ -41,28 +42,38 @@ var cmdDevices = &cobra.Command{
 	Long: utils.Doc(`
 		Display the devices exposed by the plugin.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		Results are sorted once all of them have been received. With --no-sort,
+		they are printed as they are received instead, which uses less memory
+		for large results. Table, CSV, TSV and NDJSON output is then written
+		a row at a time. Results sorted with --sort-by are always held until
+		all of them have been received.
+
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
+
+		The wide table also shows the tags and capabilities of each device.
+
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each device as they are in the JSON
+		output, e.g.:
+
+		   --filter 'tags[*].label == "led"' --sort-by type,id
+
+		See 'synse --help' for the filter expressions.
 
 		The default table view only provides a summary of the data. To see
 		see the data in its entirety, use the JSON or YAML output formats.
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(pluginDevices(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(pluginDevices(cmd.OutOrStdout(), clientFactory(cmd)))
 	},
 }
 
-func pluginDevices(out io.Writer) error {
+func pluginDevices(out io.Writer, factory clients.Factory) error {
 	log.Debug("creating new gRPC client")
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -88,7 +99,17 @@ func pluginDevices(out io.Writer) error {
 		return err
 	}
 
-	var devices []*synse.V3Device
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	printer.SetIntermediateYaml()
+	printer.SetHeader("ID", "ALIAS", "TYPE", "INFO", "PLUGIN")
+	printer.SetRowFunc(pluginDeviceRowFunc)
+	printer.SetWideColumns(pluginDeviceWideColumns...)
+	if !flagNoSort {
+		printer.SetLessFunc(deviceLess)
+	}
+
+	devices := printer.NewStream()
+	defer devices.Flush() // nolint: errcheck
 	for {
 		resp, err := stream.Recv()
 		if err == io.EOF {
@@ -97,20 +118,15 @@ func pluginDevices(out io.Writer) error {
 		if err != nil {
 			return err
 		}
-		devices = append(devices, resp)
+		if err := devices.Write(resp); err != nil {
+			return err
+		}
 	}
 
-	if len(devices) == 0 {
+	if devices.Count() == 0 {
 		log.Debug("no devices reported from plugin")
-		return nil
+	} else {
+		log.WithField("total", devices.Count()).Debug("got devices from plugin")
 	}
-	log.WithField("total", len(devices)).Debug("got devices from plugin")
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
-	printer.SetIntermediateYaml()
-	printer.SetHeader("ID", "ALIAS", "TYPE", "INFO", "PLUGIN")
-	printer.SetRowFunc(pluginDeviceRowFunc)
-
-	sort.Sort(Devices(devices))
-	return printer.Write(devices)
+	return devices.Close()
 }
@@ -19,12 +19,8 @@ package plugin
 import (
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	synse "github.com/vapor-ware/synse-server-grpc/go"
-	"google.golang.org/grpc"
 )
 
 func TestCmdDevices_multipleFormats(t *testing.T) {
@@ -34,57 +30,40 @@ func TestCmdDevices_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("devices.multiple-formats.golden")
 }
 
 func TestCmdDevices_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return nil, nil, errors.New("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdDevices).Run(t)
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Err: errors.New("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdDevices_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdDevices).Run(t)
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdDevices_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdDevices).Run(t)
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("devices.table.golden")
 }
 
 func TestCmdDevices_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdDevices).Args(
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -92,13 +71,9 @@ func TestCmdDevices_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdDevices_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdDevices).Args(
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -106,15 +81,73 @@ func TestCmdDevices_json(t *testing.T) {
 }
 
 func TestCmdDevices_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdDevices).Args(
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("devices.yaml.golden")
 }
+
+func TestCmdDevices_noSort(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--no-sort",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("devices.no-sort.golden")
+}
+
+func TestCmdDevices_ndjson(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"-o", "ndjson",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("devices.ndjson.golden")
+}
+
+func TestCmdDevices_wide(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"-o", "wide",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("devices.wide.golden")
+}
+
+func TestCmdDevices_columns(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--columns", "ID:.id,TAG:{.tags[0].label},METADATA:.metadata.abc",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("devices.columns.golden")
+}
+
+func TestCmdDevices_filter(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--filter", `tags[*].label == "device" && metadata.abc == "def"`,
+		"--no-sort",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("devices.filter.golden")
+}
+
+func TestCmdDevices_filterNoMatch(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdDevices).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--filter", `type != "testdevice"`,
+		"--no-sort",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("devices.filter-no-match.golden")
+}
@@ -19,12 +19,8 @@ package plugin
 import (
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	synse "github.com/vapor-ware/synse-server-grpc/go"
-	"google.golang.org/grpc"
 )
 
 func TestCmdHealth_multipleFormats(t *testing.T) {
@@ -34,57 +30,40 @@ func TestCmdHealth_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("health.multiple-formats.golden")
 }
 
 func TestCmdHealth_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return nil, nil, errors.New("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Run(t)
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Err: errors.New("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdHealth_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Run(t)
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdHealth_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Run(t)
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("health.table.golden")
 }
 
 func TestCmdHealth_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Args(
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -92,13 +71,9 @@ func TestCmdHealth_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdHealth_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Args(
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -106,13 +81,9 @@ func TestCmdHealth_json(t *testing.T) {
 }
 
 func TestCmdHealth_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Args(
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
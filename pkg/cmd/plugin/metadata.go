@@ -23,14 +23,14 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdMetadata.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdMetadata.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdMetadata.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdMetadata.Flags(), &flagOutput)
 }
 
 var cmdMetadata = &cobra.Command{
@@ -39,28 +39,22 @@ var cmdMetadata = &cobra.Command{
 	Long: utils.Doc(`
 		Display the metadata associated with the plugin.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
 		The default table view only provides a summary of the data. To see
 		see the data in its entirety, use the JSON or YAML output formats.
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(pluginMetadata(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(pluginMetadata(cmd.OutOrStdout(), clientFactory(cmd)))
 	},
 }
 
-func pluginMetadata(out io.Writer) error {
+func pluginMetadata(out io.Writer, factory clients.Factory) error {
 	log.Debug("creating new gRPC client")
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -75,7 +69,7 @@ func pluginMetadata(out io.Writer) error {
 		return err
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetIntermediateYaml()
 	printer.SetHeader("ID", "TAG", "DESCRIPTION")
 	printer.SetRowFunc(pluginMetadataRowFunc)
@@ -19,12 +19,8 @@ package plugin
 import (
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	synse "github.com/vapor-ware/synse-server-grpc/go"
-	"google.golang.org/grpc"
 )
 
 func TestCmdMetadata_multipleFormats(t *testing.T) {
@@ -34,57 +30,40 @@ func TestCmdMetadata_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("metadata.multiple-formats.golden")
 }
 
 func TestCmdMetadata_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return nil, nil, errors.New("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdMetadata).Run(t)
+	result := test.Cmd(cmdMetadata).WithClients(&test.FakeClients{Err: errors.New("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdMetadata_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdMetadata).Run(t)
+	result := test.Cmd(cmdMetadata).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdMetadata_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdMetadata).Run(t)
+	result := test.Cmd(cmdMetadata).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("metadata.table.golden")
 }
 
 func TestCmdMetadata_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdMetadata).Args(
+	result := test.Cmd(cmdMetadata).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -92,13 +71,9 @@ func TestCmdMetadata_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdMetadata_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdMetadata).Args(
+	result := test.Cmd(cmdMetadata).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -106,13 +81,9 @@ func TestCmdMetadata_json(t *testing.T) {
 }
 
 func TestCmdMetadata_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdMetadata).Args(
+	result := test.Cmd(cmdMetadata).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
@@ -17,7 +17,10 @@
 package plugin
 
 import (
+	"strings"
+
 	"github.com/pkg/errors"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
@@ -32,6 +35,16 @@ var (
 	ErrNilData = errors.New("row handler got nil data")
 )
 
+// The columns which are added to tables for wide output. Their paths are
+// evaluated against the JSON output of each record.
+var (
+	pluginReadingWideColumns = utils.MustParseColumns("DEVICE_TYPE:.deviceType,CONTEXT:.context")
+	pluginDeviceWideColumns  = append(
+		[]utils.Column{utils.FuncColumn("TAGS", pluginDeviceTags)},
+		utils.MustParseColumns("CAPABILITIES:.capabilities.mode")...,
+	)
+)
+
 func pluginTestRowFunc(data interface{}) ([]interface{}, error) {
 	i, ok := data.(*synse.V3TestStatus)
 	if !ok {
@@ -78,13 +91,6 @@ func pluginReadingRowFunc(data interface{}) ([]interface{}, error) {
 		return nil, ErrNilData
 	}
 
-	// Special casing for reading unit symbol. % is a formatting
-	// directive, so it needs to be escaped as a double percent.
-	symbol := i.Unit.Symbol
-	if symbol == "%" {
-		symbol = "%%"
-	}
-
 	var value interface{}
 	switch i.Value.(type) {
 	case *synse.V3Reading_StringValue:
@@ -110,7 +116,7 @@ func pluginReadingRowFunc(data interface{}) ([]interface{}, error) {
 	return []interface{}{
 		i.Id,
 		value,
-		symbol,
+		i.Unit.Symbol,
 		i.Type,
 		i.Timestamp,
 	}, nil
@@ -200,3 +206,28 @@ func pluginHealthRowFunc(data interface{}) ([]interface{}, error) {
 		len(i.Checks),
 	}, nil
 }
+
+// pluginDeviceTags gets the tags of a device in their string form (e.g.
+// "vapor/type:led"), separated by spaces.
+func pluginDeviceTags(data interface{}) (interface{}, error) {
+	i, ok := data.(*synse.V3Device)
+	if !ok {
+		return nil, ErrInvalidRowData
+	}
+	if i == nil {
+		return nil, ErrNilData
+	}
+
+	var tags []string
+	for _, t := range i.Tags {
+		tag := t.Label
+		if t.Annotation != "" {
+			tag = t.Annotation + ":" + tag
+		}
+		if t.Namespace != "" {
+			tag = t.Namespace + "/" + tag
+		}
+		tags = append(tags, tag)
+	}
+	return strings.Join(tags, " "), nil
+}
@@ -132,7 +132,7 @@ func TestReadingRowFunc_ok(t *testing.T) {
 	assert.Len(t, res, 5)
 	assert.Equal(t, res[0], "123")
 	assert.Equal(t, res[1], float64(34))
-	assert.Equal(t, res[2], "%%")
+	assert.Equal(t, res[2], "%")
 	assert.Equal(t, res[3], "test-type")
 	assert.Equal(t, res[4], "2019-04-22T13:30:00Z")
 }
@@ -277,6 +277,37 @@ func TestDeviceRowFunc_ok(t *testing.T) {
 	assert.Equal(t, res[4], "999-888")
 }
 
+func TestDeviceTags_nil(t *testing.T) {
+	var data *synse.V3Device
+
+	res, err := pluginDeviceTags(data)
+	assert.Error(t, err)
+	assert.Equal(t, ErrNilData, err)
+	assert.Nil(t, res)
+}
+
+func TestDeviceTags_errDataType(t *testing.T) {
+	res, err := pluginDeviceTags("")
+	assert.Error(t, err)
+	assert.Equal(t, ErrInvalidRowData, err)
+	assert.Nil(t, res)
+}
+
+func TestDeviceTags_ok(t *testing.T) {
+	var data = &synse.V3Device{
+		Id: "123-456",
+		Tags: []*synse.V3Tag{
+			{Namespace: "vapor", Annotation: "type", Label: "led"},
+			{Namespace: "default", Label: "foo"},
+			{Annotation: "id", Label: "123-456"},
+		},
+	}
+
+	res, err := pluginDeviceTags(data)
+	assert.NoError(t, err)
+	assert.Equal(t, "vapor/type:led default/foo id:123-456", res)
+}
+
 func TestHealthRowFunc_nil(t *testing.T) {
 	var data *synse.V3Health
 
@@ -19,19 +19,20 @@ package plugin
 import (
 	"context"
 	"io"
-	"sort"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdReadCache.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdReadCache.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdReadCache.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdReadCache.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdReadCache.Flags(), &flagOutput)
+	cmdReadCache.Flags().BoolVarP(&flagNoSort, "no-sort", "", false, "print results as they are received, without sorting them")
 	cmdReadCache.Flags().StringVarP(&flagStart, "start", "s", "", "timestamp specifying the starting bound for windowing")
 	cmdReadCache.Flags().StringVarP(&flagEnd, "end", "e", "", "timestamp specifying the ending bound for windowing")
 }
@@ -50,28 +51,38 @@ var cmdReadCache = &cobra.Command{
 		The start and end bounding timestamps should be specified in FRC3339
 		format. An invalidly formatted timestamp may render the bound ineffective.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		Results are sorted once all of them have been received. With --no-sort,
+		they are printed as they are received instead, which uses less memory
+		for large results. Table, CSV, TSV and NDJSON output is then written
+		a row at a time. Results sorted with --sort-by are always held until
+		all of them have been received.
+
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
+
+		The wide table also shows the device type and context of each reading.
+
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each reading as they are in the JSON
+		output, e.g.:
+
+		   --filter 'type == "temperature" && value > 35' --sort-by timestamp:desc
+
+		See 'synse --help' for the filter expressions.
 
 		The default table view only provides a summary of the data. To see
 		see the data in its entirety, use the JSON or YAML output formats.
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(pluginReadCache(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(pluginReadCache(cmd.OutOrStdout(), clientFactory(cmd)))
 	},
 }
 
-func pluginReadCache(out io.Writer) error {
+func pluginReadCache(out io.Writer, factory clients.Factory) error {
 	log.Debug("creating new gRPC client")
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -92,7 +103,18 @@ func pluginReadCache(out io.Writer) error {
 		return err
 	}
 
-	var readings []*synse.V3Reading
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	printer.SetIntermediateYaml()
+	printer.SetHeader("ID", "VALUE", "UNIT", "TYPE", "TIMESTAMP")
+	printer.SetRowFunc(pluginReadingRowFunc)
+	printer.SetWideColumns(pluginReadingWideColumns...)
+	printer.SetTransformFunc(pluginReadTransformer)
+	if !flagNoSort {
+		printer.SetLessFunc(readingLess)
+	}
+
+	readings := printer.NewStream()
+	defer readings.Flush() // nolint: errcheck
 	for {
 		resp, err := stream.Recv()
 		if err == io.EOF {
@@ -101,20 +123,13 @@ func pluginReadCache(out io.Writer) error {
 		if err != nil {
 			return err
 		}
-		readings = append(readings, resp)
+		if err := readings.Write(resp); err != nil {
+			return err
+		}
 	}
 
-	if len(readings) == 0 {
+	if readings.Count() == 0 {
 		log.Debug("no cached readings reported by plugin")
-		return nil
 	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
-	printer.SetIntermediateYaml()
-	printer.SetHeader("ID", "VALUE", "UNIT", "TYPE", "TIMESTAMP")
-	printer.SetRowFunc(pluginReadingRowFunc)
-	printer.SetTransformFunc(pluginReadTransformer)
-
-	sort.Sort(Readings(readings))
-	return printer.Write(readings)
+	return readings.Close()
 }
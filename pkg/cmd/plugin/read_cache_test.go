@@ -19,12 +19,8 @@ package plugin
 import (
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	synse "github.com/vapor-ware/synse-server-grpc/go"
-	"google.golang.org/grpc"
 )
 
 func TestCmdReadCache_multipleFormats(t *testing.T) {
@@ -34,57 +30,40 @@ func TestCmdReadCache_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("read-cache.multiple-formats.golden")
 }
 
 func TestCmdReadCache_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return nil, nil, errors.New("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Run(t)
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Err: errors.New("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdReadCache_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Run(t)
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdReadCache_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Run(t)
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("read-cache.table.golden")
 }
 
 func TestCmdReadCache_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Args(
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -92,13 +71,9 @@ func TestCmdReadCache_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdReadCache_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Args(
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -106,15 +81,52 @@ func TestCmdReadCache_json(t *testing.T) {
 }
 
 func TestCmdReadCache_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Args(
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("read-cache.yaml.golden")
 }
+
+func TestCmdReadCache_noSortCSV(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--no-sort",
+		"-o", "csv",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read-cache.no-sort-csv.golden")
+}
+
+func TestCmdReadCache_ndjson(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"-o", "ndjson",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read-cache.ndjson.golden")
+}
+
+func TestCmdReadCache_wide(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"-o", "wide",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read-cache.wide.golden")
+}
+
+func TestCmdReadCache_sortBy(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--sort-by", "timestamp:desc,id",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read-cache.sort-by.golden")
+}
@@ -19,12 +19,8 @@ package plugin
 import (
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	synse "github.com/vapor-ware/synse-server-grpc/go"
-	"google.golang.org/grpc"
 )
 
 func TestCmdRead_multipleFormats(t *testing.T) {
@@ -34,57 +30,40 @@ func TestCmdRead_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("read.multiple-formats.golden")
 }
 
 func TestCmdRead_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return nil, nil, errors.New("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Run(t)
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Err: errors.New("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdRead_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Run(t)
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdRead_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Run(t)
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("read.table.golden")
 }
 
 func TestCmdRead_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Args(
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -92,13 +71,9 @@ func TestCmdRead_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdRead_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Args(
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -106,15 +81,42 @@ func TestCmdRead_json(t *testing.T) {
 }
 
 func TestCmdRead_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Args(
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("read.yaml.golden")
 }
+
+func TestCmdRead_noSort(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--no-sort",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.no-sort.golden")
+}
+
+func TestCmdRead_wide(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"-o", "wide",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.wide.golden")
+}
+
+func TestCmdRead_filter(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"--filter", `context.foo == "bar" && value < 30`,
+		"-o", "csv",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.filter.golden")
+}
@@ -17,36 +17,56 @@
 package plugin
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 )
 
 // Define variables which hold values passed in via flags. These are
 // defined here because they are used by multiple commands in the package.
 var (
 	flagNoHeader bool
-	flagJSON     bool
-	flagYaml     bool
+	flagNoSort   bool
+	flagOutput   utils.Output
 	flagWait     bool
 	flagStart    string
 	flagEnd      string
 	flagTags     []string
 
-	flagTLSCert string
-	flagContext string
+	flagTLSCert  string
+	flagContext  string
+	flagSelector string
+	flagTimeout  time.Duration
+
+	flagForceProtected bool
 )
 
 // resetFlags resets the flag values. This is useful for tests.
 func resetFlags() {
 	flagNoHeader = false
-	flagJSON = false
-	flagYaml = false
+	flagNoSort = false
+	flagOutput = utils.Output{}
 	flagWait = false
 	flagStart = ""
 	flagEnd = ""
 	flagTags = []string{}
 	flagTLSCert = ""
 	flagContext = ""
+	flagSelector = ""
+	flagTimeout = 0
+	flagForceProtected = false
+}
+
+// clientFactory gets the factory used to create clients for the command,
+// resolving the context from the --with-context and --tlscert flags.
+func clientFactory(cmd *cobra.Command) clients.Factory {
+	return clients.FromCmd(cmd, utils.NewClientFactory(utils.ClientOptions{
+		Context: flagContext,
+		TLSCert: flagTLSCert,
+		Timeout: flagTimeout,
+	}))
 }
 
 // New returns a new instance of the 'plugin' command.
@@ -65,6 +85,8 @@ func New() *cobra.Command {
 	// Add flag options
 	cmd.PersistentFlags().StringVarP(&flagTLSCert, "tlscert", "", "", "path to TLS certificate file (e.g. ./plugin.pem)")
 	cmd.PersistentFlags().StringVarP(&flagContext, "with-context", "", "", "the name of the plugin context to use")
+	cmd.PersistentFlags().StringVarP(&flagSelector, "selector", "l", "", "select the plugin context to use by its labels (e.g. env=prod,site!=lab); it must match exactly one")
+	cmd.PersistentFlags().DurationVarP(&flagTimeout, "timeout", "", 0, "timeout for requests to the plugin (e.g. 10s); no timeout by default")
 
 	// Add sub-commands
 	cmd.AddCommand(
@@ -73,3 +73,13 @@ func (s Transactions) Less(i, j int) bool {
 func (s Transactions) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
+
+// deviceLess orders devices as Devices does, for printer streams.
+func deviceLess(a, b interface{}) bool {
+	return Devices{a.(*synse.V3Device), b.(*synse.V3Device)}.Less(0, 1)
+}
+
+// readingLess orders readings as Readings does, for printer streams.
+func readingLess(a, b interface{}) bool {
+	return Readings{a.(*synse.V3Reading), b.(*synse.V3Reading)}.Less(0, 1)
+}
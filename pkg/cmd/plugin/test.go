@@ -23,14 +23,14 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdTest.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdTest.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdTest.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdTest.Flags(), &flagOutput)
 }
 
 var cmdTest = &cobra.Command{
@@ -39,28 +39,22 @@ var cmdTest = &cobra.Command{
 	Long: utils.Doc(`
 		Check whether the plugin is reachable and ready.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 	`),
 	Aliases: []string{
 		"status",
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(pluginTest(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(pluginTest(cmd.OutOrStdout(), clientFactory(cmd)))
 	},
 }
 
-func pluginTest(out io.Writer) error {
+func pluginTest(out io.Writer, factory clients.Factory) error {
 	log.Debug("creating new gRPC client")
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -75,7 +69,7 @@ func pluginTest(out io.Writer) error {
 		return err
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetIntermediateYaml()
 	printer.SetHeader("STATUS")
 	printer.SetRowFunc(pluginTestRowFunc)
@@ -24,14 +24,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdTransaction.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdTransaction.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdTransaction.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdTransaction.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdTransaction.Flags(), &flagOutput)
 }
 
 var cmdTransaction = &cobra.Command{
@@ -59,25 +60,27 @@ var cmdTransaction = &cobra.Command{
 		    execute the write. This is a terminal state. Once a transaction
 		    is in this state, it will no longer be updated.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
-	`),
-	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each transaction as they are in the JSON
+		output, e.g.:
 
-		exiter.Err(pluginTransaction(cmd.OutOrStdout(), args))
+		   --filter 'context.action == "color"' --sort-by created:desc
+
+		See 'synse --help' for the filter expressions.
+	`),
+	Run: func(cmd *cobra.Command, args []string) {
+		exit.FromCmd(cmd).Err(pluginTransaction(cmd.OutOrStdout(), clientFactory(cmd), args))
 	},
 }
 
-func pluginTransaction(out io.Writer, transactions []string) error {
+func pluginTransaction(out io.Writer, factory clients.Factory, transactions []string) error {
 	log.Debug("creating new gRPC client")
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -125,7 +128,7 @@ func pluginTransaction(out io.Writer, transactions []string) error {
 		return nil
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetIntermediateYaml()
 	printer.SetHeader("ID", "STATUS", "MESSAGE", "CREATED", "UPDATED")
 	printer.SetRowFunc(pluginTransactionStatusRowFunc)
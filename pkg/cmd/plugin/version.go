@@ -23,14 +23,14 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdVersion.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdVersion.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdVersion.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdVersion.Flags(), &flagOutput)
 }
 
 var cmdVersion = &cobra.Command{
@@ -39,25 +39,19 @@ var cmdVersion = &cobra.Command{
 	Long: utils.Doc(`
 		Display version information for the plugin.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(pluginVersion(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(pluginVersion(cmd.OutOrStdout(), clientFactory(cmd)))
 	},
 }
 
-func pluginVersion(out io.Writer) error {
+func pluginVersion(out io.Writer, factory clients.Factory) error {
 	log.Debug("creating new gRPC client")
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -72,7 +66,7 @@ func pluginVersion(out io.Writer) error {
 		return err
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetIntermediateYaml()
 	printer.SetHeader("VERSION", "SDK", "BUILD DATE", "OS", "ARCH")
 	printer.SetRowFunc(pluginVersionRowFunc)
@@ -23,15 +23,16 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
 )
 
 func init() {
 	cmdWrite.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdWrite.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdWrite.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdWrite.Flags(), &flagOutput)
 	cmdWrite.Flags().BoolVarP(&flagWait, "wait", "w", false, "wait for the write to complete")
+	cmdWrite.Flags().BoolVarP(&flagForceProtected, "force-protected", "", false, "allow the write to a protected context, once its name is typed to confirm")
 }
 
 var cmdWrite = &cobra.Command{
@@ -51,19 +52,19 @@ var cmdWrite = &cobra.Command{
 		will display the final status of the write transaction, indicating error
 		or success.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		Writes to a protected context are refused. To write to one anyway,
+		give the --force-protected flag and type the name of the context when
+		prompted to confirm the write.
+
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 	`),
 	Args: cobra.RangeArgs(2, 3),
 	Run: func(cmd *cobra.Command, args []string) {
 		exiter := exit.FromCmd(cmd)
 
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
 		device := args[0]
 		action := args[1]
 		data := ""
@@ -71,16 +72,18 @@ var cmdWrite = &cobra.Command{
 			data = args[2]
 		}
 
+		exiter.Err(utils.ConfirmWrite(cmd.InOrStdin(), cmd.ErrOrStderr(), flagForceProtected, utils.Targets(flagContext, "plugin")))
+
 		if flagWait {
-			exiter.Err(pluginWriteSync(cmd.OutOrStdout(), device, action, data))
+			exiter.Err(pluginWriteSync(cmd.OutOrStdout(), clientFactory(cmd), device, action, data))
 		} else {
-			exiter.Err(pluginWriteAsync(cmd.OutOrStdout(), device, action, data))
+			exiter.Err(pluginWriteAsync(cmd.OutOrStdout(), clientFactory(cmd), device, action, data))
 		}
 	},
 }
 
-func pluginWriteAsync(out io.Writer, device, action, data string) error {
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+func pluginWriteAsync(out io.Writer, factory clients.Factory, device, action, data string) error {
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -118,7 +121,7 @@ func pluginWriteAsync(out io.Writer, device, action, data string) error {
 		return fmt.Errorf("failed devie write")
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetIntermediateYaml()
 	printer.SetHeader("TRANSACTION", "ACTION", "DATA", "DEVICE")
 	printer.SetRowFunc(pluginTransactionInfoRowFunc)
@@ -127,8 +130,8 @@ func pluginWriteAsync(out io.Writer, device, action, data string) error {
 	return printer.Write(txns)
 }
 
-func pluginWriteSync(out io.Writer, device, action, data string) error {
-	conn, client, err := utils.NewSynseGrpcClient(flagContext, flagTLSCert)
+func pluginWriteSync(out io.Writer, factory clients.Factory, device, action, data string) error {
+	conn, client, err := factory.GRPC()
 	if err != nil {
 		return err
 	}
@@ -166,7 +169,7 @@ func pluginWriteSync(out io.Writer, device, action, data string) error {
 		return fmt.Errorf("failed device write")
 	}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetIntermediateYaml()
 	printer.SetHeader("ID", "STATUS", "MESSAGE", "CREATED", "UPDATED")
 	printer.SetRowFunc(pluginTransactionStatusRowFunc)
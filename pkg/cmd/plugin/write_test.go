@@ -19,12 +19,9 @@ package plugin
 import (
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	synse "github.com/vapor-ware/synse-server-grpc/go"
-	"google.golang.org/grpc"
+	"github.com/vapor-ware/synse-cli/pkg/config"
 )
 
 func TestCmdWrite_extraArgs(t *testing.T) {
@@ -49,19 +46,14 @@ func TestCmdWrite_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("write.multiple-formats.golden")
 }
 
 func TestCmdWrite_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return nil, nil, errors.New("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Err: errors.New("test error message")}).Args(
 		"device",
 		"action",
 	).Run(t)
@@ -71,13 +63,9 @@ func TestCmdWrite_badClient(t *testing.T) {
 }
 
 func TestCmdWrite_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3Err()}).Args(
 		"device",
 		"action",
 	).Run(t)
@@ -87,13 +75,9 @@ func TestCmdWrite_requestError(t *testing.T) {
 }
 
 func TestCmdWriteAsync_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 	).Run(t)
@@ -102,13 +86,9 @@ func TestCmdWriteAsync_table(t *testing.T) {
 }
 
 func TestCmdWriteAsync_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--no-header",
@@ -118,13 +98,9 @@ func TestCmdWriteAsync_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdWriteAsync_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--json",
@@ -134,13 +110,9 @@ func TestCmdWriteAsync_json(t *testing.T) {
 }
 
 func TestCmdWriteAsync_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--yaml",
@@ -150,13 +122,9 @@ func TestCmdWriteAsync_yaml(t *testing.T) {
 }
 
 func TestCmdWriteSync_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--wait",
@@ -166,13 +134,9 @@ func TestCmdWriteSync_table(t *testing.T) {
 }
 
 func TestCmdWriteSync_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--wait",
@@ -183,13 +147,9 @@ func TestCmdWriteSync_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdWriteSync_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--wait",
@@ -200,13 +160,9 @@ func TestCmdWriteSync_json(t *testing.T) {
 }
 
 func TestCmdWriteSync_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseGrpcClient, func(ctx, cert string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-		return test.NewFakeConn(), test.NewFakeGRPCClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
 		"device",
 		"action",
 		"--wait",
@@ -215,3 +171,67 @@ func TestCmdWriteSync_yaml(t *testing.T) {
 	result.AssertNoErr()
 	result.AssertGolden("write-sync.yaml.golden")
 }
+
+// protectedTestContexts are a protected plugin context named "prod".
+var protectedTestContexts = []config.ContextRecord{
+	{
+		Name:      "prod",
+		Type:      "plugin",
+		Protected: true,
+		Context: config.Context{
+			Address: "prod:5001",
+		},
+	},
+}
+
+func TestCmdWrite_protected(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, protectedTestContexts, "prod")
+
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"111-222-333",
+		"foo",
+		"bar",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("write.protected.golden")
+}
+
+func TestCmdWrite_forceProtected(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, protectedTestContexts, "prod")
+
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"111-222-333",
+		"foo",
+		"bar",
+		"--force-protected",
+	).Input("prod\n").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("write.force-protected.golden")
+}
+
+func TestCmdWrite_forceProtectedNoInput(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, protectedTestContexts, "prod")
+
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Plugin: test.NewFakeGRPCClientV3()}).Args(
+		"111-222-333",
+		"foo",
+		"bar",
+		"--force-protected",
+	).Input("").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("write.force-protected-no-input.golden")
+}
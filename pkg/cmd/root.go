@@ -17,8 +17,12 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	cmdconfig "github.com/vapor-ware/synse-cli/pkg/cmd/config"
 	"github.com/vapor-ware/synse-cli/pkg/cmd/context"
 	"github.com/vapor-ware/synse-cli/pkg/cmd/plugin"
 	"github.com/vapor-ware/synse-cli/pkg/cmd/server"
@@ -34,17 +38,33 @@ func init() {
 	log.SetLevel(log.PanicLevel)
 
 	rootCmd.PersistentFlags().BoolVarP(&flagDebug, "debug", "d", false, "enable debug logging")
+	rootCmd.PersistentFlags().StringVarP(&flagConfig, "config", "", "", "path to a CLI config file which takes precedence over the home and project config files (default $SYNSE_CONFIG)")
+}
+
+// addAddressFlags adds the --address and --type flags, which run a command
+// against a component which has no context, to a server or plugin command
+// and its sub-commands.
+func addAddressFlags(cmd *cobra.Command) *cobra.Command {
+	cmd.PersistentFlags().StringVarP(&flagAddress, "address", "", "", "address of a server or plugin to use instead of the current context, without saving it as a context")
+	cmd.PersistentFlags().StringVarP(&flagType, "type", "", "", "type of the component at --address (server, plugin); defaults to the type the command is for")
+	return cmd
 }
 
 var (
-	flagDebug  bool
-	flagSimple bool
+	flagDebug   bool
+	flagSimple  bool
+	flagConfig  string
+	flagAddress string
+	flagType    string
 )
 
 // resetFlags resets the flag values. This is useful for tests.
 func resetFlags() {
 	flagDebug = false
 	flagSimple = false
+	flagConfig = ""
+	flagAddress = ""
+	flagType = ""
 }
 
 // rootCmd is the root command for synse.
@@ -60,6 +80,55 @@ var rootCmd = &cobra.Command{
 		This tool provides simple access to Synse APIs as well as simple
 		management and development utilities for the Synse platform.
 
+		Server and plugin commands can be run against a component which has no
+		context with the --address flag, or the SYNSE_SERVER_ADDRESS and
+		SYNSE_PLUGIN_ADDRESS environment variables. The SYNSE_CONTEXT
+		environment variable names a context to use instead of the current
+		context of its type. These apply to a single invocation, and the CLI
+		configuration is not modified. A context named with --with-context
+		takes precedence over the environment variables, and cannot be used
+		with --address.
+
+		Server and plugin commands can also be run against contexts selected
+		by their labels with -l/--selector (e.g. -l env=prod,site=atl), in
+		place of naming them. Server commands are run against each context
+		which matches (see 'synse server --help'). Plugin commands are run
+		against the context which matches, and the selector must match
+		exactly one. See 'synse context label --help' for setting labels.
+
+		The output of commands which print data is formatted with the
+		-o/--output flag:
+		  table                 a table (the default for most commands)
+		  wide                  a table with additional columns, such as the
+		                        tags, context or capabilities of each item,
+		                        if the command has any
+		  csv, tsv              the table as comma or tab separated values,
+		                        quoted where needed
+		  json, yaml            the data as JSON or YAML
+		  ndjson                newline delimited JSON, with a line for each
+		                        item of the data
+		  jsonpath=TEMPLATE     a JSONPath template, e.g. '{.version}' or
+		                        '{range .[*]}{.id}{"\n"}{end}'
+		  go-template=TEMPLATE  a Go template, e.g. '{{.version}}'
+		  template-file=PATH    a Go template read from a file
+		Templates are given the data as it appears in the JSON output. The
+		--fields flag selects the columns of table, wide, CSV and TSV output
+		by their header names (e.g. --fields ID,VALUE). The --columns flag
+		replaces the columns with custom ones, each a header name and a
+		JSONPath evaluated against the JSON form of each item (e.g.
+		--columns ID:.id,TAGS:.tags[*]).
+
+		Commands which list devices, readings or transactions can filter and
+		sort their results with --filter and --sort-by, using the fields of
+		each item as they are in the JSON output. A filter compares fields
+		with values using ==, !=, <, <=, >, >=, and =~ or !~ for regular
+		expressions, and joins comparisons with &&, || and !, e.g.:
+		  --filter 'type == "temperature" && (value > 35 || unit.symbol == "F")'
+		Fields are written as JSONPath (e.g. context.zone, tags[*]); a field
+		which selects several values matches if any of them do. --sort-by
+		takes one or more fields, each optionally followed by :desc (e.g.
+		--sort-by type,value:desc).
+
 		<underscore>https://github.com/vapor-ware/synse</>
 	`),
 	BashCompletionFunction: bashCompletionFunc,
@@ -69,7 +138,11 @@ var rootCmd = &cobra.Command{
 		}
 
 		// Load CLI config from file prior to running any command.
+		config.SetPath(flagConfig)
 		exit.FromCmd(cmd).Err(config.Load())
+		exit.FromCmd(cmd).Err(setEphemeralContexts(cmd))
+		exit.FromCmd(cmd).Err(selectContext(cmd))
+		exit.FromCmd(cmd).Err(applyContextDefaults(cmd, args))
 
 		log.WithFields(log.Fields{
 			"command": cmd.Name(),
@@ -78,16 +151,153 @@ var rootCmd = &cobra.Command{
 	},
 
 	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		// Commands run against an ephemeral context leave the config
+		// untouched.
+		if utils.HasEphemeralContexts() {
+			log.Debug("not persisting config for ephemeral context")
+			return
+		}
+
 		// Persist the CLI config to file after running any command.
 		exit.FromCmd(cmd).Err(config.Persist())
 	},
 }
 
+// setEphemeralContexts sets the contexts which server and plugin commands
+// are run against in place of the current contexts, from the --address and
+// --type flags and the environment. Other commands manage the configuration
+// itself, so they do not have the flags and the environment is ignored.
+func setEphemeralContexts(cmd *cobra.Command) error {
+	utils.ClearEphemeralContexts()
+
+	if flagType != "" && flagAddress == "" {
+		return errors.New("--type can only be used with --address")
+	}
+
+	cmdType := commandType(cmd)
+	if cmdType == "" {
+		return nil
+	}
+
+	// A named context is used in place of any ephemeral context, so the
+	// address would be ignored.
+	flags := cmd.Flags()
+	if flagAddress != "" && flags.Changed("with-context") {
+		return errors.New("cannot use --address with --with-context")
+	}
+	for _, name := range []string{"contexts", "all-contexts", "context-pattern"} {
+		if flagAddress != "" && flags.Changed(name) {
+			return errors.New("cannot use --address with --contexts, --all-contexts or --context-pattern")
+		}
+	}
+
+	ctxType := flagType
+	if ctxType == "" {
+		ctxType = cmdType
+	}
+	if ctxType != "server" && ctxType != "plugin" {
+		return fmt.Errorf("unsupported context type: %s", ctxType)
+	}
+	if ctxType != cmdType {
+		return fmt.Errorf("cannot use --type %s with %s commands", ctxType, cmdType)
+	}
+	return utils.SetEphemeralContexts(ctxType, flagAddress)
+}
+
+// selectContext checks the -l/--selector label selector of server and
+// plugin commands. Commands which can run against multiple contexts run
+// against each context the selector matches (see utils.Fanout). Otherwise,
+// the --with-context flag is set to the context the selector matches, so
+// that the command runs against it as if it were named; the selector must
+// match exactly one context of the command's type.
+func selectContext(cmd *cobra.Command) error {
+	ctxType := commandType(cmd)
+	flags := cmd.Flags()
+	if ctxType == "" || !flags.Changed("selector") {
+		return nil
+	}
+
+	if flags.Changed("with-context") {
+		return errors.New("cannot use --selector with --with-context")
+	}
+	if flagAddress != "" {
+		return errors.New("cannot use --selector with --address")
+	}
+	for _, name := range []string{"contexts", "all-contexts", "context-pattern"} {
+		if flags.Changed(name) {
+			return errors.New("cannot use --selector with --contexts, --all-contexts or --context-pattern")
+		}
+	}
+	if flags.Lookup("contexts") != nil {
+		return nil
+	}
+
+	name, err := utils.SelectContext(flags.Lookup("selector").Value.String(), ctxType)
+	if err != nil {
+		return err
+	}
+	log.WithFields(log.Fields{
+		"selector": flags.Lookup("selector").Value.String(),
+		"context":  name,
+	}).Debug("selected context by labels")
+	return flags.Set("with-context", name)
+}
+
+// applyContextDefaults sets the flags of server and plugin commands which
+// were not set explicitly to the defaults of the context the command is
+// run against. Defaults are not used for commands run against multiple
+// contexts.
+func applyContextDefaults(cmd *cobra.Command, args []string) error {
+	ctxType := commandType(cmd)
+	if ctxType == "" {
+		return nil
+	}
+
+	flags := cmd.Flags()
+	if flags.Lookup("contexts") != nil && flags.Changed("selector") {
+		return nil
+	}
+	for _, name := range []string{"contexts", "all-contexts", "context-pattern"} {
+		if flags.Changed(name) {
+			return nil
+		}
+	}
+
+	var name string
+	if f := flags.Lookup("with-context"); f != nil {
+		name = f.Value.String()
+	}
+	record, err := utils.ResolveContext(name, ctxType)
+	if err != nil {
+		// The command reports the error when it creates its client.
+		log.WithError(err).Debug("not applying context defaults")
+		return nil
+	}
+	return utils.ApplyContextDefaults(flags, record.Defaults, len(args) != 0 || flags.Changed("id"))
+}
+
+// commandType gets the type of context a command is run against, from its
+// command group (e.g. 'synse server ...'). It is empty for commands which
+// are not run against a server or plugin.
+func commandType(cmd *cobra.Command) string {
+	var group string
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if !c.Parent().HasParent() {
+			group = c.Name()
+		}
+	}
+	if group != "server" && group != "plugin" {
+		return ""
+	}
+	return group
+}
+
 func init() {
 	rootCmd.AddCommand(
+		cmdconfig.New(),
 		context.New(),
-		plugin.New(),
-		server.New(),
+		addAddressFlags(plugin.New()),
+		addAddressFlags(server.New()),
 		template.New(),
 
 		cmdCompletion,
@@ -0,0 +1,273 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
+)
+
+func TestSetEphemeralContexts(t *testing.T) {
+	tests := []struct {
+		desc    string
+		command string
+		address string
+		ctxType string
+		env     string
+		err     string
+		set     bool
+	}{
+		{desc: "server address", command: "server status", address: "localhost:5000", set: true},
+		{desc: "plugin address", command: "plugin devices", address: "localhost:5001", set: true},
+		{desc: "matching type", command: "plugin devices", address: "localhost:5001", ctxType: "plugin", set: true},
+		{desc: "no address", command: "server status"},
+		{desc: "env", command: "server status", env: "localhost:5000", set: true},
+		{desc: "env ignored", command: "context list", env: "localhost:5000"},
+		{desc: "type without address", command: "server status", ctxType: "server", err: "--type can only be used with --address"},
+		{desc: "unsupported type", command: "server status", address: "localhost:5000", ctxType: "other", err: "unsupported context type: other"},
+		{desc: "conflicting type", command: "server status", address: "localhost:5001", ctxType: "plugin", err: "cannot use --type plugin with server commands"},
+		{desc: "with context", command: "server status --with-context local", address: "localhost:5000", err: "cannot use --address with --with-context"},
+		{desc: "multiple contexts", command: "server status --all-contexts", address: "localhost:5000", err: "cannot use --address with --contexts, --all-contexts or --context-pattern"},
+		{desc: "with context from env", command: "server status --with-context local", env: "localhost:5000", set: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			defer func() {
+				resetFlags()
+				utils.ClearEphemeralContexts()
+			}()
+			t.Setenv(utils.EnvServerAddress, tt.env)
+
+			cmd, rest, err := rootCmd.Find(strings.Fields(tt.command))
+			assert.NoError(t, err)
+			defer resetCommandFlags(t, cmd)
+			assert.NoError(t, cmd.ParseFlags(rest))
+
+			flagAddress = tt.address
+			flagType = tt.ctxType
+			err = setEphemeralContexts(cmd)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.set, utils.HasEphemeralContexts())
+		})
+	}
+}
+
+func TestAddressFlags(t *testing.T) {
+	tests := []struct {
+		command string
+		flags   bool
+	}{
+		{command: "server status", flags: true},
+		{command: "server plugins list", flags: true},
+		{command: "plugin devices", flags: true},
+		{command: "config path"},
+		{command: "context list"},
+		{command: "completion"},
+		{command: "version"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			cmd, _, err := rootCmd.Find(strings.Fields(tt.command))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.flags, cmd.InheritedFlags().Lookup("address") != nil)
+			assert.Equal(t, tt.flags, cmd.InheritedFlags().Lookup("type") != nil)
+		})
+	}
+}
+
+// resetCommandFlags resets the flags of a command to their default values.
+func resetCommandFlags(t *testing.T, cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			assert.NoError(t, v.Replace([]string{}))
+		} else if v, ok := f.Value.(*utils.Output); ok {
+			*v = utils.Output{}
+		} else if f.Name != "json" && f.Name != "yaml" {
+			// The --json and --yaml aliases are reset along with --output.
+			assert.NoError(t, f.Value.Set(f.DefValue))
+		}
+		f.Changed = false
+	})
+}
+
+func TestApplyContextDefaults(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, []config.ContextRecord{
+		{Name: "local", Type: "server", Context: config.Context{Address: "localhost:5000"}, Defaults: &config.Defaults{
+			Namespace: "vapor",
+			Output:    "json",
+			Timeout:   "10s",
+			Tags:      []string{"type:temperature"},
+		}},
+		{Name: "remote", Type: "server", Context: config.Context{Address: "10.0.0.1:5000"}, Defaults: &config.Defaults{
+			Output:   "yaml",
+			NoHeader: true,
+		}},
+		{Name: "emulator", Type: "plugin", Context: config.Context{Address: "localhost:5001"}, Defaults: &config.Defaults{
+			Output: "yaml",
+			Tags:   []string{"type:led"},
+		}},
+	}, "local", "emulator")
+
+	tests := []struct {
+		desc     string
+		command  string
+		expected map[string]string
+	}{
+		{
+			desc:    "current server context",
+			command: "server scan",
+			expected: map[string]string{
+				"ns": "vapor", "json": "true", "yaml": "false", "no-header": "false", "timeout": "10s", "tag": "[type:temperature]",
+			},
+		},
+		{
+			desc:    "explicit flags",
+			command: "server scan --ns other --yaml --tag type:led",
+			expected: map[string]string{
+				"ns": "other", "json": "false", "yaml": "true", "timeout": "10s", "tag": "[type:led]",
+			},
+		},
+		{
+			desc:    "devices",
+			command: "server read 1234",
+			expected: map[string]string{
+				"ns": "vapor", "json": "true", "tag": "[]",
+			},
+		},
+		{
+			desc:    "named context",
+			command: "server status --with-context remote",
+			expected: map[string]string{
+				"json": "false", "yaml": "true", "no-header": "true", "timeout": "0s",
+			},
+		},
+		{
+			desc:    "multiple contexts",
+			command: "server status --all-contexts",
+			expected: map[string]string{
+				"json": "false", "yaml": "false", "timeout": "0s",
+			},
+		},
+		{
+			desc:    "current plugin context",
+			command: "plugin devices",
+			expected: map[string]string{
+				"json": "false", "yaml": "true", "tag": "[type:led]",
+			},
+		},
+		{
+			desc:    "unknown context",
+			command: "server status --with-context missing",
+			expected: map[string]string{
+				"json": "false", "yaml": "false", "timeout": "0s",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			args := strings.Fields(tt.command)
+			cmd, rest, err := rootCmd.Find(args)
+			assert.NoError(t, err)
+			defer resetCommandFlags(t, cmd)
+
+			assert.NoError(t, cmd.ParseFlags(rest))
+			assert.NoError(t, applyContextDefaults(cmd, cmd.Flags().Args()))
+			for name, value := range tt.expected {
+				assert.Equal(t, value, cmd.Flags().Lookup(name).Value.String(), name)
+			}
+		})
+	}
+}
+
+func TestSelectContext(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, []config.ContextRecord{
+		{Name: "atl-prod", Type: "server", Context: config.Context{Address: "10.1.0.5:5000"}, Labels: map[string]string{"env": "prod", "site": "atl"}},
+		{Name: "ord-prod", Type: "server", Context: config.Context{Address: "10.2.0.5:5000"}, Labels: map[string]string{"env": "prod", "site": "ord"}},
+		{Name: "atl-emulator", Type: "plugin", Context: config.Context{Address: "10.1.0.6:5001"}, Labels: map[string]string{"env": "prod", "site": "atl"}},
+		{Name: "ord-emulator", Type: "plugin", Context: config.Context{Address: "10.2.0.6:5001"}, Labels: map[string]string{"env": "prod", "site": "ord"}},
+	})
+
+	tests := []struct {
+		desc    string
+		command string
+		context string
+		err     string
+	}{
+		// Server commands run against each of the selected contexts, so no
+		// single context is set.
+		{desc: "server", command: "server status -l env=prod"},
+		{desc: "server plugins", command: "server plugins list --selector site=ord"},
+		{desc: "plugin", command: "plugin devices -l site=atl", context: "atl-emulator"},
+		{desc: "no selector", command: "server status"},
+		{desc: "context command", command: "context list -l site=atl"},
+		{desc: "ambiguous", command: "plugin devices -l env=prod", err: "selector 'env=prod' matches 2 plugin contexts (atl-emulator, ord-emulator); it must match exactly one"},
+		{desc: "no match", command: "plugin devices -l site=lab", err: "no plugin context matches selector 'site=lab'"},
+		{desc: "with context", command: "server status -l site=atl --with-context ord-prod", context: "ord-prod", err: "cannot use --selector with --with-context"},
+		{desc: "multiple contexts", command: "server status -l site=atl --all-contexts", err: "cannot use --selector with --contexts, --all-contexts or --context-pattern"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			args := strings.Fields(tt.command)
+			cmd, rest, err := rootCmd.Find(args)
+			assert.NoError(t, err)
+			defer resetCommandFlags(t, cmd)
+
+			assert.NoError(t, cmd.ParseFlags(rest))
+			err = selectContext(cmd)
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			} else {
+				assert.NoError(t, err)
+			}
+			if f := cmd.Flags().Lookup("with-context"); f != nil {
+				assert.Equal(t, tt.context, f.Value.String())
+			}
+		})
+	}
+}
+
+func TestSelectContext_address(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+
+	cmd, rest, err := rootCmd.Find([]string{"server", "status", "-l", "env=prod"})
+	assert.NoError(t, err)
+	defer resetCommandFlags(t, cmd)
+	assert.NoError(t, cmd.ParseFlags(rest))
+
+	flagAddress = "localhost:5000"
+	assert.EqualError(t, selectContext(cmd), "cannot use --selector with --address")
+}
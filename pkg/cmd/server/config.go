@@ -22,11 +22,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
 func init() {
-	cmdConfig.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdConfig.Flags(), &flagOutput)
+
+	// The output of this command is JSON unless another format is set.
+	cmdConfig.Flags().Lookup("output").DefValue = utils.OutputJSON
 }
 
 var cmdConfig = &cobra.Command{
@@ -35,30 +39,29 @@ var cmdConfig = &cobra.Command{
 	Long: utils.Doc(`
 		Display the application configuration for the Synse Server instance.
 
-		The output of this command can be formatted as JSON (default) or as
-		YAML.
+		The output of this command is JSON by default. It can be formatted
+		with -o/--output as YAML, or with a JSONPath or Go template. See
+		'synse --help' for the output formats.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#config</>
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exit.FromCmd(cmd).Err(serverConfig(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(serverConfig(cmd.OutOrStdout(), fanout(cmd)))
 	},
 }
 
-func serverConfig(out io.Writer) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
+func serverConfig(out io.Writer, run *utils.Fanout) error {
+	printer := utils.NewPrinter(out, flagOutput.WithDefault(utils.OutputJSON), flagNoHeader)
 
-	log.Debug("issuing HTTP config request")
-	response, err := client.Config()
-	if err != nil {
-		return err
-	}
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
 
-	printer := utils.NewPrinter(out, !flagYaml, flagYaml, flagNoHeader)
-	return printer.Write(response)
+		log.Debug("issuing HTTP config request")
+		return client.Config()
+	})
 }
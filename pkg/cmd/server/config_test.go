@@ -20,58 +20,39 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdConfig_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdConfig).Run(t)
+	result := test.Cmd(cmdConfig).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdConfig_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdConfig).Run(t)
+	result := test.Cmd(cmdConfig).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdConfig_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdConfig).Run(t)
+	result := test.Cmd(cmdConfig).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("config.json.golden")
 }
 
 func TestCmdConfig_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdConfig).Args(
+	result := test.Cmd(cmdConfig).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
@@ -22,11 +22,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
 func init() {
-	cmdInfo.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdInfo.Flags(), &flagOutput)
+
+	// The output of this command is JSON unless another format is set.
+	cmdInfo.Flags().Lookup("output").DefValue = utils.OutputJSON
 }
 
 var cmdInfo = &cobra.Command{
@@ -39,31 +43,30 @@ var cmdInfo = &cobra.Command{
 		including its metadata, tags, read-write capabilities, and supported
 		outputs.
 
-		The output of this command can be formatted as JSON (default) or as
-		YAML.
+		The output of this command is JSON by default. It can be formatted
+		with -o/--output as YAML, or with a JSONPath or Go template. See
+		'synse --help' for the output formats.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#info</>
 	`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		exit.FromCmd(cmd).Err(serverInfo(cmd.OutOrStdout(), args[0]))
+		exit.FromCmd(cmd).Err(serverInfo(cmd.OutOrStdout(), fanout(cmd), args[0]))
 	},
 }
 
-func serverInfo(out io.Writer, device string) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
+func serverInfo(out io.Writer, run *utils.Fanout, device string) error {
+	printer := utils.NewPrinter(out, flagOutput.WithDefault(utils.OutputJSON), flagNoHeader)
 
-	log.WithField("device", device).Debug("issuing HTTP device info request")
-	response, err := client.Info(device)
-	if err != nil {
-		return err
-	}
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
 
-	printer := utils.NewPrinter(out, !flagYaml, flagYaml, flagNoHeader)
-	return printer.Write(response)
+		log.WithField("device", device).Debug("issuing HTTP device info request")
+		return client.Info(device)
+	})
 }
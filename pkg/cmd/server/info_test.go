@@ -20,10 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdInfo_extraArgs(t *testing.T) {
@@ -39,53 +36,49 @@ func TestCmdInfo_extraArgs(t *testing.T) {
 }
 
 func TestCmdInfo_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args("111-222-333").Run(t)
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Args("111-222-333").Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdInfo_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args("111-222-333").Run(t)
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Args("111-222-333").Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdInfo_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args("111-222-333").Run(t)
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args("111-222-333").Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("info.json.golden")
 }
 
 func TestCmdInfo_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args("111-222-333").Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args("111-222-333").Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("info.yaml.golden")
 }
+
+func TestCmdInfo_columns(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"111-222-333",
+		"-o", "table",
+		"--columns", "ID:.id,MODE:.capabilities.mode,ACTIONS:.capabilities.write.actions[*]",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("info.columns.golden")
+}
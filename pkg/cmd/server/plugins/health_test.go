@@ -20,10 +20,9 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-cli/pkg/config"
 )
 
 func TestCmdHealth_multipleFormats(t *testing.T) {
@@ -33,57 +32,40 @@ func TestCmdHealth_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("health.multiple-formats.golden")
 }
 
 func TestCmdHealth_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Run(t)
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdHealth_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Run(t)
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdHealth_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Run(t)
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("health.table.golden")
 }
 
 func TestCmdHealth_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Args(
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -91,13 +73,9 @@ func TestCmdHealth_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdHealth_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Args(
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -105,15 +83,35 @@ func TestCmdHealth_json(t *testing.T) {
 }
 
 func TestCmdHealth_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdHealth).Args(
+	result := test.Cmd(cmdHealth).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("health.yaml.golden")
 }
+
+func TestCmdHealth_contexts(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	for _, name := range []string{"site-a", "site-b"} {
+		err := config.AddContext(&config.ContextRecord{
+			Name:    name,
+			Type:    "server",
+			Context: config.Context{Address: name + ":5000"},
+		})
+		assert.NoError(t, err)
+	}
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdHealth)).WithRoot("plugins").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"--all-contexts",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("health.contexts.table.golden")
+}
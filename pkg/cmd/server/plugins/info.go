@@ -22,13 +22,16 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
 func init() {
 	cmdInfo.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdInfo.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdInfo.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdInfo.Flags(), &flagOutput)
+
+	// The output of this command is JSON unless another format is set.
+	cmdInfo.Flags().Lookup("output").DefValue = utils.OutputJSON
 }
 
 var cmdInfo = &cobra.Command{
@@ -38,9 +41,10 @@ var cmdInfo = &cobra.Command{
 		Display information about a plugin registered with the Synse Server
 		instance.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
 		The default table view only provides a summary of the data. To see
 		see the data in its entirety, use the JSON or YAML output formats.
@@ -50,33 +54,23 @@ var cmdInfo = &cobra.Command{
 	`),
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverPluginInfo(cmd.OutOrStdout(), args[0]))
+		exit.FromCmd(cmd).Err(serverPluginInfo(cmd.OutOrStdout(), fanout(cmd), args[0]))
 	},
 }
 
-func serverPluginInfo(out io.Writer, plugin string) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	log.WithField("plugin", plugin).Debug("issuing HTTP plugin info request")
-	response, err := client.Plugin(plugin)
-	if err != nil {
-		return err
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverPluginInfo(out io.Writer, run *utils.Fanout, plugin string) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("ACTIVE", "ID", "TAG", "ADDRESS", "STATUS", "LAST_CHECK")
 	printer.SetRowFunc(serverPluginRowFunc)
 
-	return printer.Write(response)
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithField("plugin", plugin).Debug("issuing HTTP plugin info request")
+		return client.Plugin(plugin)
+	})
 }
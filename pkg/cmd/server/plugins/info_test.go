@@ -20,10 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdInfo_extraArgs(t *testing.T) {
@@ -46,19 +43,14 @@ func TestCmdInfo_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("info.multiple-formats.golden")
 }
 
 func TestCmdInfo_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Args(
 		"111-222-333",
 	).Run(t)
 	result.AssertNoErr()
@@ -67,13 +59,9 @@ func TestCmdInfo_badClient(t *testing.T) {
 }
 
 func TestCmdInfo_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Args(
 		"111-222-333",
 	).Run(t)
 	result.AssertNoErr()
@@ -82,13 +70,9 @@ func TestCmdInfo_requestError(t *testing.T) {
 }
 
 func TestCmdInfo_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 	).Run(t)
 	result.AssertNoErr()
@@ -96,13 +80,9 @@ func TestCmdInfo_table(t *testing.T) {
 }
 
 func TestCmdInfo_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"--no-header",
 	).Run(t)
@@ -111,13 +91,9 @@ func TestCmdInfo_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdInfo_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"--json",
 	).Run(t)
@@ -126,13 +102,9 @@ func TestCmdInfo_json(t *testing.T) {
 }
 
 func TestCmdInfo_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdInfo).Args(
+	result := test.Cmd(cmdInfo).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"--yaml",
 	).Run(t)
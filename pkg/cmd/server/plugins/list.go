@@ -23,13 +23,13 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
 func init() {
 	cmdList.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdList.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdList.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdList.Flags(), &flagOutput)
 }
 
 var cmdList = &cobra.Command{
@@ -38,47 +38,43 @@ var cmdList = &cobra.Command{
 	Long: utils.Doc(`
 		List all plugins registered with the Synse Server instance.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#plugins</>
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverPluginList(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(serverPluginList(cmd.OutOrStdout(), fanout(cmd)))
 	},
 }
 
-func serverPluginList(out io.Writer) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
+func serverPluginList(out io.Writer, run *utils.Fanout) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	printer.SetHeader("ACTIVE", "ID", "VERSION", "TAG", "DESCRIPTION")
+	printer.SetRowFunc(serverPluginSummaryRowFunc)
 
-	log.Debug("issuing HTTP plugins request")
-	response, err := client.Plugins()
-	if err != nil {
-		return err
-	}
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
 
-	if len(response) == 0 {
-		log.Debug("no plugins reported from server")
-		return nil
-	}
+		log.Debug("issuing HTTP plugins request")
+		response, err := client.Plugins()
+		if err != nil {
+			return nil, err
+		}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
-	printer.SetHeader("ACTIVE", "ID", "VERSION", "TAG", "DESCRIPTION")
-	printer.SetRowFunc(serverPluginSummaryRowFunc)
+		if len(response) == 0 {
+			log.Debug("no plugins reported from server")
+			return nil, nil
+		}
 
-	sort.Sort(PluginSummaries(response))
-	return printer.Write(response)
+		sort.Sort(PluginSummaries(response))
+		return response, nil
+	})
 }
@@ -20,10 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdList_multipleFormats(t *testing.T) {
@@ -33,57 +30,40 @@ func TestCmdList_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("list.multiple-formats.golden")
 }
 
 func TestCmdList_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdList).Run(t)
+	result := test.Cmd(cmdList).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdList_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdList).Run(t)
+	result := test.Cmd(cmdList).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdList_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdList).Run(t)
+	result := test.Cmd(cmdList).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("list.table.golden")
 }
 
 func TestCmdList_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdList).Args(
+	result := test.Cmd(cmdList).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -91,13 +71,9 @@ func TestCmdList_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdList_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdList).Args(
+	result := test.Cmd(cmdList).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -105,13 +81,9 @@ func TestCmdList_json(t *testing.T) {
 }
 
 func TestCmdList_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdList).Args(
+	result := test.Cmd(cmdList).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
@@ -17,28 +17,77 @@
 package plugins
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 )
 
 // Define variables which hold values passed in via flags. These are
 // defined here because they are used by multiple commands in the package.
 var (
 	flagNoHeader bool
-	flagJSON     bool
-	flagYaml     bool
+	flagOutput   utils.Output
+
+	flagTLSCert  string
+	flagContext  string
+	flagSelector string
+	flagTimeout  time.Duration
 
-	flagTLSCert string
-	flagContext string
+	flagContexts       []string
+	flagAllContexts    bool
+	flagContextPattern string
 )
 
 // resetFlags resets the flag values. This is useful for tests.
 func resetFlags() {
 	flagNoHeader = false
-	flagJSON = false
-	flagYaml = false
+	flagOutput = utils.Output{}
 	flagTLSCert = ""
 	flagContext = ""
+	flagSelector = ""
+	flagTimeout = 0
+	flagContexts = []string{}
+	flagAllContexts = false
+	flagContextPattern = ""
+}
+
+// clientFactory gets the factory used to create clients for the command,
+// resolving the context from the --with-context and --tlscert flags.
+func clientFactory(cmd *cobra.Command) clients.Factory {
+	return clients.FromCmd(cmd, utils.NewClientFactory(utils.ClientOptions{
+		Context: flagContext,
+		TLSCert: flagTLSCert,
+		Timeout: flagTimeout,
+	}))
+}
+
+// fanout gets the utils.Fanout which runs the command against the server
+// contexts selected by the --contexts, --all-contexts, --context-pattern and
+// -l/--selector flags, or against a single context if none are set.
+func fanout(cmd *cobra.Command) *utils.Fanout {
+	return &utils.Fanout{
+		Selection: utils.ContextSelection{
+			Names:    flagContexts,
+			All:      flagAllContexts,
+			Pattern:  flagContextPattern,
+			Selector: flagSelector,
+		},
+		Type:    "server",
+		Context: flagContext,
+		Factory: func(name string) clients.Factory {
+			if name == "" {
+				return clientFactory(cmd)
+			}
+			return clients.ForContext(cmd, name, utils.NewClientFactory(utils.ClientOptions{
+				Context: name,
+				TLSCert: flagTLSCert,
+				Timeout: flagTimeout,
+			}))
+		},
+		Err: cmd.ErrOrStderr(),
+	}
 }
 
 // New returns a new instance of the 'server plugin' command.
@@ -55,6 +104,11 @@ func New() *cobra.Command {
 	// Add flag options
 	cmd.PersistentFlags().StringVarP(&flagTLSCert, "tlscert", "", "", "path to TLS certificate file (e.g. ./server.pem)")
 	cmd.PersistentFlags().StringVarP(&flagContext, "with-context", "", "", "the name of the plugin context to use")
+	cmd.PersistentFlags().StringVarP(&flagSelector, "selector", "l", "", "run against the server contexts with labels matching a selector (e.g. env=prod,site!=lab)")
+	cmd.PersistentFlags().DurationVarP(&flagTimeout, "timeout", "", 0, "timeout for requests to the server (e.g. 10s; default 2s)")
+	cmd.PersistentFlags().StringSliceVarP(&flagContexts, "contexts", "", []string{}, "run against each of the named server contexts")
+	cmd.PersistentFlags().BoolVarP(&flagAllContexts, "all-contexts", "", false, "run against all server contexts")
+	cmd.PersistentFlags().StringVarP(&flagContextPattern, "context-pattern", "", "", "run against the server contexts with names matching a glob pattern (e.g. 'site-*')")
 
 	// Add sub-commands
 	cmd.AddCommand(
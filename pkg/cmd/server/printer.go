@@ -18,6 +18,7 @@ package server
 
 import (
 	"github.com/pkg/errors"
+	"github.com/vapor-ware/synse-cli/pkg/utils"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
@@ -32,6 +33,13 @@ var (
 	ErrNilData = errors.New("row handler got nil data")
 )
 
+// The columns which are added to tables for wide output. Their paths are
+// evaluated against the JSON output of each record.
+var (
+	serverReadWideColumns = utils.MustParseColumns("DEVICE_TYPE:.device_type,CONTEXT:.context")
+	serverScanWideColumns = utils.MustParseColumns("ALIAS:.alias,PLUGIN:.plugin,TAGS:.tags[*]")
+)
+
 func serverReadRowFunc(data interface{}) ([]interface{}, error) {
 	i, ok := data.(*scheme.Read)
 	if !ok {
@@ -41,17 +49,10 @@ func serverReadRowFunc(data interface{}) ([]interface{}, error) {
 		return nil, ErrNilData
 	}
 
-	// Special casing for reading unit symbol. % is a formatting
-	// directive, so it needs to be escaped as a double percent.
-	symbol := i.Unit.Symbol
-	if symbol == "%" {
-		symbol = "%%"
-	}
-
 	return []interface{}{
 		i.Device,
 		i.Value,
-		symbol,
+		i.Unit.Symbol,
 		i.Type,
 		i.Timestamp,
 	}, nil
@@ -56,7 +56,7 @@ func TestReadRowFunc(t *testing.T) {
 	assert.Len(t, res, 5)
 	assert.Equal(t, res[0], "123")
 	assert.Equal(t, res[1], 23)
-	assert.Equal(t, res[2], "%%")
+	assert.Equal(t, res[2], "%")
 	assert.Equal(t, res[3], "foo")
 	assert.Equal(t, res[4], "2019-04-22T13:30:00Z")
 }
@@ -23,14 +23,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
 func init() {
 	cmdRead.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdRead.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdRead.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdRead.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdRead.Flags(), &flagOutput)
 	cmdRead.Flags().StringVarP(&flagNS, "ns", "", "", "default tag namespace for tags with no explicit namespace set")
 	cmdRead.Flags().StringSliceVarP(&flagTags, "tag", "t", []string{}, "specify tags to use as device selectors")
 }
@@ -57,9 +58,20 @@ var cmdRead = &cobra.Command{
 		You cannot specify devices both by ID and tag. Doing so will result in
 		an error.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
+
+		The wide table also shows the device type and context of each reading.
+
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each reading as they are in the JSON
+		output, e.g.:
+
+		   --filter 'type == "temperature" && value > 35' --sort-by value:desc
+
+		See 'synse --help' for the filter expressions.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#read</>
@@ -72,56 +84,54 @@ var cmdRead = &cobra.Command{
 			exiter.Err("cannot specify device IDs and device tags together")
 		}
 
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverRead(cmd.OutOrStdout(), args))
+		exiter.Err(serverRead(cmd.OutOrStdout(), fanout(cmd), args))
 	},
 }
 
-func serverRead(out io.Writer, devices []string) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	var readings []*scheme.Read
-	if len(devices) != 0 {
-		for _, device := range devices {
-			log.WithField("device", device).Debug("issuing HTTP read device request")
-			response, err := client.ReadDevice(device)
-			if err != nil {
-				return err
-			}
-			readings = append(readings, response...)
-		}
-	} else {
-		log.WithFields(log.Fields{
-			"tags": flagTags,
-			"ns":   flagNS,
-		}).Debug("issuing HTTP read request")
-		response, err := client.Read(scheme.ReadOptions{
-			Tags: utils.NormalizeTags(flagTags),
-			NS:   flagNS,
-		})
+func serverRead(out io.Writer, run *utils.Fanout, devices []string) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
+	printer.SetHeader("ID", "VALUE", "UNIT", "TYPE", "TIMESTAMP")
+	printer.SetRowFunc(serverReadRowFunc)
+	printer.SetWideColumns(serverReadWideColumns...)
+
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		readings = response
-	}
 
-	if len(readings) == 0 {
-		log.Debug("no readings reported from server")
-		return nil
-	}
+		var readings []*scheme.Read
+		if len(devices) != 0 {
+			for _, device := range devices {
+				log.WithField("device", device).Debug("issuing HTTP read device request")
+				response, err := client.ReadDevice(device)
+				if err != nil {
+					return nil, err
+				}
+				readings = append(readings, response...)
+			}
+		} else {
+			log.WithFields(log.Fields{
+				"tags": flagTags,
+				"ns":   flagNS,
+			}).Debug("issuing HTTP read request")
+			response, err := client.Read(scheme.ReadOptions{
+				Tags: utils.NormalizeTags(flagTags),
+				NS:   flagNS,
+			})
+			if err != nil {
+				return nil, err
+			}
+			readings = response
+		}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
-	printer.SetHeader("ID", "VALUE", "UNIT", "TYPE", "TIMESTAMP")
-	printer.SetRowFunc(serverReadRowFunc)
+		if len(readings) == 0 {
+			log.Debug("no readings reported from server")
+			return nil, nil
+		}
 
-	sort.Sort(Readings(readings))
-	return printer.Write(readings)
+		sort.Sort(Readings(readings))
+		return readings, nil
+	})
 }
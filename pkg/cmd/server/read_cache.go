@@ -18,19 +18,20 @@ package server
 
 import (
 	"io"
-	"sort"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
 func init() {
 	cmdReadCache.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdReadCache.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdReadCache.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdReadCache.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdReadCache.Flags(), &flagOutput)
+	cmdReadCache.Flags().BoolVarP(&flagNoSort, "no-sort", "", false, "print results as they are received, without sorting them")
 	cmdReadCache.Flags().StringVarP(&flagStart, "start", "s", "", "timestamp specifying the starting bound for windowing")
 	cmdReadCache.Flags().StringVarP(&flagEnd, "end", "e", "", "timestamp specifying the ending bound for windowing")
 }
@@ -49,63 +50,104 @@ var cmdReadCache = &cobra.Command{
 		The start and end bounding timestamps should be specified in FRC3339
 		format. An invalidly formatted timestamp may render the bound ineffective.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		Results are sorted once all of them have been received. With --no-sort,
+		they are printed as they are received instead, which uses less memory
+		for large results. Table, CSV, TSV and NDJSON output is then written
+		a row at a time. Results sorted with --sort-by are always held until
+		all of them have been received.
+
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
+
+		The wide table also shows the device type and context of each reading.
+
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each reading as they are in the JSON
+		output, e.g.:
+
+		   --filter 'type == "temperature" && value > 35' --sort-by timestamp:desc
+
+		See 'synse --help' for the filter expressions.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#read-cache</>
 	`),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverReadCache(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(serverReadCache(cmd.OutOrStdout(), fanout(cmd)))
 	},
 }
 
-func serverReadCache(out io.Writer) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	readings := make(chan *scheme.Read, 5)
-	log.WithFields(log.Fields{
-		"start": flagStart,
-		"end":   flagEnd,
-	}).Debug("issuing HTTP read cache request")
-	err = client.ReadCache(
-		scheme.ReadCacheOptions{
-			Start: flagStart,
-			End:   flagEnd,
-		},
-		readings,
-	)
-	if err != nil {
-		return err
-	}
-
-	var response []*scheme.Read
-	for reading := range readings {
-		response = append(response, reading)
-	}
-
-	if len(response) == 0 {
-		log.Debug("no readings reported from server")
-		return nil
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverReadCache(out io.Writer, run *utils.Fanout) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("ID", "VALUE", "UNIT", "TYPE", "TIMESTAMP")
 	printer.SetRowFunc(serverReadRowFunc)
+	printer.SetWideColumns(serverReadWideColumns...)
+	if !flagNoSort {
+		printer.SetLessFunc(readingLess)
+	}
 
-	sort.Sort(Readings(response))
-	return printer.Write(response)
+	return run.Stream(printer, func(factory clients.Factory, emit func(interface{}) error) error {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return err
+		}
+
+		// The client sends the readings on the channel as they are decoded
+		// from the response, so they are received while the request is made.
+		readings := make(chan *scheme.Read, 5)
+		errs := make(chan error, 1)
+		log.WithFields(log.Fields{
+			"start": flagStart,
+			"end":   flagEnd,
+		}).Debug("issuing HTTP read cache request")
+		go func() {
+			errs <- client.ReadCache(
+				scheme.ReadCacheOptions{
+					Start: flagStart,
+					End:   flagEnd,
+				},
+				readings,
+			)
+		}()
+
+		var count int
+		for {
+			select {
+			case err := <-errs:
+				if err != nil {
+					return err
+				}
+				// The request is done; receive the rest of the readings, if
+				// the channel has not already been closed.
+				for readings != nil {
+					reading, ok := <-readings
+					if !ok {
+						break
+					}
+					count++
+					if err := emit(reading); err != nil {
+						return err
+					}
+				}
+				if count == 0 {
+					log.Debug("no readings reported from server")
+				}
+				return nil
+
+			case reading, ok := <-readings:
+				if !ok {
+					readings = nil
+					continue
+				}
+				count++
+				if err := emit(reading); err != nil {
+					return err
+				}
+			}
+		}
+	})
 }
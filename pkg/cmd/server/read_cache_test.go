@@ -20,10 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdReadCache_multipleFormats(t *testing.T) {
@@ -33,57 +30,40 @@ func TestCmdReadCache_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("read-cache.multiple-formats.golden")
 }
 
 func TestCmdReadCache_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Run(t)
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdReadCache_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Run(t)
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdReadCache_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Run(t)
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("readcache.table.golden")
 }
 
 func TestCmdReadCache_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Args(
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -91,13 +71,9 @@ func TestCmdReadCache_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdReadCache_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Args(
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -105,15 +81,62 @@ func TestCmdReadCache_json(t *testing.T) {
 }
 
 func TestCmdReadCache_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdReadCache).Args(
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("readcache.yaml.golden")
 }
+
+func TestCmdReadCache_csv(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "csv",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("readcache.csv.golden")
+}
+
+func TestCmdReadCache_noSort(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--no-sort",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("readcache.no-sort.golden")
+}
+
+func TestCmdReadCache_ndjson(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "ndjson",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("readcache.ndjson.golden")
+}
+
+func TestCmdReadCache_wide(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "wide",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("readcache.wide.golden")
+}
+
+func TestCmdReadCache_sortBy(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdReadCache).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--sort-by", "value:desc",
+		"--no-sort",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("readcache.sort-by.golden")
+}
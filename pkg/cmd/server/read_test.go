@@ -20,10 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdRead_invalidArgs(t *testing.T) {
@@ -45,57 +42,40 @@ func TestCmdRead_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("read.multiple-formats.golden")
 }
 
 func TestCmdRead_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Run(t)
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdRead_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Run(t)
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdRead_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Run(t)
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("read.table.golden")
 }
 
 func TestCmdRead_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Args(
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -103,13 +83,9 @@ func TestCmdRead_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdRead_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Args(
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -117,15 +93,53 @@ func TestCmdRead_json(t *testing.T) {
 }
 
 func TestCmdRead_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdRead).Args(
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("read.yaml.golden")
 }
+
+func TestCmdRead_csv(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "csv",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.csv.golden")
+}
+
+func TestCmdRead_tsvFields(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "tsv",
+		"--fields", "ID,VALUE,UNIT",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.tsv-fields.golden")
+}
+
+func TestCmdRead_wide(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "wide",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.wide.golden")
+}
+
+func TestCmdRead_filterJSON(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdRead).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--filter", "value > 8 && unit.symbol == \"fu\"",
+		"-o", "json",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("read.filter-json.golden")
+}
@@ -17,17 +17,20 @@
 package server
 
 import (
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/cmd/server/plugins"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 )
 
 // Define variables which hold values passed in via flags. These are
 // defined here because they are used by multiple commands in the package.
 var (
 	flagNoHeader  bool
-	flagJSON      bool
-	flagYaml      bool
+	flagNoSort    bool
+	flagOutput    utils.Output
 	flagForce     bool
 	flagIds       bool
 	flagWait      bool
@@ -37,15 +40,23 @@ var (
 	flagTags      []string
 	flagDeviceIds []string
 
-	flagTLSCert string
-	flagContext string
+	flagTLSCert  string
+	flagContext  string
+	flagSelector string
+	flagTimeout  time.Duration
+
+	flagForceProtected bool
+
+	flagContexts       []string
+	flagAllContexts    bool
+	flagContextPattern string
 )
 
 // resetFlags resets the flag values. This is useful for tests.
 func resetFlags() {
 	flagNoHeader = false
-	flagJSON = false
-	flagYaml = false
+	flagNoSort = false
+	flagOutput = utils.Output{}
 	flagForce = false
 	flagIds = false
 	flagWait = false
@@ -55,6 +66,49 @@ func resetFlags() {
 	flagTags = []string{}
 	flagTLSCert = ""
 	flagContext = ""
+	flagSelector = ""
+	flagTimeout = 0
+	flagForceProtected = false
+	flagContexts = []string{}
+	flagAllContexts = false
+	flagContextPattern = ""
+}
+
+// clientFactory gets the factory used to create clients for the command,
+// resolving the context from the --with-context and --tlscert flags.
+func clientFactory(cmd *cobra.Command) clients.Factory {
+	return clients.FromCmd(cmd, utils.NewClientFactory(utils.ClientOptions{
+		Context: flagContext,
+		TLSCert: flagTLSCert,
+		Timeout: flagTimeout,
+	}))
+}
+
+// fanout gets the utils.Fanout which runs the command against the server
+// contexts selected by the --contexts, --all-contexts, --context-pattern and
+// -l/--selector flags, or against a single context if none are set.
+func fanout(cmd *cobra.Command) *utils.Fanout {
+	return &utils.Fanout{
+		Selection: utils.ContextSelection{
+			Names:    flagContexts,
+			All:      flagAllContexts,
+			Pattern:  flagContextPattern,
+			Selector: flagSelector,
+		},
+		Type:    "server",
+		Context: flagContext,
+		Factory: func(name string) clients.Factory {
+			if name == "" {
+				return clientFactory(cmd)
+			}
+			return clients.ForContext(cmd, name, utils.NewClientFactory(utils.ClientOptions{
+				Context: name,
+				TLSCert: flagTLSCert,
+				Timeout: flagTimeout,
+			}))
+		},
+		Err: cmd.ErrOrStderr(),
+	}
 }
 
 // New returns a new instance of the 'server' command.
@@ -67,12 +121,27 @@ func New() *cobra.Command {
 
 			In order to issue commands to a Synse Server instance, there must be
 			a current server context. See 'synse context' for details.
+
+			Commands can also be run against multiple server contexts at once,
+			selected by name with the --contexts, --all-contexts or
+			--context-pattern flags, or by label with the -l/--selector flag. The
+			request is made against each context concurrently, and the results
+			are merged into a single output: tables get a leading CONTEXT column,
+			and JSON and YAML output holds the data for each context under its
+			name. A failure for one context is reported without aborting the
+			others. The 'stream' and 'write' commands do not support multiple
+			contexts.
 		`),
 	}
 
 	// Add flag options
 	cmd.PersistentFlags().StringVarP(&flagTLSCert, "tlscert", "", "", "path to TLS certificate file (e.g. ./server.pem)")
 	cmd.PersistentFlags().StringVarP(&flagContext, "with-context", "", "", "the name of the plugin context to use")
+	cmd.PersistentFlags().StringVarP(&flagSelector, "selector", "l", "", "run against the server contexts with labels matching a selector (e.g. env=prod,site!=lab)")
+	cmd.PersistentFlags().DurationVarP(&flagTimeout, "timeout", "", 0, "timeout for requests to the server (e.g. 10s; default 2s)")
+	cmd.PersistentFlags().StringSliceVarP(&flagContexts, "contexts", "", []string{}, "run against each of the named server contexts")
+	cmd.PersistentFlags().BoolVarP(&flagAllContexts, "all-contexts", "", false, "run against all server contexts")
+	cmd.PersistentFlags().StringVarP(&flagContextPattern, "context-pattern", "", "", "run against the server contexts with names matching a glob pattern (e.g. 'site-*')")
 
 	// Add sub-commands
 	cmd.AddCommand(
@@ -0,0 +1,35 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package server
+
+import "github.com/vapor-ware/synse-cli/pkg/config"
+
+// siteContexts creates server contexts for tests which run a command
+// against multiple contexts.
+func siteContexts(names ...string) []config.ContextRecord {
+	var ctxs []config.ContextRecord
+	for _, name := range names {
+		ctxs = append(ctxs, config.ContextRecord{
+			Name: name,
+			Type: "server",
+			Context: config.Context{
+				Address: name + ":5000",
+			},
+		})
+	}
+	return ctxs
+}
@@ -23,14 +23,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
 func init() {
 	cmdScan.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdScan.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdScan.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdScan.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdScan.Flags(), &flagOutput)
 	cmdScan.Flags().BoolVarP(&flagForce, "force", "", false, "force a cache rebuild on the server")
 	cmdScan.Flags().StringVarP(&flagNS, "ns", "", "", "default tag namespace for tags with no explicit namespace set")
 	cmdScan.Flags().StringSliceVarP(&flagTags, "tag", "t", []string{}, "specify tags to use as device selectors")
@@ -55,56 +56,63 @@ var cmdScan = &cobra.Command{
 		   --tag default/foo --tag default/type:bar
 		   --tag default/foo,default/type:bar
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
+
+		The wide table also shows the alias, plugin and tags of each device.
+
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each device as they are in the JSON
+		output, e.g.:
+
+		   --filter 'type == "temperature"' --sort-by plugin,id
+
+		See 'synse --help' for the filter expressions.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#scan</>
 	`),
 
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverScan(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(serverScan(cmd.OutOrStdout(), fanout(cmd)))
 	},
 }
 
-func serverScan(out io.Writer) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	log.WithFields(log.Fields{
-		"tags":  flagTags,
-		"force": flagForce,
-		"ns":    flagNS,
-	}).Debug("issuing HTTP scan request")
-	response, err := client.Scan(scheme.ScanOptions{
-		Tags:  utils.NormalizeTags(flagTags),
-		Force: flagForce,
-		NS:    flagNS,
-	})
-	if err != nil {
-		return err
-	}
-
-	if len(response) == 0 {
-		log.Debug("no devices reported by server")
-		return nil
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverScan(out io.Writer, run *utils.Fanout) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("DEVICE_ID", "TYPE", "INFO")
 	printer.SetRowFunc(serverScanRowFunc)
+	printer.SetWideColumns(serverScanWideColumns...)
+
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithFields(log.Fields{
+			"tags":  flagTags,
+			"force": flagForce,
+			"ns":    flagNS,
+		}).Debug("issuing HTTP scan request")
+		response, err := client.Scan(scheme.ScanOptions{
+			Tags:  utils.NormalizeTags(flagTags),
+			Force: flagForce,
+			NS:    flagNS,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response) == 0 {
+			log.Debug("no devices reported by server")
+			return nil, nil
+		}
 
-	sort.Sort(DeviceSummaries(response))
-	return printer.Write(response)
+		sort.Sort(DeviceSummaries(response))
+		return response, nil
+	})
 }
@@ -20,10 +20,9 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
+	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-cli/pkg/config"
 )
 
 func TestCmdScan_multipleFormats(t *testing.T) {
@@ -33,57 +32,40 @@ func TestCmdScan_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("scan.multiple-formats.golden")
 }
 
 func TestCmdScan_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdScan).Run(t)
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdScan_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdScan).Run(t)
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdScan_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdScan).Run(t)
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("scan.table.golden")
 }
 
 func TestCmdScan_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdScan).Args(
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -91,13 +73,9 @@ func TestCmdScan_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdScan_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdScan).Args(
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -105,15 +83,219 @@ func TestCmdScan_json(t *testing.T) {
 }
 
 func TestCmdScan_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdScan).Args(
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("scan.yaml.golden")
 }
+
+func TestCmdScan_csv(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "csv",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.csv.golden")
+}
+
+func TestCmdScan_csvFields(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "csv",
+		"--fields", "type,device_id",
+		"--no-header",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.csv-fields.golden")
+}
+
+func TestCmdScan_unknownField(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--fields", "value",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("scan.unknown-field.golden")
+}
+
+func TestCmdScan_contexts(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-a", "site-b"))
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdScan)).WithRoot("server").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"--contexts", "site-b,site-a",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.contexts.table.golden")
+}
+
+func TestCmdScan_allContextsJSON(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-b", "site-a"))
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdScan)).WithRoot("server").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"--all-contexts",
+		"--json",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.contexts.json.golden")
+}
+
+func TestCmdScan_contextsTSV(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-a", "site-b"))
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdScan)).WithRoot("server").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"--all-contexts",
+		"-o", "tsv",
+		"--fields", "context,device_id",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.contexts.tsv.golden")
+}
+
+func TestCmdScan_contextPatternPartialFailure(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-a", "site-b", "site-c", "lab"))
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdScan)).WithRoot("server").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3Err()},
+		"site-c": {Err: fmt.Errorf("connection refused")},
+		"lab":    {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"--context-pattern", "site-*",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("scan.contexts.partial-failure.golden")
+}
+
+func TestCmdScan_labelSelector(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-a", "site-b", "lab"))
+	assert.NoError(t, config.LabelContext("site-a", map[string]string{"env": "prod"}, nil))
+	assert.NoError(t, config.LabelContext("site-b", map[string]string{"env": "prod"}, nil))
+	assert.NoError(t, config.LabelContext("lab", map[string]string{"env": "dev"}, nil))
+
+	// The label selector runs the command against each matching context.
+	result := test.Cmd(test.AddToRoot(t, New, cmdScan)).WithRoot("server").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3()},
+		"lab":    {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"-l", "env=prod",
+		"-o", "tsv",
+		"--fields", "context,device_id",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.contexts.tsv.golden")
+}
+
+func TestCmdScan_contextsWithContext(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-a"))
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdScan)).WithRoot("server").WithClients(test.ContextClients{}).Args(
+		"--contexts", "site-a",
+		"--with-context", "site-a",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("contexts.with-context.golden")
+}
+
+func TestCmdScan_wide(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "wide",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.wide.golden")
+}
+
+func TestCmdScan_columnsCSV(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"-o", "csv",
+		"--columns", "ID:.id,TAGS:.tags[*]",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.columns-csv.golden")
+}
+
+func TestCmdScan_badColumns(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--columns", "ID",
+	).Run(t)
+	result.AssertErr()
+	result.AssertGolden("scan.bad-columns.golden")
+}
+
+func TestCmdScan_filter(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--filter", `alias == "fake-device2" || tags[*] == "vapor/none"`,
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.filter.golden")
+}
+
+func TestCmdScan_sortBy(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--sort-by", "type,id:desc",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("scan.sort-by.golden")
+}
+
+func TestCmdScan_badFilter(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdScan).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--filter", `type ==`,
+	).Run(t)
+	result.AssertErr()
+	result.AssertGolden("scan.bad-filter.golden")
+}
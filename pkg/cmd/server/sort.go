@@ -78,3 +78,8 @@ func (s Transactions) Less(i, j int) bool {
 func (s Transactions) Swap(i, j int) {
 	s[i], s[j] = s[j], s[i]
 }
+
+// readingLess orders readings as Readings does, for printer streams.
+func readingLess(a, b interface{}) bool {
+	return Readings{a.(*scheme.Read), b.(*scheme.Read)}.Less(0, 1)
+}
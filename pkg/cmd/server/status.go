@@ -22,13 +22,13 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 )
 
 func init() {
 	cmdStatus.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdStatus.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdStatus.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdStatus.Flags(), &flagOutput)
 }
 
 var cmdStatus = &cobra.Command{
@@ -37,41 +37,32 @@ var cmdStatus = &cobra.Command{
 	Long: utils.Doc(`
 		Get the connectivity status for the configured Synse Server instance.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#test</>
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverStatus(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(serverStatus(cmd.OutOrStdout(), fanout(cmd)))
 	},
 }
 
-func serverStatus(out io.Writer) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	log.Debug("issuing HTTP status request")
-	response, err := client.Status()
-	if err != nil {
-		return err
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverStatus(out io.Writer, run *utils.Fanout) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("STATUS", "TIMESTAMP")
 	printer.SetRowFunc(serverStatusRowFunc)
 
-	return printer.Write(response)
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debug("issuing HTTP status request")
+		return client.Status()
+	})
 }
@@ -31,6 +31,7 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 	"golang.org/x/sync/errgroup"
@@ -78,13 +79,19 @@ var cmdStream = &cobra.Command{
 			exiter.Err("cannot specify device IDs and device tags together")
 		}
 
-		exiter.Err(serverStream(cmd.OutOrStdout()))
+		// Error out if multiple contexts are selected, since each would need
+		// its own live-updating output.
+		if !fanout(cmd).Selection.Empty() {
+			exiter.Err("cannot stream from multiple contexts")
+		}
+
+		exiter.Err(serverStream(cmd.OutOrStdout(), clientFactory(cmd)))
 	},
 }
 
-func serverStream(out io.Writer) error {
+func serverStream(out io.Writer, factory clients.Factory) error {
 	log.Debug("creating new WebSocket client")
-	client, err := utils.NewSynseWebsocketClient(flagContext, flagTLSCert)
+	client, err := factory.WebSocket()
 	if err != nil {
 		return err
 	}
@@ -199,17 +206,11 @@ readLoop:
 			// do nothing and continue on
 		}
 
-		// Special casing for unit symbol.
-		symbol := reading.Unit.Symbol
-		if symbol == "%" {
-			symbol = "%%"
-		}
-
 		row := strings.Join([]string{
 			reading.Device,
 			reading.Type,
 			fmt.Sprintf("%v", reading.Value),
-			symbol,
+			reading.Unit.Symbol,
 			reading.Timestamp,
 		}, "\t")
 		lock.Lock()
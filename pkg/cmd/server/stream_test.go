@@ -20,33 +20,22 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdStream_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseWebsocketClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdStream).Run(t)
+	result := test.Cmd(cmdStream).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdStream_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseWebsocketClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdStream).Run(t)
+	result := test.Cmd(cmdStream).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
@@ -55,10 +44,6 @@ func TestCmdStream_requestError(t *testing.T) {
 // FIXME (etd): 03/27/2020 - This test appears to be failing intermittently. Need to investigate and
 //   correct. Temporarily disabling. See: https://github.com/vapor-ware/synse-cli/issues/230
 //func TestCmdStream(t *testing.T) {
-//	patch := monkey.Patch(utils.NewSynseWebsocketClient, func(ctx string, cert string) (synse.Client, error) {
-//		return test.NewFakeHTTPClientV3(), nil
-//	})
-//	defer patch.Unpatch()
 //	defer resetFlags()
 //
 //	// After 1 second, terminate the stream
@@ -71,6 +56,17 @@ func TestCmdStream_requestError(t *testing.T) {
 //	// Unfortunately, there are no great ways of testing the output because of how it is
 //	// rendered and how the amount of data rendered may be variable, so for right now the
 //	// best we can do is just check that the command exited without error.
-//	result := test.Cmd(cmdStream).Run(t)
+//	result := test.Cmd(cmdStream).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 //	result.AssertNoErr()
 //}
+
+func TestCmdStream_contexts(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(test.AddToRoot(t, New, cmdStream)).WithRoot("server").WithClients(test.ContextClients{}).Args(
+		"--all-contexts",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("stream.contexts.golden")
+}
@@ -22,14 +22,14 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
 func init() {
 	cmdTags.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdTags.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdTags.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdTags.Flags(), &flagOutput)
 	cmdTags.Flags().BoolVarP(&flagIds, "ids", "", false, "include id tags in the output")
 	cmdTags.Flags().StringVarP(&flagNS, "ns", "", "", "default tag namespace for tags with no explicit namespace set")
 }
@@ -40,52 +40,47 @@ var cmdTags = &cobra.Command{
 	Long: utils.Doc(`
 		List tags currently associated with devices.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#tags</>
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverTags(cmd.OutOrStdout()))
+		exit.FromCmd(cmd).Err(serverTags(cmd.OutOrStdout(), fanout(cmd)))
 	},
 }
 
-func serverTags(out io.Writer) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	log.WithFields(log.Fields{
-		"ns":  flagNS,
-		"ids": flagIds,
-	}).Debug("issuing HTTP tags request")
-	response, err := client.Tags(scheme.TagsOptions{
-		NS:  []string{flagNS},
-		IDs: flagIds,
-	})
-	if err != nil {
-		return err
-	}
-
-	if len(response) == 0 {
-		log.Debug("no tags reported by server")
-		return nil
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverTags(out io.Writer, run *utils.Fanout) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("TAG")
 	printer.SetRowFunc(serverTagsRowFunc)
 
-	return printer.Write(response)
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithFields(log.Fields{
+			"ns":  flagNS,
+			"ids": flagIds,
+		}).Debug("issuing HTTP tags request")
+		response, err := client.Tags(scheme.TagsOptions{
+			NS:  []string{flagNS},
+			IDs: flagIds,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response) == 0 {
+			log.Debug("no tags reported by server")
+			return nil, nil
+		}
+		return response, nil
+	})
 }
@@ -23,14 +23,15 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
 func init() {
 	cmdTransaction.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdTransaction.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdTransaction.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdTransaction.Flags(), &flagOutput)
+	utils.AddQueryFlags(cmdTransaction.Flags(), &flagOutput)
 }
 
 var cmdTransaction = &cobra.Command{
@@ -58,67 +59,79 @@ var cmdTransaction = &cobra.Command{
 		    execute the write. This is a terminal state. Once a transaction
 		    is in this state, it will no longer be updated.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
+
+		The results can be filtered with --filter and sorted with --sort-by,
+		both of which use the fields of each transaction as they are in the JSON
+		output, e.g.:
+
+		   --filter 'status == "ERROR"' --sort-by created:desc
+
+		See 'synse --help' for the filter expressions.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#transaction</>
 	`),
 	Run: func(cmd *cobra.Command, args []string) {
-		exiter := exit.FromCmd(cmd)
-
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
-		exiter.Err(serverTransaction(cmd.OutOrStdout(), args))
+		exit.FromCmd(cmd).Err(serverTransaction(cmd.OutOrStdout(), fanout(cmd), args))
 	},
 }
 
-func serverTransaction(out io.Writer, transactions []string) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
+func serverTransaction(out io.Writer, run *utils.Fanout, transactions []string) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 
 	// If there are no transactions specified, get all of them.
 	if len(transactions) == 0 {
-		log.Debug("no transactions specified -- getting all transactions")
-		txns, err := client.Transactions()
-		if err != nil {
-			return err
-		}
-
-		printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
 		printer.SetHeader("ID")
 		printer.SetRowFunc(serverTransactionsRowFunc)
 
-		sort.Strings(txns)
-		return printer.Write(txns)
+		return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+			log.Debug("creating new HTTP client")
+			client, err := factory.HTTP()
+			if err != nil {
+				return nil, err
+			}
+
+			log.Debug("no transactions specified -- getting all transactions")
+			txns, err := client.Transactions()
+			if err != nil {
+				return nil, err
+			}
+
+			sort.Strings(txns)
+			return txns, nil
+		})
 	}
 
-	var txns []*scheme.Transaction
-	for _, t := range transactions {
-		log.WithField("txn", t).Debug("issuing HTTP transaction request")
-		response, err := client.Transaction(t)
+	printer.SetHeader("ID", "STATUS", "MESSAGE", "CREATED", "UPDATED")
+	printer.SetRowFunc(serverTransactionRowFunc)
+
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		txns = append(txns, response)
-	}
 
-	if len(txns) == 0 {
-		log.Debug("no transactions reported by server")
-		return nil
-	}
+		var txns []*scheme.Transaction
+		for _, t := range transactions {
+			log.WithField("txn", t).Debug("issuing HTTP transaction request")
+			response, err := client.Transaction(t)
+			if err != nil {
+				return nil, err
+			}
+			txns = append(txns, response)
+		}
 
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
-	printer.SetHeader("ID", "STATUS", "MESSAGE", "CREATED", "UPDATED")
-	printer.SetRowFunc(serverTransactionRowFunc)
+		if len(txns) == 0 {
+			log.Debug("no transactions reported by server")
+			return nil, nil
+		}
 
-	sort.Sort(Transactions(txns))
-	return printer.Write(txns)
+		sort.Sort(Transactions(txns))
+		return txns, nil
+	})
 }
@@ -20,10 +20,7 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
 )
 
 func TestCmdTransaction_multipleFormats(t *testing.T) {
@@ -33,69 +30,48 @@ func TestCmdTransaction_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("transaction.multiple-formats.golden")
 }
 
 func TestCmdTransaction_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Run(t)
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("bad-client.golden")
 }
 
 func TestCmdTransaction_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Run(t)
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Run(t)
 	result.AssertNoErr()
 	result.AssertExited()
 	result.AssertGolden("request-err.golden")
 }
 
 func TestCmdTransaction_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args("abc-def").Run(t)
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args("abc-def").Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("transaction.table.golden")
 }
 
 func TestCmdTransactions_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Run(t)
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("transactions.table.golden")
 }
 
 func TestCmdTransaction_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args(
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"abc-def",
 		"--no-header",
 	).Run(t)
@@ -104,13 +80,9 @@ func TestCmdTransaction_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdTransactions_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args(
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--no-header",
 	).Run(t)
 	result.AssertNoErr()
@@ -118,13 +90,9 @@ func TestCmdTransactions_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdTransaction_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args(
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"abc-def",
 		"--json",
 	).Run(t)
@@ -133,13 +101,9 @@ func TestCmdTransaction_json(t *testing.T) {
 }
 
 func TestCmdTransactions_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args(
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--json",
 	).Run(t)
 	result.AssertNoErr()
@@ -147,13 +111,9 @@ func TestCmdTransactions_json(t *testing.T) {
 }
 
 func TestCmdTransaction_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args(
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"abc-def",
 		"--yaml",
 	).Run(t)
@@ -162,15 +122,33 @@ func TestCmdTransaction_yaml(t *testing.T) {
 }
 
 func TestCmdTransactions_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdTransaction).Args(
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"--yaml",
 	).Run(t)
 	result.AssertNoErr()
 	result.AssertGolden("transactions.yaml.golden")
 }
+
+func TestCmdTransactions_sortBy(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"--sort-by", "@",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("transactions.sort-by.golden")
+}
+
+func TestCmdTransaction_filter(t *testing.T) {
+	defer resetFlags()
+
+	result := test.Cmd(cmdTransaction).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"abc-def",
+		"--filter", `status != "DONE"`,
+		"-o", "json",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("transaction.filter.golden")
+}
@@ -23,15 +23,16 @@ import (
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 	"github.com/vapor-ware/synse-cli/pkg/utils"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
 	"github.com/vapor-ware/synse-cli/pkg/utils/exit"
 	"github.com/vapor-ware/synse-client-go/synse/scheme"
 )
 
 func init() {
 	cmdWrite.Flags().BoolVarP(&flagNoHeader, "no-header", "n", false, "do not print out column headers")
-	cmdWrite.Flags().BoolVarP(&flagJSON, "json", "", false, "print output as JSON")
-	cmdWrite.Flags().BoolVarP(&flagYaml, "yaml", "", false, "print output as YAML")
+	utils.AddOutputFlags(cmdWrite.Flags(), &flagOutput)
 	cmdWrite.Flags().BoolVarP(&flagWait, "wait", "w", false, "wait for the write to complete")
+	cmdWrite.Flags().BoolVarP(&flagForceProtected, "force-protected", "", false, "allow the write to a protected context, once its name is typed to confirm")
 }
 
 var cmdWrite = &cobra.Command{
@@ -51,9 +52,14 @@ var cmdWrite = &cobra.Command{
 		will display the final status of the write transaction, indicating error
 		or success.
 
-		The output of this command can be formatted as a table (default), as
-		JSON, or as YAML. If specifying the output format, only one flag may
-		be used. Using multiple output format flags will result in an error.
+		Writes to a protected context are refused. To write to one anyway,
+		give the --force-protected flag and type the name of the context when
+		prompted to confirm the write.
+
+		The output of this command can be formatted with -o/--output as a
+		table (default), a wide table, CSV, TSV, JSON, NDJSON or YAML, or
+		with a JSONPath or Go template. See 'synse --help' for the output
+		formats.
 
 		For more information, see:
 		<underscore>https://vapor-ware.github.io/synse-server/#write</>
@@ -62,11 +68,6 @@ var cmdWrite = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		exiter := exit.FromCmd(cmd)
 
-		// Error out if multiple output formats are specified.
-		if flagJSON && flagYaml {
-			exiter.Err("cannot use multiple formatting flags at once")
-		}
-
 		device := args[0]
 		action := args[1]
 		data := ""
@@ -74,74 +75,87 @@ var cmdWrite = &cobra.Command{
 			data = args[2]
 		}
 
+		// Error out if multiple contexts are selected, so that a single
+		// command can not change devices across several deployments.
+		run := fanout(cmd)
+		if !run.Selection.Empty() {
+			exiter.Err("cannot write to multiple contexts")
+		}
+
+		targets, err := run.Targets()
+		exiter.Err(err)
+		exiter.Err(utils.ConfirmWrite(cmd.InOrStdin(), cmd.ErrOrStderr(), flagForceProtected, targets))
+
 		if flagWait {
 			log.Debug("writing synchronously")
-			exiter.Err(serverWriteSync(cmd.OutOrStdout(), device, action, data))
+			exiter.Err(serverWriteSync(cmd.OutOrStdout(), run, device, action, data))
 		} else {
 			log.Debug("writing asynchronously")
-			exiter.Err(serverWriteAsync(cmd.OutOrStdout(), device, action, data))
+			exiter.Err(serverWriteAsync(cmd.OutOrStdout(), run, device, action, data))
 		}
 	},
 }
 
-func serverWriteAsync(out io.Writer, device, action, data string) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	log.WithFields(log.Fields{
-		"device": device,
-		"action": action,
-		"data":   data,
-	}).Debug("issuing HTTP write async request")
-	response, err := client.WriteAsync(device, []scheme.WriteData{{
-		Action: action,
-		Data:   data,
-	}})
-	if err != nil {
-		return err
-	}
-
-	if len(response) == 0 {
-		return fmt.Errorf("failed device write")
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverWriteAsync(out io.Writer, run *utils.Fanout, device, action, data string) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("ID", "ACTION", "DATA", "DEVICE")
 	printer.SetRowFunc(serverTransactionSummaryRowFunc)
 
-	return printer.Write(response)
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithFields(log.Fields{
+			"device": device,
+			"action": action,
+			"data":   data,
+		}).Debug("issuing HTTP write async request")
+		response, err := client.WriteAsync(device, []scheme.WriteData{{
+			Action: action,
+			Data:   data,
+		}})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response) == 0 {
+			return nil, fmt.Errorf("failed device write")
+		}
+		return response, nil
+	})
 }
 
-func serverWriteSync(out io.Writer, device, action, data string) error {
-	log.Debug("creating new HTTP client")
-	client, err := utils.NewSynseHTTPClient(flagContext, flagTLSCert)
-	if err != nil {
-		return err
-	}
-
-	log.WithFields(log.Fields{
-		"device": device,
-		"action": action,
-		"data":   data,
-	}).Debug("issuing HTTP write sync request")
-	response, err := client.WriteSync(device, []scheme.WriteData{{
-		Action: action,
-		Data:   data,
-	}})
-	if err != nil {
-		return err
-	}
-
-	if len(response) == 0 {
-		return fmt.Errorf("failed device write")
-	}
-
-	printer := utils.NewPrinter(out, flagJSON, flagYaml, flagNoHeader)
+func serverWriteSync(out io.Writer, run *utils.Fanout, device, action, data string) error {
+	printer := utils.NewPrinter(out, flagOutput, flagNoHeader)
 	printer.SetHeader("ID", "STATUS", "MESSAGE", "CREATED", "UPDATED")
 	printer.SetRowFunc(serverTransactionRowFunc)
 
-	return printer.Write(response)
+	return run.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		log.Debug("creating new HTTP client")
+		client, err := factory.HTTP()
+		if err != nil {
+			return nil, err
+		}
+
+		log.WithFields(log.Fields{
+			"device": device,
+			"action": action,
+			"data":   data,
+		}).Debug("issuing HTTP write sync request")
+		response, err := client.WriteSync(device, []scheme.WriteData{{
+			Action: action,
+			Data:   data,
+		}})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(response) == 0 {
+			return nil, fmt.Errorf("failed device write")
+		}
+		return response, nil
+	})
 }
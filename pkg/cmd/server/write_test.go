@@ -20,10 +20,8 @@ import (
 	"fmt"
 	"testing"
 
-	"bou.ke/monkey"
 	"github.com/vapor-ware/synse-cli/internal/test"
-	"github.com/vapor-ware/synse-cli/pkg/utils"
-	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-cli/pkg/config"
 )
 
 func TestCmdWrite_extraArgs(t *testing.T) {
@@ -50,19 +48,14 @@ func TestCmdWrite_multipleFormats(t *testing.T) {
 		"--json",
 		"--yaml",
 	).Run(t)
-	result.AssertNoErr()
-	result.AssertExited()
-	result.AssertGolden("multiple-formats.golden")
+	result.AssertErr()
+	result.AssertGolden("write.multiple-formats.golden")
 }
 
 func TestCmdWriteAsync_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Args(
 		"111-222-333",
 		"foo",
 	).Run(t)
@@ -72,13 +65,9 @@ func TestCmdWriteAsync_badClient(t *testing.T) {
 }
 
 func TestCmdWriteAsync_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -89,13 +78,9 @@ func TestCmdWriteAsync_requestError(t *testing.T) {
 }
 
 func TestCmdWriteAsync_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -105,13 +90,9 @@ func TestCmdWriteAsync_table(t *testing.T) {
 }
 
 func TestCmdWriteAsync_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -122,13 +103,9 @@ func TestCmdWriteAsync_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdWriteAsync_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -139,13 +116,9 @@ func TestCmdWriteAsync_json(t *testing.T) {
 }
 
 func TestCmdWriteAsync_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -156,13 +129,9 @@ func TestCmdWriteAsync_yaml(t *testing.T) {
 }
 
 func TestCmdWriteSync_badClient(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return nil, fmt.Errorf("test error message")
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Err: fmt.Errorf("test error message")}).Args(
 		"111-222-333",
 		"foo",
 		"--wait",
@@ -173,13 +142,9 @@ func TestCmdWriteSync_badClient(t *testing.T) {
 }
 
 func TestCmdWriteSync_requestError(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3Err(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3Err()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -191,13 +156,9 @@ func TestCmdWriteSync_requestError(t *testing.T) {
 }
 
 func TestCmdWriteSync_table(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -208,13 +169,9 @@ func TestCmdWriteSync_table(t *testing.T) {
 }
 
 func TestCmdWriteSync_tableNoHeader(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -226,13 +183,9 @@ func TestCmdWriteSync_tableNoHeader(t *testing.T) {
 }
 
 func TestCmdWriteSync_json(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -244,13 +197,9 @@ func TestCmdWriteSync_json(t *testing.T) {
 }
 
 func TestCmdWriteSync_yaml(t *testing.T) {
-	patch := monkey.Patch(utils.NewSynseHTTPClient, func(ctx string, cert string) (synse.Client, error) {
-		return test.NewFakeHTTPClientV3(), nil
-	})
-	defer patch.Unpatch()
 	defer resetFlags()
 
-	result := test.Cmd(cmdWrite).Args(
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
 		"111-222-333",
 		"foo",
 		"bar",
@@ -260,3 +209,89 @@ func TestCmdWriteSync_yaml(t *testing.T) {
 	result.AssertNoErr()
 	result.AssertGolden("write.sync.yaml.golden")
 }
+
+// protectedTestContexts are a protected server context named "prod".
+var protectedTestContexts = []config.ContextRecord{
+	{
+		Name:      "prod",
+		Type:      "server",
+		Protected: true,
+		Context: config.Context{
+			Address: "prod:5000",
+		},
+	},
+}
+
+func TestCmdWrite_protected(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, protectedTestContexts, "prod")
+
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"111-222-333",
+		"foo",
+		"bar",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("write.protected.golden")
+}
+
+func TestCmdWrite_forceProtected(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, protectedTestContexts, "prod")
+
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"111-222-333",
+		"foo",
+		"bar",
+		"--force-protected",
+	).Input("prod\n").Run(t)
+	result.AssertNoErr()
+	result.AssertGolden("write.force-protected.golden")
+}
+
+func TestCmdWrite_forceProtectedNotConfirmed(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, protectedTestContexts, "prod")
+
+	result := test.Cmd(cmdWrite).WithClients(&test.FakeClients{Server: test.NewFakeHTTPClientV3()}).Args(
+		"111-222-333",
+		"foo",
+		"bar",
+		"--force-protected",
+	).Input("staging\n").Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("write.force-protected-not-confirmed.golden")
+}
+
+func TestCmdWrite_contexts(t *testing.T) {
+	defer func() {
+		config.Purge()
+		resetFlags()
+	}()
+	test.AddContexts(t, siteContexts("site-a", "site-b"))
+
+	// A write is never fanned out across contexts.
+	result := test.Cmd(test.AddToRoot(t, New, cmdWrite)).WithRoot("server").WithClients(test.ContextClients{
+		"site-a": {Server: test.NewFakeHTTPClientV3()},
+		"site-b": {Server: test.NewFakeHTTPClientV3()},
+	}).Args(
+		"--all-contexts",
+		"111-222-333",
+		"foo",
+		"bar",
+	).Run(t)
+	result.AssertNoErr()
+	result.AssertExited()
+	result.AssertGolden("write.contexts.golden")
+}
@@ -0,0 +1,88 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+)
+
+// UnixScheme is the prefix of the address of a component which listens on a
+// unix domain socket, e.g. "unix:///tmp/synse/plugin.sock".
+const UnixScheme = "unix://"
+
+// UnixSocketPath gets the path of the socket for a unix socket address. It
+// returns false if the address is not a unix socket address.
+func UnixSocketPath(address string) (string, bool) {
+	if !strings.HasPrefix(address, UnixScheme) {
+		return "", false
+	}
+	return strings.TrimPrefix(address, UnixScheme), true
+}
+
+// validateAddress checks that an address is either a TCP address in the
+// form host[:port] or a unix socket address with an absolute path.
+func validateAddress(address string) error {
+	if path, ok := UnixSocketPath(address); ok {
+		if path == "" {
+			return fmt.Errorf("unix socket address '%s' has no path", address)
+		}
+		if !filepath.IsAbs(path) {
+			return fmt.Errorf("unix socket address '%s' must have an absolute path (e.g. unix:///tmp/synse.sock)", address)
+		}
+		return nil
+	}
+
+	if i := strings.Index(address, "://"); i != -1 {
+		return fmt.Errorf("unsupported address scheme '%s' (addresses are host[:port] or unix:///path)", address[:i])
+	}
+	if _, err := url.Parse("//" + address); err != nil {
+		return err
+	}
+	return nil
+}
+
+// proxySchemes are the supported schemes for the URL of a proxy.
+var proxySchemes = []string{"http", "https", "socks5"}
+
+// validateProxy checks that a proxy URL has a supported scheme and a host,
+// e.g. socks5://bastion:1080. Connections to a unix socket can not be made
+// through a proxy.
+func validateProxy(proxy, address string) error {
+	if _, ok := UnixSocketPath(address); ok {
+		return errors.New("proxies can not be used with unix socket addresses")
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil {
+		return err
+	}
+	supported := false
+	for _, scheme := range proxySchemes {
+		supported = supported || u.Scheme == scheme
+	}
+	if !supported {
+		return fmt.Errorf("unsupported scheme '%s' (supported: %s)", u.Scheme, strings.Join(proxySchemes, ", "))
+	}
+	if u.Host == "" {
+		return errors.New("no host")
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// RedactedValue replaces the value of a secret setting when a context is
+// displayed.
+const RedactedValue = "REDACTED"
+
+// headerNameChars are the characters, other than letters and digits, which
+// may be used in an HTTP header name (RFC 7230 token characters).
+const headerNameChars = "!#$%&'*+-.^_`|~"
+
+// HasAuth checks whether any authentication settings are configured for
+// the Context.
+func (c Context) HasAuth() bool {
+	return len(c.Headers) != 0 || c.Token != "" || c.CredentialHelper != ""
+}
+
+// Redacted gets a copy of the Context with the values of its headers, its
+// token and any proxy password replaced, so that it may be displayed.
+func (c Context) Redacted() Context {
+	redacted := c.clone()
+	for name := range redacted.Headers {
+		redacted.Headers[name] = RedactedValue
+	}
+	if redacted.Token != "" {
+		redacted.Token = RedactedValue
+	}
+	if u, err := url.Parse(redacted.Proxy); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), RedactedValue)
+			redacted.Proxy = u.String()
+		}
+	}
+	return redacted
+}
+
+// withoutCredentials gets a copy of the Context with its authentication
+// settings and any proxy password removed, so that it may be shared.
+func (c Context) withoutCredentials() Context {
+	c.Headers = nil
+	c.Token = ""
+	c.CredentialHelper = ""
+	if u, err := url.Parse(c.Proxy); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.User(u.User.Username())
+			c.Proxy = u.String()
+		}
+	}
+	return c
+}
+
+// clone gets a copy of the Context which shares no state with it.
+func (c Context) clone() Context {
+	if c.Headers != nil {
+		headers := make(map[string]string, len(c.Headers))
+		for name, value := range c.Headers {
+			headers[name] = value
+		}
+		c.Headers = headers
+	}
+	return c
+}
+
+// validateAuth checks that authentication settings are only configured for
+// server contexts, and that the configured headers are well-formed.
+func validateAuth(ctx *ContextRecord) error {
+	if !ctx.Context.HasAuth() {
+		return nil
+	}
+	if ctx.Type != "server" {
+		return errors.New("has authentication settings, which are only supported for server contexts")
+	}
+	for name, value := range ctx.Context.Headers {
+		if err := validateHeader(name, value); err != nil {
+			return fmt.Errorf("has %v", err)
+		}
+	}
+	return nil
+}
+
+// validateHeader checks that the name and value can be sent as an HTTP
+// header.
+func validateHeader(name, value string) error {
+	if name == "" {
+		return errors.New("an empty header name")
+	}
+	for _, r := range name {
+		if !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' || strings.ContainsRune(headerNameChars, r)) {
+			return fmt.Errorf("an invalid header name '%s'", name)
+		}
+	}
+	if strings.ContainsAny(value, "\r\n\x00") {
+		return fmt.Errorf("an invalid value for header '%s'", name)
+	}
+	return nil
+}
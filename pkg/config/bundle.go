@@ -0,0 +1,353 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// certDir is the directory, relative to the user's home directory, where
+// certificates embedded in imported context bundles are written by default.
+const certDir = ".synse/certs"
+
+// MergeStrategy defines how a context from an imported Bundle is handled
+// when a context with the same name already exists.
+type MergeStrategy string
+
+// The supported strategies for merging imported contexts.
+const (
+	// MergeSkip keeps the existing context and ignores the imported one.
+	MergeSkip MergeStrategy = "skip"
+
+	// MergeOverwrite replaces the existing context with the imported one.
+	MergeOverwrite MergeStrategy = "overwrite"
+
+	// MergeRename imports the context under a new, unused name.
+	MergeRename MergeStrategy = "rename"
+)
+
+// Bundle is a portable collection of context records which can be shared
+// between CLI configurations.
+type Bundle struct {
+	Contexts       []BundleRecord    `json:"contexts" yaml:"contexts"`
+	CurrentContext map[string]string `json:"current_context,omitempty" yaml:"current_context,omitempty"`
+}
+
+// BundleRecord is a ContextRecord which may carry its TLS certificates and
+// key inline as base64-encoded data, so it does not depend on the files being
+// present at the same path on every machine.
+type BundleRecord struct {
+	ContextRecord  `yaml:",inline"`
+	ClientCertData string `json:"client_cert_data,omitempty" yaml:"client_cert_data,omitempty"`
+	ClientKeyData  string `json:"client_key_data,omitempty" yaml:"client_key_data,omitempty"`
+	CACertData     string `json:"ca_cert_data,omitempty" yaml:"ca_cert_data,omitempty"`
+}
+
+// bundleFile associates a file referenced by a Context with the field of a
+// BundleRecord which holds its embedded data.
+type bundleFile struct {
+	desc   string
+	suffix string
+	path   func(*Context) *string
+	data   func(*BundleRecord) *string
+}
+
+// bundleFiles are the files which may be embedded in a BundleRecord.
+var bundleFiles = []bundleFile{
+	{
+		desc:   "client cert",
+		suffix: ".pem",
+		path:   func(c *Context) *string { return &c.ClientCert },
+		data:   func(r *BundleRecord) *string { return &r.ClientCertData },
+	},
+	{
+		desc:   "client key",
+		suffix: "-key.pem",
+		path:   func(c *Context) *string { return &c.ClientKey },
+		data:   func(r *BundleRecord) *string { return &r.ClientKeyData },
+	},
+	{
+		desc:   "CA cert",
+		suffix: "-ca.pem",
+		path:   func(c *Context) *string { return &c.CACert },
+		data:   func(r *BundleRecord) *string { return &r.CACertData },
+	},
+}
+
+// ImportResult describes how a single context from a Bundle was imported.
+type ImportResult struct {
+	// Name is the name the context was imported as.
+	Name string
+
+	// Original is the name of the context in the bundle.
+	Original string
+
+	// Action describes what was done with the context: "added",
+	// "overwritten", "renamed", or "skipped".
+	Action string
+}
+
+// DefaultCertDir gets the default directory which certificates embedded in
+// an imported Bundle are written to.
+func DefaultCertDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return certDir
+	}
+	return filepath.Join(home, certDir)
+}
+
+// Export creates a Bundle from the named contexts. If no names are given,
+// all contexts are exported. If embedCerts is set, the certificates and key
+// for each context are read and included in the bundle as base64 data.
+//
+// The authentication settings of the contexts, and any proxy password, are
+// left out of the bundle unless includeCredentials is set.
+func (c *Config) Export(names []string, embedCerts, includeCredentials bool) (*Bundle, error) {
+	var records []ContextRecord
+	if len(names) == 0 {
+		records = c.Contexts
+	} else {
+		for _, name := range names {
+			ctx := c.GetContext(name)
+			if ctx == nil {
+				return nil, fmt.Errorf("cannot export '%s': no such context", name)
+			}
+			records = append(records, *ctx)
+		}
+	}
+
+	bundle := &Bundle{
+		Contexts:       []BundleRecord{},
+		CurrentContext: map[string]string{},
+	}
+	for _, ctx := range records {
+		record := BundleRecord{ContextRecord: ctx}
+		if !includeCredentials {
+			record.Context = record.Context.withoutCredentials()
+		}
+		for _, f := range bundleFiles {
+			path := f.path(&record.Context)
+			if !embedCerts || *path == "" {
+				continue
+			}
+			data, err := os.ReadFile(*path)
+			if err != nil {
+				return nil, fmt.Errorf("cannot embed %s for context '%s': %v", f.desc, ctx.Name, err)
+			}
+			*f.data(&record) = base64.StdEncoding.EncodeToString(data)
+
+			// The local path has no meaning once the file is embedded.
+			*path = ""
+		}
+		bundle.Contexts = append(bundle.Contexts, record)
+
+		if c.IsCurrentContext(&ctx) {
+			bundle.CurrentContext[ctx.Type] = ctx.Name
+		}
+	}
+
+	log.WithField("contexts", len(bundle.Contexts)).Debug("exported contexts")
+	return bundle, nil
+}
+
+// Export creates a Bundle from the default configuration.
+func Export(names []string, embedCerts, includeCredentials bool) (*Bundle, error) {
+	return config.Export(names, embedCerts, includeCredentials)
+}
+
+// Import merges the contexts in the Bundle into the configuration. Conflicts
+// with existing context names are resolved using the given MergeStrategy.
+// Embedded certificates and keys are written to new files in certDir, and the
+// imported contexts are updated to reference them. Existing files are never
+// overwritten.
+//
+// If setCurrent is true, the current contexts recorded in the bundle are set
+// as the current contexts, following any renames. Contexts which were skipped
+// are never set as current.
+//
+// All imported contexts are validated before any file is written or change is
+// made. If the import still fails, the files it wrote are removed, so a failed
+// import leaves the configuration and cert directory untouched.
+func (c *Config) Import(bundle *Bundle, strategy MergeStrategy, certDir string, setCurrent bool) ([]ImportResult, error) {
+	if strategy != MergeSkip && strategy != MergeOverwrite && strategy != MergeRename {
+		return nil, fmt.Errorf("unsupported merge strategy: %s", strategy)
+	}
+
+	taken := map[string]bool{}
+	for _, ctx := range c.Contexts {
+		taken[ctx.Name] = true
+	}
+
+	var (
+		results     []ImportResult
+		records     []ContextRecord
+		pending     []pendingFile
+		overwritten []string
+	)
+	for _, r := range bundle.Contexts {
+		result := ImportResult{Name: r.Name, Original: r.Name, Action: "added"}
+		if taken[r.Name] {
+			switch strategy {
+			case MergeSkip:
+				result.Action = "skipped"
+				results = append(results, result)
+				continue
+			case MergeOverwrite:
+				result.Action = "overwritten"
+				overwritten = append(overwritten, r.Name)
+			case MergeRename:
+				result.Action = "renamed"
+				result.Name = UniqueName(r.Name, taken)
+			}
+		}
+		taken[result.Name] = true
+
+		record := r.ContextRecord
+		record.Name = result.Name
+		for _, f := range bundleFiles {
+			encoded := *f.data(&r)
+			if encoded == "" {
+				continue
+			}
+			data, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("context '%s' has invalid %s data: %v", r.Name, f.desc, err)
+			}
+			pending = append(pending, pendingFile{idx: len(records), file: f, data: data})
+
+			// Embedded files are not written until all contexts are validated,
+			// so validate the record without them.
+			*f.path(&record.Context) = ""
+		}
+
+		results = append(results, result)
+		records = append(records, record)
+	}
+
+	if errs := c.checkReplaceContexts(overwritten, records); len(errs) != 0 {
+		return nil, errs[0]
+	}
+
+	var written []string
+	for _, p := range pending {
+		path, err := writeCert(certDir, records[p.idx].Name, p.file.suffix, p.data)
+		if err != nil {
+			removeFiles(written)
+			return nil, err
+		}
+		written = append(written, path)
+		*p.file.path(&records[p.idx].Context) = path
+	}
+
+	if errs := c.ReplaceContexts(overwritten, records); len(errs) != 0 {
+		removeFiles(written)
+		return nil, errs[0]
+	}
+
+	if setCurrent {
+		if c.CurrentContext == nil {
+			c.CurrentContext = map[string]string{}
+		}
+		for t, name := range bundle.CurrentContext {
+			for _, result := range results {
+				if result.Original == name && result.Action != "skipped" {
+					c.CurrentContext[t] = result.Name
+				}
+			}
+		}
+	}
+
+	log.WithField("contexts", len(records)).Debug("imported contexts")
+	return results, nil
+}
+
+// Import merges a Bundle into the default configuration.
+func Import(bundle *Bundle, strategy MergeStrategy, certDir string, setCurrent bool) ([]ImportResult, error) {
+	return config.Import(bundle, strategy, certDir, setCurrent)
+}
+
+// pendingFile is embedded file data which is to be written out for the
+// context at the given index of the imported records.
+type pendingFile struct {
+	idx  int
+	file bundleFile
+	data []byte
+}
+
+// UniqueName generates a name based on the given name which is not yet taken,
+// by appending an incrementing numeric suffix.
+func UniqueName(name string, taken map[string]bool) string {
+	for i := 1; ; i++ {
+		candidate := fmt.Sprintf("%s-%d", name, i)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
+
+// writeCert writes certificate or key data to a new file in the cert
+// directory, returning the path to the written file. The file is named for
+// the context with the given suffix. Existing files are never overwritten,
+// since they may be in use by other contexts; if the name is taken, a numeric
+// suffix is added to the context name.
+func writeCert(dir, name, suffix string, data []byte) (string, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	base := strings.ReplaceAll(name, string(filepath.Separator), "_")
+	for i := 0; ; i++ {
+		path := filepath.Join(dir, base+suffix)
+		if i > 0 {
+			path = filepath.Join(dir, fmt.Sprintf("%s-%d%s", base, i, suffix))
+		}
+
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if os.IsExist(err) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if _, err := f.Write(data); err != nil {
+			f.Close()       // nolint: errcheck
+			os.Remove(path) // nolint: errcheck
+			return "", err
+		}
+		if err := f.Close(); err != nil {
+			os.Remove(path) // nolint: errcheck
+			return "", err
+		}
+		log.WithField("path", path).Debug("wrote imported client cert")
+		return path, nil
+	}
+}
+
+// removeFiles removes the files at the given paths, logging any failure.
+func removeFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			log.WithError(err).WithField("path", path).Debug("failed to remove imported client cert")
+		}
+	}
+}
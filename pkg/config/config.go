@@ -19,13 +19,11 @@ package config
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"strings"
+	"time"
 
-	"github.com/mitchellh/mapstructure"
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/viper"
-	"gopkg.in/yaml.v2"
 )
 
 func init() {
@@ -46,6 +44,10 @@ var config Config
 
 // Config specifies the persisted configuration for the CLI.
 type Config struct {
+	// Version is the schema version of the config. Config files with an
+	// older schema version are migrated when they are loaded.
+	Version int `json:"version" yaml:"version" mapstructure:"version"`
+
 	Contexts       []ContextRecord   `json:"contexts" yaml:"contexts" mapstructure:"contexts"`
 	CurrentContext map[string]string `json:"current_context" yaml:"current_context" mapstructure:"current_context"`
 }
@@ -56,6 +58,28 @@ type ContextRecord struct {
 	Name    string  `json:"name" yaml:"name" mapstructure:"name"`
 	Type    string  `json:"type" yaml:"type" mapstructure:"type"`
 	Context Context `json:"context" yaml:"context" mapstructure:"context"`
+
+	// SyncedFrom is the name of the server context a plugin context was
+	// created from by 'synse context sync-plugins', if any. Only synced
+	// contexts are pruned by a later sync.
+	SyncedFrom string `json:"synced_from,omitempty" yaml:"synced_from,omitempty" mapstructure:"synced_from"`
+
+	// Protected contexts refuse writes, unless the write is forced and the
+	// name of the context is typed to confirm it.
+	Protected bool `json:"protected,omitempty" yaml:"protected,omitempty" mapstructure:"protected"`
+
+	// Labels are arbitrary key/value pairs (e.g. site, env, region) which
+	// contexts can be selected by.
+	Labels map[string]string `json:"labels,omitempty" yaml:"labels,omitempty" mapstructure:"labels"`
+
+	// Defaults are used for the flags of server and plugin commands which
+	// are not set explicitly when the command is run against the context.
+	Defaults *Defaults `json:"defaults,omitempty" yaml:"defaults,omitempty" mapstructure:"defaults"`
+
+	// Source is the path of the config file the context was loaded from.
+	// It is not persisted. Contexts without a source are persisted to the
+	// highest precedence config layer.
+	Source string `json:"-" yaml:"-" mapstructure:"-"`
 }
 
 // Context specifies any contextual information associated
@@ -64,56 +88,199 @@ type ContextRecord struct {
 type Context struct {
 	Address    string `json:"address" yaml:"address" mapstructure:"address"`
 	ClientCert string `json:"client_cert" yaml:"client_cert" mapstructure:"client_cert"`
+
+	// TLS settings for the connection. The ClientCert and ClientKey are used
+	// for mutual TLS, the CACert is used to verify the component's certificate,
+	// and the ServerName overrides the name used for verification (e.g. when
+	// connecting through a TLS-terminating proxy).
+	ClientKey          string `json:"client_key,omitempty" yaml:"client_key,omitempty" mapstructure:"client_key"`
+	CACert             string `json:"ca_cert,omitempty" yaml:"ca_cert,omitempty" mapstructure:"ca_cert"`
+	ServerName         string `json:"server_name,omitempty" yaml:"server_name,omitempty" mapstructure:"server_name"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" yaml:"insecure_skip_verify,omitempty" mapstructure:"insecure_skip_verify"`
+
+	// Proxy is the URL of the proxy through which connections to the
+	// component are made, e.g. http://proxy:3128 or socks5://bastion:1080.
+	// If it is not set, the proxy is taken from the environment (HTTPS_PROXY,
+	// HTTP_PROXY and NO_PROXY).
+	Proxy string `json:"proxy,omitempty" yaml:"proxy,omitempty" mapstructure:"proxy"`
+
+	// Authentication for the HTTP and WebSocket requests made to a server.
+	// The Headers are sent with each request and the Token is sent as a
+	// bearer token. The CredentialHelper is a command which is run to get
+	// credentials for the server; its credentials take precedence over the
+	// static settings.
+	Headers          map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" mapstructure:"headers"`
+	Token            string            `json:"token,omitempty" yaml:"token,omitempty" mapstructure:"token"`
+	CredentialHelper string            `json:"credential_helper,omitempty" yaml:"credential_helper,omitempty" mapstructure:"credential_helper"`
 }
 
-// Load loads the configuration for the CLI. If a configuration file
-// cannot be found, this will load a new empty Config instance.
-func Load() error {
-	log.Debug("loading cli configuration")
-	v := readConfigFromFile()
+// Output formats which may be set as a context default.
+var defaultOutputs = []string{"table", "wide", "json", "yaml"}
 
-	// Use mapstructure.Decode here instead of Viper's built-in Unmarshal
-	// since that appears to be broken and does not properly load all keys.
-	err := mapstructure.Decode(v.AllSettings(), &config)
-	if err != nil {
-		return err
+// Defaults holds the values used for server and plugin command flags which
+// are not set explicitly, for commands run against a context.
+type Defaults struct {
+	// Namespace is the default tag namespace (--ns).
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty" mapstructure:"namespace"`
+
+	// Output is the output format: table, wide, json or yaml (-o).
+	Output string `json:"output,omitempty" yaml:"output,omitempty" mapstructure:"output"`
+
+	// NoHeader omits table headers (--no-header).
+	NoHeader bool `json:"no_header,omitempty" yaml:"no_header,omitempty" mapstructure:"no_header"`
+
+	// Timeout is the timeout for requests to the component, as a duration
+	// string, e.g. "10s" (--timeout).
+	Timeout string `json:"timeout,omitempty" yaml:"timeout,omitempty" mapstructure:"timeout"`
+
+	// Tags are the device selector tags used when no devices are given
+	// (--tag).
+	Tags []string `json:"tags,omitempty" yaml:"tags,omitempty" mapstructure:"tags"`
+}
+
+// IsZero checks whether no defaults are set.
+func (d *Defaults) IsZero() bool {
+	return d == nil || (d.Namespace == "" && d.Output == "" && !d.NoHeader && d.Timeout == "" && len(d.Tags) == 0)
+}
+
+// clone gets a copy of the defaults which shares no state with them.
+func (d *Defaults) clone() *Defaults {
+	if d == nil {
+		return nil
 	}
+	c := *d
+	c.Tags = append([]string(nil), d.Tags...)
+	return &c
+}
 
-	log.WithField("config", config).Debug("unmarshaled config")
+// validate checks that the defaults have a supported output format and a
+// parsable timeout.
+func (d *Defaults) validate() error {
+	if d == nil {
+		return nil
+	}
+	if d.Output != "" {
+		supported := false
+		for _, o := range defaultOutputs {
+			supported = supported || d.Output == o
+		}
+		if !supported {
+			return fmt.Errorf("unsupported default output format '%s' (supported: %s)", d.Output, strings.Join(defaultOutputs, ", "))
+		}
+	}
+	if d.Timeout != "" {
+		timeout, err := time.ParseDuration(d.Timeout)
+		if err != nil {
+			return fmt.Errorf("invalid default timeout: %v", err)
+		}
+		if timeout < 0 {
+			return fmt.Errorf("invalid default timeout: %s is negative", d.Timeout)
+		}
+	}
 	return nil
 }
 
-// Persist saves the CLI configuration to disk, writing back the
-// in-memory config to the configuration YAML file.
-func Persist() error {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return err
+// TLSEnabled checks whether any TLS settings are configured for the Context.
+func (c Context) TLSEnabled() bool {
+	return c.ClientCert != "" || c.ClientKey != "" || c.CACert != "" || c.ServerName != "" || c.InsecureSkipVerify
+}
+
+// ValidateContext checks that a ContextRecord is well-formed. A valid record
+// has a name, a supported type (plugin or server), and a parsable address,
+// either host[:port] or unix:///path. A proxy must be an http, https or socks5
+// URL. Any configured certificate or key must be a readable file, and only
+// server contexts may have authentication settings.
+func ValidateContext(ctx *ContextRecord) error {
+	if ctx.Name == "" {
+		return errors.New("context name must not be empty")
 	}
-	wd, err := os.Getwd()
-	if err != nil {
-		return err
+
+	if ctx.Type != "plugin" && ctx.Type != "server" {
+		return fmt.Errorf("unsupported context type: %s", ctx.Type)
 	}
-	var configPath = filepath.Join(wd, configFile)
-	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) {
-		log.Debug("persisting cli config to HOME directory")
-		configPath = filepath.Join(home, configFile)
-	} else if err != nil {
-		log.Debug(fmt.Sprintf("stat cli config: %v", err))
-		return err
+
+	if ctx.Context.Address == "" {
+		return fmt.Errorf("context '%s' has no address", ctx.Name)
+	}
+	if err := validateAddress(ctx.Context.Address); err != nil {
+		return fmt.Errorf("context '%s' has an invalid address: %v", ctx.Name, err)
 	}
 
-	log.WithFields(log.Fields{
-		"path":   configPath,
-		"config": fmt.Sprintf("%+v", config),
-	}).Debug("persisting config")
+	if ctx.Context.Proxy != "" {
+		if err := validateProxy(ctx.Context.Proxy, ctx.Context.Address); err != nil {
+			return fmt.Errorf("context '%s' has an invalid proxy '%s': %v", ctx.Name, ctx.Context.Proxy, err)
+		}
+	}
 
-	data, err := yaml.Marshal(config)
+	for key, value := range ctx.Labels {
+		if err := validateLabel(key, value); err != nil {
+			return fmt.Errorf("context '%s' has %v", ctx.Name, err)
+		}
+	}
+
+	if err := ctx.Defaults.validate(); err != nil {
+		return fmt.Errorf("context '%s' has %v", ctx.Name, err)
+	}
+
+	if err := validateAuth(ctx); err != nil {
+		return fmt.Errorf("context '%s' %v", ctx.Name, err)
+	}
+
+	if ctx.Context.ClientKey != "" && ctx.Context.ClientCert == "" {
+		return fmt.Errorf("context '%s' has a client key but no client cert", ctx.Name)
+	}
+	for _, f := range []struct {
+		desc string
+		path string
+	}{
+		{"client cert", ctx.Context.ClientCert},
+		{"client key", ctx.Context.ClientKey},
+		{"CA cert", ctx.Context.CACert},
+	} {
+		if f.path == "" {
+			continue
+		}
+		if err := checkReadable(f.path); err != nil {
+			return fmt.Errorf("context '%s' has an unreadable %s: %v", ctx.Name, f.desc, err)
+		}
+	}
+	return nil
+}
+
+// ValidateContexts checks each of the given ContextRecords with ValidateContext
+// and ensures that no two records share the same name. All failures are
+// collected and returned, so the caller may report them together.
+func ValidateContexts(ctxs []ContextRecord) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for i := range ctxs {
+		if err := ValidateContext(&ctxs[i]); err != nil {
+			errs = append(errs, err)
+		}
+		if ctxs[i].Name != "" && seen[ctxs[i].Name] {
+			errs = append(errs, fmt.Errorf("context '%s': name already exists", ctxs[i].Name))
+		}
+		seen[ctxs[i].Name] = true
+	}
+	return errs
+}
+
+// checkReadable checks that the file at the given path can be opened for reading.
+func checkReadable(path string) error {
+	f, err := os.Open(path)
 	if err != nil {
 		return err
 	}
-	log.Debug("cli configuration persisted")
-	return os.WriteFile(configPath, data, 0644)
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory", path)
+	}
+	return nil
 }
 
 // GetContexts gets the contexts for the default configuration.
@@ -164,6 +331,102 @@ func AddContext(ctx *ContextRecord) error {
 	return config.AddContext(ctx)
 }
 
+// ReplaceContexts replaces the named contexts in the configuration with the
+// given ContextRecords. The resulting set of contexts is validated before any
+// change is made; if it is not valid, all failures are returned and the
+// configuration is left untouched.
+//
+// A current context which referenced one of the replaced contexts is kept only
+// if a replacement with the same name and type exists.
+func (c *Config) ReplaceContexts(names []string, ctxs []ContextRecord) []error {
+	replaced := map[string]bool{}
+	for _, name := range names {
+		replaced[name] = true
+	}
+
+	var contexts []ContextRecord
+	sources := map[string]string{}
+	for _, ctx := range c.Contexts {
+		if replaced[ctx.Name] {
+			sources[ctx.Name] = ctx.Source
+		} else {
+			contexts = append(contexts, ctx)
+		}
+	}
+
+	// A replacement with the same name as a replaced record is persisted
+	// to the same config file.
+	for _, ctx := range ctxs {
+		if ctx.Source == "" {
+			ctx.Source = sources[ctx.Name]
+		}
+		contexts = append(contexts, ctx)
+	}
+
+	if errs := validateReplacements(contexts[:len(contexts)-len(ctxs)], ctxs); len(errs) != 0 {
+		return errs
+	}
+
+	for t, name := range c.CurrentContext {
+		if !replaced[name] {
+			continue
+		}
+		var found bool
+		for _, ctx := range ctxs {
+			if ctx.Name == name && ctx.Type == t {
+				found = true
+				break
+			}
+		}
+		if !found {
+			delete(c.CurrentContext, t)
+		}
+	}
+
+	c.Contexts = contexts
+	log.WithField("contexts", names).Debug("replaced contexts")
+	return nil
+}
+
+// checkReplaceContexts checks whether replacing the named contexts in the
+// configuration with the given ContextRecords would succeed, without making
+// any change.
+func (c *Config) checkReplaceContexts(names []string, ctxs []ContextRecord) []error {
+	replaced := map[string]bool{}
+	for _, name := range names {
+		replaced[name] = true
+	}
+
+	var kept []ContextRecord
+	for _, ctx := range c.Contexts {
+		if !replaced[ctx.Name] {
+			kept = append(kept, ctx)
+		}
+	}
+	return validateReplacements(kept, ctxs)
+}
+
+// validateReplacements validates the records which are to replace contexts,
+// given the contexts which are kept. Only the replacement records are
+// validated, since existing records may pre-date validation. Names must still
+// be unique across the full set.
+func validateReplacements(kept, ctxs []ContextRecord) []error {
+	errs := ValidateContexts(ctxs)
+	for _, ctx := range kept {
+		for _, r := range ctxs {
+			if r.Name == ctx.Name {
+				errs = append(errs, fmt.Errorf("context '%s': name already exists", r.Name))
+			}
+		}
+	}
+	return errs
+}
+
+// ReplaceContexts replaces contexts in the default configuration.
+func ReplaceContexts(names []string, ctxs []ContextRecord) []error {
+	return config.ReplaceContexts(names, ctxs)
+}
+
 // RemoveContext removes a context from the configuration. If the given
 // name does not correspond to a context, this has no effect.
 //
@@ -199,6 +462,138 @@ func RemoveContext(name string) {
 	config.RemoveContext(name)
 }
 
+// RenameContext renames a context. A current context which referenced the
+// context, and plugin contexts synced from it, are updated to the new name.
+// The renamed context is persisted to the same config file.
+func (c *Config) RenameContext(name, newName string) error {
+	ctx := c.GetContext(name)
+	if ctx == nil {
+		return fmt.Errorf("cannot rename context '%s': no such context", name)
+	}
+	if newName == name {
+		return nil
+	}
+	if c.GetContext(newName) != nil {
+		return fmt.Errorf("cannot rename context '%s': name '%s' already exists", name, newName)
+	}
+
+	renamed := *ctx
+	renamed.Name = newName
+	if err := ValidateContext(&renamed); err != nil {
+		return err
+	}
+
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts[i] = renamed
+		}
+		if c.Contexts[i].SyncedFrom == name {
+			c.Contexts[i].SyncedFrom = newName
+		}
+	}
+	if c.CurrentContext[ctx.Type] == name {
+		c.CurrentContext[ctx.Type] = newName
+	}
+	log.WithFields(log.Fields{
+		"context": name,
+		"name":    newName,
+	}).Debug("renamed context")
+	return nil
+}
+
+// RenameContext renames a context in the default configuration.
+func RenameContext(name, newName string) error {
+	return config.RenameContext(name, newName)
+}
+
+// CopyContext adds a copy of a context with a new name. The copy is never
+// the current context, and is not considered to be synced from a server
+// even if the original is. Like other new contexts, it is persisted to the
+// highest precedence config file.
+func (c *Config) CopyContext(name, newName string) error {
+	ctx := c.GetContext(name)
+	if ctx == nil {
+		return fmt.Errorf("cannot copy context '%s': no such context", name)
+	}
+	if c.GetContext(newName) != nil {
+		return fmt.Errorf("cannot copy context '%s': name '%s' already exists", name, newName)
+	}
+
+	copied := *ctx
+	copied.Name = newName
+	copied.Context = ctx.Context.clone()
+	copied.Defaults = ctx.Defaults.clone()
+	copied.Labels = cloneLabels(ctx.Labels)
+	copied.SyncedFrom = ""
+	copied.Source = ""
+	if err := ValidateContext(&copied); err != nil {
+		return err
+	}
+
+	c.Contexts = append(c.Contexts, copied)
+	log.WithFields(log.Fields{
+		"context": name,
+		"name":    newName,
+	}).Debug("copied context")
+	return nil
+}
+
+// CopyContext copies a context in the default configuration.
+func CopyContext(name, newName string) error {
+	return config.CopyContext(name, newName)
+}
+
+// UpdateContext replaces the connection settings and command defaults of a
+// context. The context keeps its name, type and config file, so it remains
+// the current context if it was one.
+func (c *Config) UpdateContext(name string, settings Context, defaults *Defaults) error {
+	ctx := c.GetContext(name)
+	if ctx == nil {
+		return fmt.Errorf("cannot update context '%s': no such context", name)
+	}
+
+	updated := *ctx
+	updated.Context = settings.clone()
+	updated.Defaults = defaults.clone()
+	if err := ValidateContext(&updated); err != nil {
+		return err
+	}
+
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts[i] = updated
+		}
+	}
+	log.WithField("context", name).Debug("updated context")
+	return nil
+}
+
+// UpdateContext updates a context in the default configuration.
+func UpdateContext(name string, settings Context, defaults *Defaults) error {
+	return config.UpdateContext(name, settings, defaults)
+}
+
+// ProtectContext sets whether a context is protected from writes.
+func (c *Config) ProtectContext(name string, protected bool) error {
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts[i].Protected = protected
+			log.WithFields(log.Fields{
+				"context":   name,
+				"protected": protected,
+			}).Debug("set context protection")
+			return nil
+		}
+	}
+	return fmt.Errorf("cannot protect context '%s': no such context", name)
+}
+
+// ProtectContext sets whether a context in the default configuration is
+// protected from writes.
+func ProtectContext(name string, protected bool) error {
+	return config.ProtectContext(name, protected)
+}
+
 // Purge removes all contexts from the config and clears the current
 // context.
 func (c *Config) Purge() {
@@ -207,9 +602,13 @@ func (c *Config) Purge() {
 	log.Debug("cli contexts purged")
 }
 
-// Purge removes all contexts from the default configuration.
+// Purge removes all contexts from the default configuration, including
+// those hidden by contexts in higher precedence config layers.
 func Purge() {
 	config.Purge()
+	for _, l := range layers {
+		l.shadowed = nil
+	}
 }
 
 // IsCurrentContext checks if the specified ContextRecord is currently active.
@@ -301,32 +700,3 @@ func (c *Config) GetCurrentContext() map[string]*ContextRecord {
 func GetCurrentContext() map[string]*ContextRecord {
 	return config.GetCurrentContext()
 }
-
-// readConfigFromFile reads in the CLI configuration from file.
-//
-// If the config file does not exist, we fall back to using default values.
-func readConfigFromFile() *viper.Viper {
-	v := viper.New()
-	v.SetConfigName(".synse")
-	v.SetConfigType("yaml")
-
-	v.AddConfigPath(".")               // Try local first
-	v.AddConfigPath(os.Getenv("HOME")) // Then try home
-
-	// Defaults
-	v.SetDefault("current_context", map[string]string{})
-	v.SetDefault("contexts", []ContextRecord{})
-
-	err := v.ReadInConfig()
-	if err != nil {
-		log.WithFields(log.Fields{
-			"file": v.ConfigFileUsed(),
-		}).Debug("config file not found, a new one will be created")
-	}
-
-	log.WithFields(log.Fields{
-		"file":     v.ConfigFileUsed(),
-		"settings": v.AllSettings(),
-	}).Debug("loaded cli configuration")
-	return v
-}
@@ -0,0 +1,190 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestConfig() *Config {
+	return &Config{
+		Contexts: []ContextRecord{
+			{Name: "local", Type: "server", Source: "/tmp/home.yml", Context: Context{Address: "localhost:5000"}},
+			{Name: "remote", Type: "server", Context: Context{Address: "10.0.0.1:5000"}},
+			{Name: "emulator", Type: "plugin", SyncedFrom: "local", Context: Context{Address: "localhost:5001"}},
+		},
+		CurrentContext: map[string]string{
+			"server": "local",
+			"plugin": "emulator",
+		},
+	}
+}
+
+func TestConfig_RenameContext(t *testing.T) {
+	c := newTestConfig()
+
+	assert.NoError(t, c.RenameContext("local", "dev"))
+	assert.Nil(t, c.GetContext("local"))
+
+	ctx := c.GetContext("dev")
+	assert.Equal(t, "server", ctx.Type)
+	assert.Equal(t, "localhost:5000", ctx.Context.Address)
+	assert.Equal(t, "/tmp/home.yml", ctx.Source)
+	assert.Equal(t, "dev", c.CurrentContext["server"])
+	assert.Equal(t, "emulator", c.CurrentContext["plugin"])
+	assert.Equal(t, "dev", c.GetContext("emulator").SyncedFrom)
+	assert.Len(t, c.Contexts, 3)
+}
+
+func TestConfig_RenameContext_notCurrent(t *testing.T) {
+	c := newTestConfig()
+
+	assert.NoError(t, c.RenameContext("remote", "prod"))
+	assert.NotNil(t, c.GetContext("prod"))
+	assert.Equal(t, "local", c.CurrentContext["server"])
+}
+
+func TestConfig_RenameContext_sameName(t *testing.T) {
+	c := newTestConfig()
+
+	assert.NoError(t, c.RenameContext("local", "local"))
+	assert.Equal(t, newTestConfig(), c)
+}
+
+func TestConfig_RenameContext_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		newName string
+		err     string
+	}{
+		{"missing", "other", "cannot rename context 'missing': no such context"},
+		{"local", "remote", "cannot rename context 'local': name 'remote' already exists"},
+		{"local", "", "context name must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			c := newTestConfig()
+			assert.EqualError(t, c.RenameContext(tt.name, tt.newName), tt.err)
+			assert.Equal(t, newTestConfig(), c)
+		})
+	}
+}
+
+func TestConfig_CopyContext(t *testing.T) {
+	c := newTestConfig()
+
+	c.Contexts[2].Defaults = &Defaults{Tags: []string{"type:temperature"}}
+	assert.NoError(t, c.CopyContext("emulator", "emulator-2"))
+	assert.Len(t, c.Contexts, 4)
+
+	ctx := c.GetContext("emulator-2")
+	assert.Equal(t, "plugin", ctx.Type)
+	assert.Equal(t, "localhost:5001", ctx.Context.Address)
+	assert.Empty(t, ctx.SyncedFrom)
+	assert.Empty(t, ctx.Source)
+	assert.Equal(t, []string{"type:temperature"}, ctx.Defaults.Tags)
+	assert.Equal(t, "emulator", c.CurrentContext["plugin"])
+
+	// The copy is independent of the original.
+	ctx.Defaults.Tags[0] = "type:humidity"
+	assert.NoError(t, c.UpdateContext("emulator-2", Context{Address: "localhost:5002"}, nil))
+	assert.Equal(t, "localhost:5001", c.GetContext("emulator").Context.Address)
+	assert.Equal(t, []string{"type:temperature"}, c.GetContext("emulator").Defaults.Tags)
+}
+
+func TestConfig_CopyContext_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		newName string
+		err     string
+	}{
+		{"missing", "other", "cannot copy context 'missing': no such context"},
+		{"local", "remote", "cannot copy context 'local': name 'remote' already exists"},
+		{"local", "", "context name must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			c := newTestConfig()
+			assert.EqualError(t, c.CopyContext(tt.name, tt.newName), tt.err)
+			assert.Equal(t, newTestConfig(), c)
+		})
+	}
+}
+
+func TestConfig_UpdateContext(t *testing.T) {
+	c := newTestConfig()
+
+	defaults := &Defaults{Namespace: "vapor", Tags: []string{"type:temperature"}}
+	assert.NoError(t, c.UpdateContext("local", Context{Address: "localhost:5050", ServerName: "synse.local"}, defaults))
+
+	ctx := c.GetContext("local")
+	assert.Equal(t, "localhost:5050", ctx.Context.Address)
+	assert.Equal(t, "synse.local", ctx.Context.ServerName)
+	assert.Equal(t, defaults, ctx.Defaults)
+
+	// The defaults are copied.
+	defaults.Tags[0] = "type:humidity"
+	assert.Equal(t, []string{"type:temperature"}, c.GetContext("local").Defaults.Tags)
+	assert.Equal(t, "/tmp/home.yml", ctx.Source)
+	assert.Equal(t, "local", c.CurrentContext["server"])
+}
+
+func TestConfig_UpdateContext_errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings Context
+		defaults *Defaults
+		err      string
+	}{
+		{"missing", Context{Address: "localhost:5000"}, nil, "cannot update context 'missing': no such context"},
+		{"local", Context{}, nil, "context 'local' has no address"},
+		{"local", Context{Address: "unix://synse.sock"}, nil, "context 'local' has an invalid address: unix socket address 'unix://synse.sock' must have an absolute path (e.g. unix:///tmp/synse.sock)"},
+		{"local", Context{Address: "localhost:5000", ClientKey: "key.pem"}, nil, "context 'local' has a client key but no client cert"},
+		{"local", Context{Address: "localhost:5000"}, &Defaults{Output: "xml"}, "context 'local' has unsupported default output format 'xml' (supported: table, wide, json, yaml)"},
+		{"local", Context{Address: "localhost:5000"}, &Defaults{Timeout: "soon"}, `context 'local' has invalid default timeout: time: invalid duration "soon"`},
+		{"local", Context{Address: "localhost:5000"}, &Defaults{Timeout: "-1s"}, "context 'local' has invalid default timeout: -1s is negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			c := newTestConfig()
+			assert.EqualError(t, c.UpdateContext(tt.name, tt.settings, tt.defaults), tt.err)
+			assert.Equal(t, newTestConfig(), c)
+		})
+	}
+}
+
+func TestConfig_ProtectContext(t *testing.T) {
+	c := newTestConfig()
+
+	assert.NoError(t, c.ProtectContext("local", true))
+	assert.True(t, c.GetContext("local").Protected)
+
+	assert.NoError(t, c.ProtectContext("local", false))
+	assert.Equal(t, newTestConfig(), c)
+}
+
+func TestConfig_ProtectContext_missing(t *testing.T) {
+	c := newTestConfig()
+
+	assert.EqualError(t, c.ProtectContext("missing", true), "cannot protect context 'missing': no such context")
+	assert.Equal(t, newTestConfig(), c)
+}
@@ -0,0 +1,174 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// lockDir is the directory, relative to the user's cache directory, which
+// holds the lock files for config files.
+const lockDir = "synse/locks"
+
+// userCacheDir gets the user's cache directory. It is a variable so that
+// tests may replace it.
+var userCacheDir = os.UserCacheDir
+
+// lockPath gets the path of the lock file which serializes updates to the
+// config file at the path. Lock files are kept in the user's cache directory,
+// keyed by the absolute path of the config file, so that none are left next
+// to config files (e.g. a project's .synse.yml). If there is no cache
+// directory, the lock file is "<config file>.lock", next to the config file.
+func lockPath(path string) string {
+	dir, err := userCacheDir()
+	if err != nil {
+		log.WithError(err).Debug("no cache directory for config lock files")
+		return path + ".lock"
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	key := sha256.Sum256([]byte(path))
+	return filepath.Join(dir, lockDir, hex.EncodeToString(key[:])+".lock")
+}
+
+// updateConfigFile updates the config file at the path. The update function
+// is passed the current contents of the file, which it modifies in place. The
+// file is locked for the duration of the update, so concurrent updates from
+// other CLI processes are applied in turn rather than overwriting each other.
+//
+// The file is always written at the current schema version. If the file has
+// an older schema version, it is backed up before it is rewritten.
+func updateConfigFile(path string, update func(c *Config)) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	lock := lockPath(path)
+	if err := os.MkdirAll(filepath.Dir(lock), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(lock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	c, version, data, err := readConfigFile(path)
+	if err != nil {
+		return err
+	}
+	update(c)
+
+	if data != nil && version < CurrentVersion {
+		backup := backupPath(path, version)
+		log.WithFields(log.Fields{
+			"path":   path,
+			"backup": backup,
+		}).Debug("backing up config file prior to migration")
+		if err := writeFileAtomic(backup, data, 0600); err != nil {
+			return fmt.Errorf("failed to back up %s: %v", path, err)
+		}
+	}
+
+	out, err := encodeConfig(c)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, out, 0644)
+}
+
+// readConfigFile reads the config file at the path, migrating it to the
+// current schema version. The schema version and raw contents of the file
+// are returned along with the Config. If the file does not exist, an empty
+// Config and nil contents are returned.
+func readConfigFile(path string) (*Config, int, []byte, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Config{
+			Version:        CurrentVersion,
+			Contexts:       []ContextRecord{},
+			CurrentContext: map[string]string{},
+		}, CurrentVersion, nil, nil
+	}
+	if err != nil {
+		return nil, 0, nil, err
+	}
+
+	c, version, err := decodeConfig(data)
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to load %s: %v", path, err)
+	}
+	return c, version, data, nil
+}
+
+// writeFileAtomic writes the data to the file at the path, such that readers
+// see either the old or the new contents of the file, never a partial write.
+// The data is written to a temporary file in the same directory, which is
+// synced to disk and then renamed over the original file.
+//
+// If the file already exists, its permissions are kept.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode().Perm()
+	}
+
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// After a successful rename, the temp file no longer exists.
+		_ = os.Remove(tmp.Name())
+	}()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename itself is durable.
+	if d, err := os.Open(dir); err == nil {
+		if err := d.Sync(); err != nil {
+			log.WithField("error", err).Debug("failed to sync config directory")
+		}
+		_ = d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,350 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+// helperEnv is set when the test binary is run as a helper process by
+// TestPersist_concurrentProcesses.
+const helperEnv = "SYNSE_TEST_PERSIST_HELPER"
+
+// TestPersistHelperProcess is not a real test. It is run as a separate CLI
+// process, which loads the config, adds a context and persists it.
+func TestPersistHelperProcess(t *testing.T) {
+	name := os.Getenv(helperEnv)
+	if name == "" {
+		return
+	}
+
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	err := AddContext(&ContextRecord{
+		Name: name,
+		Type: "server",
+		Context: Context{
+			Address: name + ":5000",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Persist(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setupConfigFile points the config at a file in a temp dir, isolated from
+// any other config files.
+func setupConfigFile(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("HOME", dir)
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("XDG_CACHE_HOME", dir)
+	t.Setenv(EnvConfigFile, "")
+
+	path := filepath.Join(dir, "config.yml")
+	SetPath(path)
+	t.Cleanup(func() {
+		SetPath("")
+		Purge()
+		layers = nil
+	})
+	return path
+}
+
+// useTestCacheDir keeps lock files in a temporary directory for the
+// duration of the test. This is test.UseCacheDir, which cannot be imported
+// here as the test package depends on config.
+func useTestCacheDir(t *testing.T) string {
+	dir := t.TempDir()
+	userCacheDir = func() (string, error) { return dir, nil }
+	t.Cleanup(func() { userCacheDir = os.UserCacheDir })
+	return dir
+}
+
+func TestLockPath(t *testing.T) {
+	cacheDir := useTestCacheDir(t)
+	dir := t.TempDir()
+
+	lock := lockPath(filepath.Join(dir, ".synse.yml"))
+	assert.Equal(t, filepath.Join(cacheDir, lockDir), filepath.Dir(lock))
+	assert.Equal(t, ".lock", filepath.Ext(lock))
+
+	// Each config file has its own lock file.
+	assert.Equal(t, lock, lockPath(filepath.Join(dir, "sub", "..", ".synse.yml")))
+	assert.NotEqual(t, lock, lockPath(filepath.Join(dir, "config.yml")))
+}
+
+func TestLockPath_noCacheDir(t *testing.T) {
+	userCacheDir = func() (string, error) { return "", fmt.Errorf("no cache dir") }
+	defer func() { userCacheDir = os.UserCacheDir }()
+
+	path := filepath.Join(t.TempDir(), ".synse.yml")
+	assert.Equal(t, path+".lock", lockPath(path))
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yml")
+	assert.NoError(t, os.WriteFile(path, []byte("old"), 0600))
+
+	assert.NoError(t, writeFileAtomic(path, []byte("new"), 0644))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(data))
+
+	// The permissions of the existing file are kept.
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// No temp files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestUpdateConfigFile_concurrent(t *testing.T) {
+	useTestCacheDir(t)
+	path := filepath.Join(t.TempDir(), "config.yml")
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	// Readers should always see a complete config file.
+	var readErrs []error
+	var mu sync.Mutex
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			data, err := os.ReadFile(path)
+			if os.IsNotExist(err) {
+				continue
+			}
+			var c Config
+			if err == nil {
+				err = yaml.UnmarshalStrict(data, &c)
+			}
+			if err == nil && len(c.Contexts) == 0 {
+				err = fmt.Errorf("read empty config")
+			}
+			if err != nil {
+				mu.Lock()
+				readErrs = append(readErrs, err)
+				mu.Unlock()
+			}
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			err := updateConfigFile(path, func(c *Config) {
+				c.Contexts = append(c.Contexts, ContextRecord{
+					Name: fmt.Sprintf("ctx-%d", i),
+					Type: "server",
+					Context: Context{
+						Address: "localhost:5000",
+					},
+				})
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+	wg.Wait()
+	close(done)
+
+	c, _, _, err := readConfigFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, c.Contexts, 50)
+	assert.Empty(t, readErrs)
+
+	// The lock file is not left next to the config file.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPersist_concurrentProcesses(t *testing.T) {
+	path := setupConfigFile(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cmd := exec.Command(os.Args[0], "-test.run=^TestPersistHelperProcess$")
+			cmd.Env = append(os.Environ(),
+				fmt.Sprintf("%s=ctx-%d", helperEnv, i),
+				fmt.Sprintf("%s=%s", EnvConfigFile, path),
+			)
+			out, err := cmd.CombinedOutput()
+			assert.NoError(t, err, string(out))
+		}(i)
+	}
+	wg.Wait()
+
+	c, _, _, err := readConfigFile(path)
+	assert.NoError(t, err)
+	assert.Len(t, c.Contexts, 20)
+}
+
+func TestPersist_unchanged(t *testing.T) {
+	path := setupConfigFile(t)
+
+	// Nothing is written if there is nothing to persist.
+	assert.NoError(t, Load())
+	assert.NoError(t, Persist())
+	_, err := os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+
+	// A file which has not changed is not rewritten.
+	data := []byte("version: 1\ncontexts:\n- name: test\n  type: server\n  context:\n    address: localhost:5000\n# a comment\n")
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+	assert.NoError(t, Load())
+	assert.NoError(t, Persist())
+
+	written, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, data, written)
+}
+
+func TestPersist_keepsConcurrentChanges(t *testing.T) {
+	path := setupConfigFile(t)
+	assert.NoError(t, os.WriteFile(path, []byte(`
+contexts:
+- name: a
+  type: server
+  context:
+    address: a:5000
+- name: b
+  type: server
+  context:
+    address: b:5000
+current_context:
+  server: a
+`), 0644))
+	assert.NoError(t, Load())
+
+	// Another process changes the file after it was loaded.
+	assert.NoError(t, updateConfigFile(path, func(c *Config) {
+		c.Contexts = append(c.Contexts, ContextRecord{Name: "other", Type: "plugin"})
+		c.CurrentContext["plugin"] = "other"
+	}))
+
+	RemoveContext("b")
+	assert.NoError(t, SetCurrentContext("a"))
+	assert.NoError(t, AddContext(&ContextRecord{Name: "c", Type: "server", Context: Context{Address: "c:5000"}}))
+	assert.NoError(t, Persist())
+
+	c, _, _, err := readConfigFile(path)
+	assert.NoError(t, err)
+	names := map[string]bool{}
+	for _, ctx := range c.Contexts {
+		names[ctx.Name] = true
+	}
+	assert.Equal(t, map[string]bool{"a": true, "c": true, "other": true}, names)
+	assert.Equal(t, map[string]string{"server": "a", "plugin": "other"}, c.CurrentContext)
+}
+
+func TestConfig_rebase(t *testing.T) {
+	a := ContextRecord{Name: "a", Type: "server", Context: Context{Address: "a:5000"}}
+	b := ContextRecord{Name: "b", Type: "server", Context: Context{Address: "b:5000"}}
+	modified := ContextRecord{Name: "a", Type: "server", Context: Context{Address: "a:6000"}}
+	other := ContextRecord{Name: "other", Type: "plugin"}
+
+	current := &Config{
+		Contexts:       []ContextRecord{a, b, other},
+		CurrentContext: map[string]string{"server": "b", "plugin": "other"},
+	}
+	current.rebase(
+		&Config{
+			Contexts:       []ContextRecord{a, b},
+			CurrentContext: map[string]string{"server": "b"},
+		},
+		&Config{
+			Contexts:       []ContextRecord{modified},
+			CurrentContext: map[string]string{},
+		},
+	)
+
+	assert.Equal(t, []ContextRecord{modified, other}, current.Contexts)
+	assert.Equal(t, map[string]string{"plugin": "other"}, current.CurrentContext)
+}
+
+func TestConfig_equal(t *testing.T) {
+	a := ContextRecord{Name: "a", Type: "server"}
+	b := ContextRecord{Name: "b", Type: "plugin"}
+
+	assert.True(t, (&Config{Contexts: []ContextRecord{a, b}}).equal(&Config{Contexts: []ContextRecord{b, a}}))
+	assert.False(t, (&Config{Contexts: []ContextRecord{a}}).equal(&Config{Contexts: []ContextRecord{a, b}}))
+	assert.False(t, (&Config{CurrentContext: map[string]string{"server": "a"}}).equal(&Config{}))
+}
+
+func TestLoadPersist_debugLogRedacted(t *testing.T) {
+	path := setupConfigFile(t)
+	assert.NoError(t, os.WriteFile(path, []byte(`
+contexts:
+- name: a
+  type: server
+  context:
+    address: localhost:5000
+    token: secret-token
+    headers:
+      X-Api-Key: secret-header
+`), 0644))
+
+	var buf bytes.Buffer
+	level := log.GetLevel()
+	log.SetOutput(&buf)
+	log.SetLevel(log.DebugLevel)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		log.SetLevel(level)
+	}()
+
+	assert.NoError(t, Load())
+	assert.NoError(t, AddContext(&ContextRecord{
+		Name:    "b",
+		Type:    "server",
+		Context: Context{Address: "localhost:5001", Token: "other-token"},
+	}))
+	assert.NoError(t, Persist())
+
+	assert.Contains(t, buf.String(), "contexts=\"[a b]\"")
+	assert.NotContains(t, buf.String(), "secret-token")
+	assert.NotContains(t, buf.String(), "secret-header")
+	assert.NotContains(t, buf.String(), "other-token")
+}
@@ -0,0 +1,243 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// labelChars are the characters, other than letters and digits, which may
+// be used within a label key or value. Keys may also contain '/', e.g. for
+// a prefix such as vapor.io/site.
+const labelChars = "-_."
+
+// isLabelRune checks whether the rune may be used in a label key or value.
+func isLabelRune(r rune, key bool) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		strings.ContainsRune(labelChars, r) || key && r == '/'
+}
+
+// validateLabel checks that the key and value of a label are well-formed.
+// Keys must not be empty; values may be. Both are limited to letters,
+// digits and '-', '_' and '.', so that they can be used in a selector.
+func validateLabel(key, value string) error {
+	if key == "" {
+		return errors.New("an empty label key")
+	}
+	for _, r := range key {
+		if !isLabelRune(r, true) {
+			return fmt.Errorf("an invalid label key '%s'", key)
+		}
+	}
+	for _, r := range value {
+		if !isLabelRune(r, false) {
+			return fmt.Errorf("an invalid value for label '%s'", key)
+		}
+	}
+	return nil
+}
+
+// cloneLabels gets a copy of the labels which shares no state with them.
+func cloneLabels(labels map[string]string) map[string]string {
+	if labels == nil {
+		return nil
+	}
+	cloned := make(map[string]string, len(labels))
+	for key, value := range labels {
+		cloned[key] = value
+	}
+	return cloned
+}
+
+// FormatLabels formats the labels as a comma separated list of key=value
+// pairs, sorted by key.
+func FormatLabels(labels map[string]string) string {
+	var pairs []string
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// selectorOp is the operator of a label selector requirement.
+type selectorOp string
+
+// The supported selector operators.
+const (
+	opEquals    selectorOp = "="
+	opNotEquals selectorOp = "!="
+	opExists    selectorOp = "exists"
+	opNotExists selectorOp = "!exists"
+)
+
+// requirement is a single requirement of a label selector, e.g. env=prod.
+type requirement struct {
+	key   string
+	op    selectorOp
+	value string
+}
+
+// matches checks whether the labels meet the requirement.
+func (r requirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.op {
+	case opEquals:
+		return ok && value == r.value
+	case opNotEquals:
+		return !ok || value != r.value
+	case opExists:
+		return ok
+	case opNotExists:
+		return !ok
+	}
+	return false
+}
+
+// Selector selects contexts by their labels. A context is selected if its
+// labels meet all of the requirements of the selector.
+type Selector []requirement
+
+// ParseSelector parses a label selector. A selector is a comma separated
+// list of requirements, each of which is one of:
+//
+//	key=value   the label is set to the value ('==' may also be used)
+//	key!=value  the label is not set to the value, or is not set at all
+//	key         the label is set
+//	!key        the label is not set
+func ParseSelector(selector string) (Selector, error) {
+	var s Selector
+	for _, term := range strings.Split(selector, ",") {
+		r, err := parseRequirement(strings.TrimSpace(term))
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector '%s': %v", selector, err)
+		}
+		s = append(s, r)
+	}
+	return s, nil
+}
+
+// parseRequirement parses a single term of a label selector.
+func parseRequirement(term string) (requirement, error) {
+	var r requirement
+	switch {
+	case term == "":
+		return r, errors.New("empty requirement")
+	case strings.Contains(term, "!="):
+		parts := strings.SplitN(term, "!=", 2)
+		r = requirement{key: parts[0], op: opNotEquals, value: parts[1]}
+	case strings.Contains(term, "=="):
+		parts := strings.SplitN(term, "==", 2)
+		r = requirement{key: parts[0], op: opEquals, value: parts[1]}
+	case strings.Contains(term, "="):
+		parts := strings.SplitN(term, "=", 2)
+		r = requirement{key: parts[0], op: opEquals, value: parts[1]}
+	case strings.HasPrefix(term, "!"):
+		r = requirement{key: strings.TrimPrefix(term, "!"), op: opNotExists}
+	default:
+		r = requirement{key: term, op: opExists}
+	}
+
+	r.key = strings.TrimSpace(r.key)
+	r.value = strings.TrimSpace(r.value)
+	if err := validateLabel(r.key, r.value); err != nil {
+		return r, fmt.Errorf("'%s' has %v", term, err)
+	}
+	return r, nil
+}
+
+// Matches checks whether the context's labels meet all of the requirements
+// of the selector.
+func (s Selector) Matches(ctx *ContextRecord) bool {
+	for _, r := range s {
+		if !r.matches(ctx.Labels) {
+			return false
+		}
+	}
+	return true
+}
+
+// LabelContext sets the given labels on a context and removes the labels
+// with the given keys from it.
+func (c *Config) LabelContext(name string, set map[string]string, remove []string) error {
+	ctx := c.GetContext(name)
+	if ctx == nil {
+		return fmt.Errorf("cannot label context '%s': no such context", name)
+	}
+
+	updated := *ctx
+	updated.Labels = cloneLabels(ctx.Labels)
+	if updated.Labels == nil {
+		updated.Labels = map[string]string{}
+	}
+	for _, key := range remove {
+		delete(updated.Labels, key)
+	}
+	for key, value := range set {
+		updated.Labels[key] = value
+	}
+	if len(updated.Labels) == 0 {
+		updated.Labels = nil
+	}
+	if err := ValidateContext(&updated); err != nil {
+		return err
+	}
+
+	for i := range c.Contexts {
+		if c.Contexts[i].Name == name {
+			c.Contexts[i] = updated
+		}
+	}
+	log.WithFields(log.Fields{
+		"context": name,
+		"labels":  FormatLabels(updated.Labels),
+	}).Debug("labeled context")
+	return nil
+}
+
+// LabelContext sets and removes labels on a context in the default
+// configuration.
+func LabelContext(name string, set map[string]string, remove []string) error {
+	return config.LabelContext(name, set, remove)
+}
+
+// SelectContexts gets the contexts of the given type which are selected by
+// the selector. If no type is given, contexts of any type are selected.
+func (c *Config) SelectContexts(selector Selector, ctxType string) []ContextRecord {
+	var selected []ContextRecord
+	for _, ctx := range c.Contexts {
+		ctx := ctx
+		if ctxType != "" && ctx.Type != ctxType {
+			continue
+		}
+		if selector.Matches(&ctx) {
+			selected = append(selected, ctx)
+		}
+	}
+	return selected
+}
+
+// SelectContexts gets the contexts of the given type in the default
+// configuration which are selected by the selector.
+func SelectContexts(selector Selector, ctxType string) []ContextRecord {
+	return config.SelectContexts(selector, ctxType)
+}
@@ -0,0 +1,220 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateLabel(t *testing.T) {
+	for _, tt := range []struct {
+		key   string
+		value string
+	}{
+		{"env", "prod"},
+		{"env", ""},
+		{"vapor.io/site", "atl-1"},
+		{"region_name", "us.east_1"},
+	} {
+		assert.NoError(t, validateLabel(tt.key, tt.value), tt.key)
+	}
+}
+
+func TestValidateLabel_errors(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		err   string
+	}{
+		{"", "prod", "an empty label key"},
+		{"env!", "prod", "an invalid label key 'env!'"},
+		{"site name", "atl", "an invalid label key 'site name'"},
+		{"env", "prod,lab", "an invalid value for label 'env'"},
+		{"env", "a/b", "an invalid value for label 'env'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			assert.EqualError(t, validateLabel(tt.key, tt.value), tt.err)
+		})
+	}
+}
+
+func TestValidateContext_invalidLabel(t *testing.T) {
+	err := ValidateContext(&ContextRecord{
+		Name:    "local",
+		Type:    "server",
+		Context: Context{Address: "localhost:5000"},
+		Labels:  map[string]string{"env": "prod lab"},
+	})
+	assert.EqualError(t, err, "context 'local' has an invalid value for label 'env'")
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, "", FormatLabels(nil))
+	assert.Equal(t, "env=prod,region=,site=atl", FormatLabels(map[string]string{
+		"site":   "atl",
+		"env":    "prod",
+		"region": "",
+	}))
+}
+
+func TestParseSelector(t *testing.T) {
+	s, err := ParseSelector("env=prod, site!=lab,region==us-east,tier,!legacy")
+	assert.NoError(t, err)
+	assert.Equal(t, Selector{
+		{key: "env", op: opEquals, value: "prod"},
+		{key: "site", op: opNotEquals, value: "lab"},
+		{key: "region", op: opEquals, value: "us-east"},
+		{key: "tier", op: opExists},
+		{key: "legacy", op: opNotExists},
+	}, s)
+}
+
+func TestParseSelector_errors(t *testing.T) {
+	tests := []struct {
+		selector string
+		err      string
+	}{
+		{"", "invalid label selector '': empty requirement"},
+		{"env=prod,", "invalid label selector 'env=prod,': empty requirement"},
+		{"=prod", "invalid label selector '=prod': '=prod' has an empty label key"},
+		{"!", "invalid label selector '!': '!' has an empty label key"},
+		{"env=prod=lab", "invalid label selector 'env=prod=lab': 'env=prod=lab' has an invalid value for label 'env'"},
+		{"!env=prod", "invalid label selector '!env=prod': '!env=prod' has an invalid label key '!env'"},
+		{"env in (prod)", "invalid label selector 'env in (prod)': 'env in (prod)' has an invalid label key 'env in (prod)'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			s, err := ParseSelector(tt.selector)
+			assert.EqualError(t, err, tt.err)
+			assert.Nil(t, s)
+		})
+	}
+}
+
+func TestSelector_Matches(t *testing.T) {
+	ctx := &ContextRecord{Labels: map[string]string{"env": "prod", "site": "atl", "tier": ""}}
+
+	tests := []struct {
+		selector string
+		matches  bool
+	}{
+		{"env=prod", true},
+		{"env=lab", false},
+		{"env=prod,site=atl", true},
+		{"env=prod,site=lab", false},
+		{"site!=lab", true},
+		{"site!=atl", false},
+		{"region!=us-east", true},
+		{"tier", true},
+		{"tier=", true},
+		{"region", false},
+		{"!region", true},
+		{"!env", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			s, err := ParseSelector(tt.selector)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.matches, s.Matches(ctx))
+		})
+	}
+}
+
+func TestSelector_Matches_noLabels(t *testing.T) {
+	s, err := ParseSelector("env!=prod,!site")
+	assert.NoError(t, err)
+	assert.True(t, s.Matches(&ContextRecord{}))
+
+	s, err = ParseSelector("env=prod")
+	assert.NoError(t, err)
+	assert.False(t, s.Matches(&ContextRecord{}))
+}
+
+func TestConfig_LabelContext(t *testing.T) {
+	c := newTestConfig()
+
+	assert.NoError(t, c.LabelContext("local", map[string]string{"env": "dev", "site": "atl"}, nil))
+	assert.Equal(t, map[string]string{"env": "dev", "site": "atl"}, c.GetContext("local").Labels)
+
+	assert.NoError(t, c.LabelContext("local", map[string]string{"env": "prod"}, []string{"site", "missing"}))
+	assert.Equal(t, map[string]string{"env": "prod"}, c.GetContext("local").Labels)
+	assert.Equal(t, "/tmp/home.yml", c.GetContext("local").Source)
+
+	// Removing the last label clears the labels.
+	assert.NoError(t, c.LabelContext("local", nil, []string{"env"}))
+	assert.Equal(t, newTestConfig(), c)
+}
+
+func TestConfig_LabelContext_errors(t *testing.T) {
+	tests := []struct {
+		name string
+		set  map[string]string
+		err  string
+	}{
+		{"missing", map[string]string{"env": "prod"}, "cannot label context 'missing': no such context"},
+		{"local", map[string]string{"env": "prod,lab"}, "context 'local' has an invalid value for label 'env'"},
+		{"local", map[string]string{"": "prod"}, "context 'local' has an empty label key"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			c := newTestConfig()
+			assert.EqualError(t, c.LabelContext(tt.name, tt.set, nil), tt.err)
+			assert.Equal(t, newTestConfig(), c)
+		})
+	}
+}
+
+func TestConfig_LabelContext_copied(t *testing.T) {
+	c := newTestConfig()
+	assert.NoError(t, c.LabelContext("local", map[string]string{"env": "dev"}, nil))
+	assert.NoError(t, c.CopyContext("local", "local-2"))
+	assert.NoError(t, c.LabelContext("local-2", map[string]string{"env": "prod"}, nil))
+
+	assert.Equal(t, "dev", c.GetContext("local").Labels["env"])
+	assert.Equal(t, "prod", c.GetContext("local-2").Labels["env"])
+}
+
+func TestConfig_SelectContexts(t *testing.T) {
+	c := newTestConfig()
+	assert.NoError(t, c.LabelContext("local", map[string]string{"env": "dev"}, nil))
+	assert.NoError(t, c.LabelContext("remote", map[string]string{"env": "prod"}, nil))
+	assert.NoError(t, c.LabelContext("emulator", map[string]string{"env": "dev"}, nil))
+
+	names := func(ctxs []ContextRecord) []string {
+		var names []string
+		for _, ctx := range ctxs {
+			names = append(names, ctx.Name)
+		}
+		return names
+	}
+
+	s, err := ParseSelector("env=dev")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"local", "emulator"}, names(c.SelectContexts(s, "")))
+	assert.Equal(t, []string{"emulator"}, names(c.SelectContexts(s, "plugin")))
+
+	s, err = ParseSelector("env=staging")
+	assert.NoError(t, err)
+	assert.Empty(t, c.SelectContexts(s, ""))
+}
@@ -0,0 +1,439 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"reflect"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// The names of the config file layers. See findLayers for how the files
+// for each layer are found.
+const (
+	LayerHome    = "home"
+	LayerProject = "project"
+	LayerEnv     = "env"
+	LayerFlag    = "flag"
+)
+
+// Layer describes a config file which is merged into the CLI configuration.
+type Layer struct {
+	// Name is the name of the layer (e.g. "home", "project").
+	Name string
+
+	// Path is the path of the config file for the layer.
+	Path string
+
+	// Loaded is true if the config file existed and was loaded.
+	Loaded bool
+}
+
+// layer holds the state of a config file layer which is needed to write
+// changes back to it.
+type layer struct {
+	Layer
+
+	// shadowed holds the contexts in the layer which are hidden by contexts
+	// with the same name in a higher precedence layer. They are not part of
+	// the merged config, but are kept so they are persisted unchanged.
+	shadowed []ContextRecord
+
+	// currentContext holds the current contexts set in the layer.
+	currentContext map[string]string
+
+	// base holds the config for the layer as it was last loaded or persisted.
+	// Only the changes made since then are persisted.
+	base *Config
+
+	// version holds the schema version of the config file. A file with an
+	// older schema version is migrated by Migrate.
+	version int
+}
+
+func newLayer(name, path string) *layer {
+	return &layer{
+		Layer: Layer{
+			Name: name,
+			Path: path,
+		},
+		currentContext: map[string]string{},
+		version:        CurrentVersion,
+	}
+}
+
+// layers holds the config file layers which were loaded, ordered from lowest
+// to highest precedence.
+var layers []*layer
+
+// Layers gets the config file layers, ordered from lowest to highest
+// precedence.
+func Layers() ([]Layer, error) {
+	found, err := getLayers()
+	if err != nil {
+		return nil, err
+	}
+	var info []Layer
+	for _, l := range found {
+		info = append(info, l.Layer)
+	}
+	return info, nil
+}
+
+// WritePath gets the path of the file which new contexts are persisted to.
+// This is the file for the highest precedence layer.
+func WritePath() (string, error) {
+	found, err := getLayers()
+	if err != nil {
+		return "", err
+	}
+	return found[len(found)-1].Path, nil
+}
+
+// SourceLayer gets the name of the layer the context is persisted to. If
+// the config has not been loaded, an empty string is returned.
+func SourceLayer(ctx *ContextRecord) string {
+	if len(layers) == 0 {
+		return ""
+	}
+	return layerFor(ctx.Source).Name
+}
+
+// Unload purges the configuration and forgets the config files it was
+// loaded from, as if it had never been loaded.
+func Unload() {
+	Purge()
+	layers = nil
+}
+
+// getLayers gets the loaded layers, or finds them if the config has not
+// been loaded.
+func getLayers() ([]*layer, error) {
+	if len(layers) != 0 {
+		return layers, nil
+	}
+	return findLayers()
+}
+
+// layerFor gets the loaded layer for the config file path. If there is no
+// such layer, the highest precedence layer is used.
+func layerFor(path string) *layer {
+	for _, l := range layers {
+		if l.Path == path {
+			return l
+		}
+	}
+	return layers[len(layers)-1]
+}
+
+// Load loads the configuration for the CLI, merging the config files for
+// each layer. If no configuration file can be found, this will load a new
+// empty Config instance.
+//
+// Contexts are keyed by name. If more than one layer has a context with the
+// same name, the one from the highest precedence layer is used. The same
+// applies to the current context for each context type.
+func Load() error {
+	log.Debug("loading cli configuration")
+	found, err := findLayers()
+	if err != nil {
+		return err
+	}
+
+	merged := Config{
+		Version:        CurrentVersion,
+		Contexts:       []ContextRecord{},
+		CurrentContext: map[string]string{},
+	}
+	layers = found
+	for _, l := range layers {
+		c, version, data, err := readConfigFile(l.Path)
+		if err != nil {
+			return err
+		}
+		l.Loaded = data != nil
+		if !l.Loaded {
+			log.WithField("file", l.Path).Debug("config file not found")
+			continue
+		}
+		l.version = version
+		if version < CurrentVersion {
+			log.WithFields(log.Fields{
+				"file":    l.Path,
+				"version": version,
+			}).Debug("config file will be migrated when it is next changed")
+		}
+		for _, field := range unknownFields(data) {
+			log.Warnf("ignoring unknown field '%s' in %s; it is dropped if the file is changed", field, l.Path)
+		}
+		merged.merge(l, c)
+	}
+	config = merged
+
+	for l, c := range splitLayers() {
+		l.base = c
+	}
+
+	// Contexts may hold credentials, so only their names are logged.
+	log.WithFields(log.Fields{
+		"contexts": config.contextNames(),
+		"current":  config.CurrentContext,
+	}).Debug("unmarshaled config")
+	return nil
+}
+
+// merge merges the config loaded from the layer into the Config.
+func (c *Config) merge(l *layer, from *Config) {
+	for _, ctx := range from.Contexts {
+		ctx.Source = l.Path
+
+		shadowed := false
+		for i, existing := range c.Contexts {
+			if existing.Name == ctx.Name {
+				log.WithFields(log.Fields{
+					"name":  ctx.Name,
+					"layer": l.Name,
+				}).Debug("context overrides a lower precedence layer")
+				lower := layerFor(existing.Source)
+				lower.shadowed = append(lower.shadowed, existing)
+				c.Contexts[i] = ctx
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			c.Contexts = append(c.Contexts, ctx)
+		}
+	}
+
+	for ctxType, name := range from.CurrentContext {
+		l.currentContext[ctxType] = name
+		c.CurrentContext[ctxType] = name
+	}
+}
+
+// Persist saves the CLI configuration to disk, writing each context back
+// to the config file for the layer it came from. New contexts are written
+// to the highest precedence layer.
+//
+// A change to the current context for a type is written to the highest
+// precedence layer which already sets it, or to the highest precedence
+// layer if none do. Unsetting the current context for a type removes it
+// from all layers.
+//
+// Only layers which have changed since the config was loaded are written.
+// Their changes are applied to the current contents of the file while it is
+// locked, so changes made by other CLI processes in the meantime are not
+// lost. A file with an older schema version is migrated when it is written,
+// and is otherwise left as it is until Migrate is used.
+func Persist() error {
+	found, err := getLayers()
+	if err != nil {
+		return err
+	}
+	layers = found
+
+	for l, c := range splitLayers() {
+		base := l.base
+		if base == nil {
+			base = &Config{}
+		}
+		if base.equal(c) {
+			continue
+		}
+
+		log.WithFields(log.Fields{
+			"path":     l.Path,
+			"contexts": c.contextNames(),
+			"current":  c.CurrentContext,
+		}).Debug("persisting config")
+
+		err := updateConfigFile(l.Path, func(current *Config) {
+			current.rebase(base, c)
+		})
+		if err != nil {
+			return err
+		}
+		l.Loaded = true
+		l.version = CurrentVersion
+		l.base = c
+		l.currentContext = c.CurrentContext
+	}
+	log.Debug("cli configuration persisted")
+	return nil
+}
+
+// splitLayers splits the merged config into the config for each layer.
+func splitLayers() map[*layer]*Config {
+	files := map[*layer]*Config{}
+	for _, l := range layers {
+		current := map[string]string{}
+		for ctxType, name := range l.currentContext {
+			if _, ok := config.CurrentContext[ctxType]; ok {
+				current[ctxType] = name
+			}
+		}
+		files[l] = &Config{
+			Contexts:       []ContextRecord{},
+			CurrentContext: current,
+		}
+	}
+
+	for _, ctx := range config.Contexts {
+		l := layerFor(ctx.Source)
+		files[l].Contexts = append(files[l].Contexts, ctx)
+	}
+	for _, l := range layers {
+		files[l].Contexts = append(files[l].Contexts, l.shadowed...)
+	}
+
+	// A current context is written to the highest layer which already sets
+	// it for the type. That layer, or a layer below it, must define the
+	// context, since the layers above it are not always loaded (e.g. outside
+	// of a project directory). Otherwise, it is written to the layer of the
+	// context.
+	for ctxType, name := range config.CurrentContext {
+		idx := len(layers) - 1
+		for i := len(layers) - 1; i >= 0; i-- {
+			if _, ok := layers[i].currentContext[ctxType]; ok {
+				idx = i
+				break
+			}
+		}
+		target := layers[idx]
+		if !definedAtOrBelow(files, idx, name) {
+			if ctx := config.GetContext(name); ctx != nil {
+				target = layerFor(ctx.Source)
+			}
+		}
+		files[target].CurrentContext[ctxType] = name
+	}
+	return files
+}
+
+// definedAtOrBelow checks whether the file for the layer at the index, or
+// for any layer below it, defines the named context.
+func definedAtOrBelow(files map[*layer]*Config, idx int, name string) bool {
+	for i := idx; i >= 0; i-- {
+		for _, ctx := range files[layers[i]].Contexts {
+			if ctx.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// equal checks whether two configs have the same contexts and current
+// contexts. The order of the contexts does not matter.
+func (c *Config) equal(other *Config) bool {
+	if len(c.CurrentContext) != len(other.CurrentContext) {
+		return false
+	}
+	for ctxType, name := range c.CurrentContext {
+		if n, ok := other.CurrentContext[ctxType]; !ok || n != name {
+			return false
+		}
+	}
+
+	contexts := contextsByName(c.Contexts)
+	others := contextsByName(other.Contexts)
+	if len(c.Contexts) != len(other.Contexts) || len(contexts) != len(others) {
+		return false
+	}
+	for name, ctx := range contexts {
+		if o, ok := others[name]; !ok || !reflect.DeepEqual(o, ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+// rebase applies the changes made between the base and updated configs to
+// the Config. Contexts which were added or modified are added to or replaced
+// in the Config, and contexts which were removed are removed from it. The
+// same applies to the current context for each type.
+func (c *Config) rebase(base, updated *Config) {
+	bases := contextsByName(base.Contexts)
+	updates := contextsByName(updated.Contexts)
+
+	var contexts []ContextRecord
+	for _, ctx := range c.Contexts {
+		if _, ok := bases[ctx.Name]; ok {
+			if _, ok := updates[ctx.Name]; !ok {
+				continue
+			}
+		}
+		contexts = append(contexts, ctx)
+	}
+
+	for _, ctx := range updated.Contexts {
+		if b, ok := bases[ctx.Name]; ok && reflect.DeepEqual(b, ctx) {
+			continue
+		}
+		replaced := false
+		for i := range contexts {
+			if contexts[i].Name == ctx.Name {
+				contexts[i] = ctx
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			contexts = append(contexts, ctx)
+		}
+	}
+	if contexts == nil {
+		contexts = []ContextRecord{}
+	}
+	c.Contexts = contexts
+
+	if c.CurrentContext == nil {
+		c.CurrentContext = map[string]string{}
+	}
+	for _, m := range []map[string]string{base.CurrentContext, updated.CurrentContext} {
+		for ctxType := range m {
+			name, ok := updated.CurrentContext[ctxType]
+			if name == base.CurrentContext[ctxType] {
+				continue
+			}
+			if ok {
+				c.CurrentContext[ctxType] = name
+			} else {
+				delete(c.CurrentContext, ctxType)
+			}
+		}
+	}
+}
+
+// contextNames gets the names of the contexts in the Config.
+func (c *Config) contextNames() []string {
+	names := make([]string, 0, len(c.Contexts))
+	for _, ctx := range c.Contexts {
+		names = append(names, ctx.Name)
+	}
+	return names
+}
+
+// contextsByName indexes the contexts by name.
+func contextsByName(ctxs []ContextRecord) map[string]ContextRecord {
+	byName := map[string]ContextRecord{}
+	for _, ctx := range ctxs {
+		byName[ctx.Name] = ctx
+	}
+	return byName
+}
@@ -0,0 +1,243 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// CurrentVersion is the schema version of the config files written by the
+// CLI. It must be bumped, along with a new migration, whenever a change to
+// the Config would otherwise change how existing config files are read.
+const CurrentVersion = 1
+
+// migration upgrades a config file document from one schema version to
+// the next.
+type migration struct {
+	description string
+	migrate     func(doc map[string]interface{}) error
+}
+
+// unknownFieldPattern matches the error from decoding an unknown field
+// strictly, capturing the name of the field.
+var unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type`)
+
+// migrations holds the config file migrations, in order. The migration at
+// index i upgrades a document from schema version i to version i+1.
+//
+// Migrations operate on the raw YAML document rather than the Config, so
+// fields which have since been renamed or removed from the Config can still
+// be read and moved.
+var migrations = []migration{
+	{
+		// Version 0 config files pre-date schema versioning. They have the
+		// same layout as version 1, so only the version is added.
+		description: "add the config schema version",
+		migrate:     func(doc map[string]interface{}) error { return nil },
+	},
+}
+
+// Migration describes the migration of a config file to the current
+// schema version.
+type Migration struct {
+	Layer
+
+	// From is the schema version of the config file.
+	From int
+
+	// To is the schema version the config file is migrated to.
+	To int
+
+	// Before holds the contents of the config file prior to migration.
+	Before []byte
+
+	// After holds the contents of the config file after migration.
+	After []byte
+
+	// Backup is the path which the config file is backed up to when it
+	// is migrated.
+	Backup string
+}
+
+// Migrations gets the migrations needed to bring the loaded config files
+// up to the current schema version. The config files are not modified.
+func Migrations() ([]Migration, error) {
+	var pending []Migration
+	for _, l := range layers {
+		if !l.Loaded || l.version >= CurrentVersion {
+			continue
+		}
+
+		before, err := os.ReadFile(l.Path)
+		if err != nil {
+			return nil, err
+		}
+		c, from, err := decodeConfig(before)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %v", l.Path, err)
+		}
+		after, err := encodeConfig(c)
+		if err != nil {
+			return nil, err
+		}
+
+		pending = append(pending, Migration{
+			Layer:  l.Layer,
+			From:   from,
+			To:     CurrentVersion,
+			Before: before,
+			After:  after,
+			Backup: backupPath(l.Path, from),
+		})
+	}
+	return pending, nil
+}
+
+// Migrate migrates the loaded config files to the current schema version,
+// backing up each file before it is rewritten. The migrations which were
+// applied are returned.
+//
+// Config files are otherwise only migrated when a change to them is
+// persisted, so this is used to migrate them ahead of time.
+func Migrate() ([]Migration, error) {
+	pending, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range pending {
+		log.WithFields(log.Fields{
+			"path": m.Path,
+			"from": m.From,
+			"to":   m.To,
+		}).Debug("migrating config file")
+
+		if err := updateConfigFile(m.Path, func(*Config) {}); err != nil {
+			return nil, err
+		}
+		layerFor(m.Path).version = CurrentVersion
+	}
+	return pending, nil
+}
+
+// decodeConfig decodes the contents of a config file, migrating it to the
+// current schema version. The schema version of the data is returned along
+// with the Config.
+//
+// Unknown fields are ignored, so that a config file which has a field this
+// version of the CLI does not know about can still be used. They are dropped
+// if the file is rewritten; see unknownFields.
+func decodeConfig(data []byte) (*Config, int, error) {
+	migrated, version, err := migrateConfig(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	c := &Config{}
+	if err := yaml.Unmarshal(migrated, c); err != nil {
+		return nil, 0, err
+	}
+	if c.Contexts == nil {
+		c.Contexts = []ContextRecord{}
+	}
+	if c.CurrentContext == nil {
+		c.CurrentContext = map[string]string{}
+	}
+	return c, version, nil
+}
+
+// unknownFields gets the names of the fields in the contents of a config
+// file which do not map to a field of the Config.
+func unknownFields(data []byte) []string {
+	migrated, _, err := migrateConfig(data)
+	if err != nil {
+		return nil
+	}
+
+	var unknown []string
+	var typeErr *yaml.TypeError
+	if err := yaml.UnmarshalStrict(migrated, &Config{}); errors.As(err, &typeErr) {
+		for _, e := range typeErr.Errors {
+			if m := unknownFieldPattern.FindStringSubmatch(e); m != nil {
+				unknown = append(unknown, m[1])
+			}
+		}
+	}
+	return unknown
+}
+
+// migrateConfig migrates the contents of a config file to the current
+// schema version. The schema version of the original data is returned along
+// with the migrated contents.
+func migrateConfig(data []byte) ([]byte, int, error) {
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, 0, err
+	}
+	if doc == nil {
+		doc = map[string]interface{}{}
+	}
+
+	version := 0
+	if v, ok := doc["version"]; ok {
+		if version, ok = v.(int); !ok {
+			return nil, 0, fmt.Errorf("invalid config schema version: %v", v)
+		}
+	}
+	if version < 0 || version > CurrentVersion {
+		return nil, 0, fmt.Errorf(
+			"unsupported config schema version %d (this version of the CLI supports up to version %d)",
+			version, CurrentVersion,
+		)
+	}
+
+	for v := version; v < CurrentVersion; v++ {
+		log.WithFields(log.Fields{
+			"from":      v,
+			"to":        v + 1,
+			"migration": migrations[v].description,
+		}).Debug("applying config migration")
+		if err := migrations[v].migrate(doc); err != nil {
+			return nil, 0, fmt.Errorf("failed to migrate config from version %d: %v", v, err)
+		}
+	}
+	doc["version"] = CurrentVersion
+
+	migrated, err := yaml.Marshal(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return migrated, version, nil
+}
+
+// encodeConfig encodes the Config as the contents of a config file at the
+// current schema version.
+func encodeConfig(c *Config) ([]byte, error) {
+	c.Version = CurrentVersion
+	return yaml.Marshal(c)
+}
+
+// backupPath gets the path which a config file with the given schema
+// version is backed up to before it is migrated.
+func backupPath(path string, version int) string {
+	return fmt.Sprintf("%s.v%d.bak", path, version)
+}
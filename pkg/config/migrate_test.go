@@ -0,0 +1,226 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// legacyConfig is a config file written prior to schema versioning.
+const legacyConfig = `contexts:
+- name: test
+  type: server
+  context:
+    address: localhost:5000
+    client_cert: ""
+current_context:
+  server: test
+`
+
+func TestMigrations_version(t *testing.T) {
+	// There must be a migration to each schema version.
+	assert.Len(t, migrations, CurrentVersion)
+}
+
+func TestDecodeConfig_legacy(t *testing.T) {
+	c, version, err := decodeConfig([]byte(legacyConfig))
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.Equal(t, CurrentVersion, c.Version)
+	assert.Equal(t, []ContextRecord{
+		{Name: "test", Type: "server", Context: Context{Address: "localhost:5000"}},
+	}, c.Contexts)
+	assert.Equal(t, map[string]string{"server": "test"}, c.CurrentContext)
+}
+
+func TestDecodeConfig_current(t *testing.T) {
+	c, version, err := decodeConfig([]byte("version: 1\ncontexts: []\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, version)
+	assert.Equal(t, []ContextRecord{}, c.Contexts)
+	assert.Equal(t, map[string]string{}, c.CurrentContext)
+}
+
+func TestDecodeConfig_empty(t *testing.T) {
+	c, version, err := decodeConfig(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+	assert.Equal(t, []ContextRecord{}, c.Contexts)
+}
+
+func TestDecodeConfig_error(t *testing.T) {
+	tests := []struct {
+		desc string
+		data string
+		err  string
+	}{
+		{
+			desc: "newer version",
+			data: "version: 99\ncontexts: []\n",
+			err:  "unsupported config schema version 99 (this version of the CLI supports up to version 1)",
+		},
+		{
+			desc: "negative version",
+			data: "version: -1\n",
+			err:  "unsupported config schema version -1 (this version of the CLI supports up to version 1)",
+		},
+		{
+			desc: "invalid version",
+			data: "version: one\n",
+			err:  "invalid config schema version: one",
+		},
+		{
+			desc: "invalid yaml",
+			data: "contexts: [",
+			err:  "yaml:",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, _, err := decodeConfig([]byte(test.data))
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestDecodeConfig_unknownFields(t *testing.T) {
+	data := []byte("version: 1\ncontexts:\n- name: test\n  annotations: {}\n")
+
+	c, _, err := decodeConfig(data)
+	assert.NoError(t, err)
+	assert.Equal(t, []ContextRecord{{Name: "test"}}, c.Contexts)
+
+	assert.Equal(t, []string{"annotations"}, unknownFields(data))
+	assert.Empty(t, unknownFields([]byte(legacyConfig)))
+}
+
+func TestLoad_unknownFields(t *testing.T) {
+	path := setupConfigFile(t)
+	data := []byte("version: 1\ncontexts:\n- name: test\n  type: server\n  annotations: {}\n  context:\n    address: localhost:5000\n")
+	assert.NoError(t, os.WriteFile(path, data, 0644))
+
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	// The config still loads, with a warning which names the file and field.
+	assert.NoError(t, Load())
+	assert.Len(t, GetContexts(), 1)
+	assert.Contains(t, buf.String(), "level=warning")
+	assert.Contains(t, buf.String(), path)
+	assert.Contains(t, buf.String(), "'annotations'")
+
+	// The file is not rewritten unless it changes.
+	assert.NoError(t, Persist())
+	written, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, data, written)
+}
+
+func TestLoad_newerVersion(t *testing.T) {
+	path := setupConfigFile(t)
+	assert.NoError(t, os.WriteFile(path, []byte("version: 99\n"), 0644))
+
+	err := Load()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), path)
+}
+
+func TestPersist_migrates(t *testing.T) {
+	path := setupConfigFile(t)
+	assert.NoError(t, os.WriteFile(path, []byte(legacyConfig), 0644))
+
+	assert.NoError(t, Load())
+	assert.Len(t, GetContexts(), 1)
+
+	// The file is not migrated if the config has not changed.
+	assert.NoError(t, Persist())
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(data))
+	_, err = os.Stat(backupPath(path, 0))
+	assert.True(t, os.IsNotExist(err))
+
+	// It is migrated, and backed up, once a change is written to it.
+	assert.NoError(t, AddContext(&ContextRecord{
+		Name:    "other",
+		Type:    "server",
+		Context: Context{Address: "localhost:5001"},
+	}))
+	assert.NoError(t, Persist())
+
+	backup, err := os.ReadFile(backupPath(path, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(backup))
+
+	c, version, _, err := readConfigFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, CurrentVersion, version)
+	assert.Len(t, c.Contexts, 2)
+}
+
+func TestMigrations(t *testing.T) {
+	path := setupConfigFile(t)
+	assert.NoError(t, os.WriteFile(path, []byte(legacyConfig), 0644))
+	assert.NoError(t, Load())
+
+	pending, err := Migrations()
+	assert.NoError(t, err)
+	assert.Len(t, pending, 1)
+	assert.Equal(t, path, pending[0].Path)
+	assert.Equal(t, 0, pending[0].From)
+	assert.Equal(t, CurrentVersion, pending[0].To)
+	assert.Equal(t, legacyConfig, string(pending[0].Before))
+	assert.Contains(t, string(pending[0].After), "version: 1\n")
+	assert.Equal(t, path+".v0.bak", pending[0].Backup)
+
+	// Getting the migrations does not modify the file.
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(data))
+	_, err = os.Stat(pending[0].Backup)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestMigrate(t *testing.T) {
+	path := setupConfigFile(t)
+	assert.NoError(t, os.WriteFile(path, []byte(legacyConfig), 0644))
+	assert.NoError(t, Load())
+
+	migrated, err := Migrate()
+	assert.NoError(t, err)
+	assert.Len(t, migrated, 1)
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, string(migrated[0].After), string(data))
+	backup, err := os.ReadFile(migrated[0].Backup)
+	assert.NoError(t, err)
+	assert.Equal(t, legacyConfig, string(backup))
+
+	// There is nothing left to migrate.
+	pending, err := Migrations()
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+}
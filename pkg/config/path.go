@@ -0,0 +1,147 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// EnvConfigFile is the environment variable which may be set to the path
+// of the CLI config file. Multiple files may be given, separated by the OS
+// path list separator (':' on Linux and macOS), with earlier files taking
+// precedence over later ones.
+const EnvConfigFile = "SYNSE_CONFIG"
+
+// xdgConfigFile is the path of the config file relative to the XDG config
+// directory.
+var xdgConfigFile = filepath.Join("synse", "config.yml")
+
+// configPath is the path of the config file set explicitly via SetPath.
+var configPath string
+
+// SetPath sets the path of the config file to load and persist. It takes
+// precedence over the SYNSE_CONFIG environment variable. An empty path
+// clears the setting.
+func SetPath(path string) {
+	configPath = path
+}
+
+// findLayers gets the config file layers, ordered from lowest to highest
+// precedence:
+//
+//   - home: $XDG_CONFIG_HOME/synse/config.yml (default ~/.config/synse/config.yml)
+//     if it exists, otherwise ~/.synse.yml
+//   - project: ./.synse.yml, if it exists
+//   - env: the files in SYNSE_CONFIG
+//   - flag: the file set via SetPath, which replaces the env layer
+//
+// Files set via the flag or env are used whether or not they exist, so
+// they are created when the config is persisted.
+func findLayers() ([]*layer, error) {
+	var layers []*layer
+
+	home, err := homeConfigFile()
+	if err != nil {
+		return nil, err
+	}
+	layers = append(layers, newLayer(LayerHome, home))
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	project := filepath.Join(wd, configFile)
+	if exists, err := fileExists(project); err != nil {
+		return nil, err
+	} else if exists {
+		layers = append(layers, newLayer(LayerProject, project))
+	}
+
+	var name string
+	var paths []string
+	if configPath != "" {
+		name, paths = LayerFlag, []string{configPath}
+	} else if env := os.Getenv(EnvConfigFile); env != "" {
+		name, paths = LayerEnv, filepath.SplitList(env)
+	}
+	// Files given earlier in the list take precedence, so they are added last.
+	for i := len(paths) - 1; i >= 0; i-- {
+		if paths[i] == "" {
+			continue
+		}
+		path, err := filepath.Abs(paths[i])
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, newLayer(name, path))
+	}
+
+	// A file may be found via more than one layer (e.g. SYNSE_CONFIG set to
+	// the home config). It is only loaded once, at its highest precedence.
+	seen := map[string]bool{}
+	var unique []*layer
+	for i := len(layers) - 1; i >= 0; i-- {
+		if !seen[layers[i].Path] {
+			seen[layers[i].Path] = true
+			unique = append([]*layer{layers[i]}, unique...)
+		}
+	}
+	return unique, nil
+}
+
+// homeConfigFile gets the path of the config file in the user's home
+// directory. The XDG config file is preferred if it exists.
+func homeConfigFile() (string, error) {
+	if dir := xdgConfigHome(); dir != "" {
+		path := filepath.Join(dir, xdgConfigFile)
+		if exists, err := fileExists(path); err != nil {
+			return "", err
+		} else if exists {
+			return path, nil
+		}
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, configFile), nil
+}
+
+// xdgConfigHome gets the XDG base directory for user config files.
+func xdgConfigHome() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return dir
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(home, ".config")
+	}
+	return ""
+}
+
+// fileExists checks whether a regular file exists at the path.
+func fileExists(path string) (bool, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !info.IsDir(), nil
+}
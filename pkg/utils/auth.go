@@ -0,0 +1,74 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// authHeaders gets the headers which authenticate the HTTP and WebSocket
+// requests made to the server of the given context. The static headers of
+// the context are overridden by its bearer token, and both are overridden
+// by the credentials from its credential helper.
+func authHeaders(ctx *config.ContextRecord) (http.Header, error) {
+	headers := http.Header{}
+	for name, value := range ctx.Context.Headers {
+		headers.Set(name, value)
+	}
+	if ctx.Context.Token != "" {
+		headers.Set("Authorization", "Bearer "+ctx.Context.Token)
+	}
+
+	if ctx.Context.CredentialHelper != "" {
+		creds, err := getCredentials(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range creds.Headers {
+			headers.Set(name, value)
+		}
+		if creds.Username != "" || creds.Password != "" {
+			basic := base64.StdEncoding.EncodeToString([]byte(creds.Username + ":" + creds.Password))
+			headers.Set("Authorization", "Basic "+basic)
+		}
+		if creds.Token != "" {
+			headers.Set("Authorization", "Bearer "+creds.Token)
+		}
+	}
+
+	if len(headers) != 0 {
+		log.WithFields(log.Fields{
+			"context": ctx.Name,
+			"headers": redactHeaders(headers),
+		}).Debug("auth: with request headers")
+	}
+	return headers, nil
+}
+
+// redactHeaders gets the names of the headers with their values redacted,
+// so that they may be logged.
+func redactHeaders(headers http.Header) map[string]string {
+	redacted := make(map[string]string, len(headers))
+	for name := range headers {
+		redacted[name] = config.RedactedValue
+	}
+	return redacted
+}
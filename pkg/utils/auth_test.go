@@ -0,0 +1,219 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+func TestAuthHeaders_none(t *testing.T) {
+	headers, err := authHeaders(&config.ContextRecord{
+		Name:    "testctx",
+		Type:    "server",
+		Context: config.Context{Address: "localhost:5000"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, headers)
+}
+
+func TestAuthHeaders_static(t *testing.T) {
+	headers, err := authHeaders(&config.ContextRecord{
+		Name: "testctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+			Headers: map[string]string{"x-api-key": "123", "Authorization": "Basic abc"},
+			Token:   "abc",
+		},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.Header{
+		"X-Api-Key":     {"123"},
+		"Authorization": {"Bearer abc"},
+	}, headers)
+}
+
+func TestAuthHeaders_credentialHelper(t *testing.T) {
+	test.UseCacheDir(t, &userCacheDir)
+
+	for _, tt := range []struct {
+		name     string
+		output   string
+		expected http.Header
+	}{
+		{
+			name:   "token",
+			output: "token=def\n",
+			expected: http.Header{
+				"X-Api-Key":     {"123"},
+				"Authorization": {"Bearer def"},
+			},
+		},
+		{
+			name:   "basic auth",
+			output: "username=user\npassword=pass\n",
+			expected: http.Header{
+				"X-Api-Key":     {"123"},
+				"Authorization": {"Basic dXNlcjpwYXNz"},
+			},
+		},
+		{
+			name:   "headers",
+			output: "header=X-Api-Key: 456\nheader=X-Tenant: vapor\n",
+			expected: http.Header{
+				"X-Api-Key":     {"456"},
+				"X-Tenant":      {"vapor"},
+				"Authorization": {"Bearer abc"},
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			headers, err := authHeaders(&config.ContextRecord{
+				Name: "testctx",
+				Type: "server",
+				Context: config.Context{
+					Address:          "localhost:5000",
+					Headers:          map[string]string{"X-Api-Key": "123"},
+					Token:            "abc",
+					CredentialHelper: newCredentialHelper(t, tt.output).command(),
+				},
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, headers)
+		})
+	}
+}
+
+func TestAuthHeaders_credentialHelperFails(t *testing.T) {
+	test.UseCacheDir(t, &userCacheDir)
+
+	headers, err := authHeaders(&config.ContextRecord{
+		Name: "testctx",
+		Type: "server",
+		Context: config.Context{
+			Address:          "localhost:5000",
+			CredentialHelper: "false",
+		},
+	})
+	assert.Nil(t, headers)
+	assert.EqualError(t, err, "credential helper 'false' failed: exit status 1")
+}
+
+func TestClientFactory_HTTP_authHeaders(t *testing.T) {
+	defer config.Purge()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" || r.Header.Get("X-Api-Key") != "123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","timestamp":"2019-04-22T13:30:00Z"}`))
+	}))
+	defer server.Close()
+
+	addTLSContext(t, "server", config.Context{
+		Address: strings.TrimPrefix(server.URL, "http://"),
+		Headers: map[string]string{"X-Api-Key": "123"},
+		Token:   "abc",
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	s, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", s.Status)
+}
+
+func TestClientFactory_HTTP_credentialHelperFails(t *testing.T) {
+	defer config.Purge()
+	test.UseCacheDir(t, &userCacheDir)
+
+	addTLSContext(t, "server", config.Context{
+		Address:          "localhost:5000",
+		CredentialHelper: "false",
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.Nil(t, client)
+	assert.EqualError(t, err, "credential helper 'false' failed: exit status 1")
+}
+
+func TestClientFactory_WebSocket_authHeaders(t *testing.T) {
+	defer config.Purge()
+
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer abc" || r.Header.Get("X-Api-Key") != "123" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	// The headers must not replace those of the websocket handshake.
+	addTLSContext(t, "server", config.Context{
+		Address: strings.TrimPrefix(server.URL, "http://"),
+		Headers: map[string]string{"X-Api-Key": "123", "Upgrade": "h2c"},
+		Token:   "abc",
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).WebSocket()
+	assert.NoError(t, err)
+	assert.NoError(t, client.Open())
+}
+
+func TestClientFactory_WebSocket_noAuthHeaders(t *testing.T) {
+	defer config.Purge()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	addTLSContext(t, "server", config.Context{
+		Address: strings.TrimPrefix(server.URL, "http://"),
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).WebSocket()
+	assert.NoError(t, err)
+	assert.Error(t, client.Open())
+}
+
+func TestRedactHeaders(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		"Authorization": "REDACTED",
+		"X-Api-Key":     "REDACTED",
+	}, redactHeaders(http.Header{
+		"Authorization": {"Bearer abc"},
+		"X-Api-Key":     {"123"},
+	}))
+}
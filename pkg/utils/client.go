@@ -0,0 +1,117 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+)
+
+// Errors for failures to resolve the context a client is created for.
+var (
+	ErrNoCurrentCtx = errors.New("no current context")
+	ErrInvalidCtx   = errors.New("specified context does not exist")
+	ErrWrongCtxType = errors.New("specified context has the wrong type")
+)
+
+// ClientOptions holds the command line options which affect how a client
+// is created. Options which are set take precedence over the settings
+// stored with the context.
+type ClientOptions struct {
+	// Context is the name of the context to use. If empty, the current
+	// context of the appropriate type is used.
+	Context string
+
+	// TLSCert overrides the client cert configured for the context.
+	TLSCert string
+
+	// Record, if set, is the context to use instead of one resolved from
+	// the CLI configuration. This allows clients to be created for contexts
+	// which are not (yet) part of the configuration.
+	Record *config.ContextRecord
+
+	// Timeout is the time limit for HTTP requests. If zero, the client's
+	// default is used.
+	Timeout time.Duration
+
+	// NoRetry disables the retry of failed HTTP requests.
+	NoRetry bool
+}
+
+// contextClients is the clients.Factory which creates clients based on
+// the contexts in the CLI configuration.
+type contextClients struct {
+	opts ClientOptions
+}
+
+// NewClientFactory creates a clients.Factory which resolves contexts
+// from the CLI configuration and applies the given options to them.
+func NewClientFactory(opts ClientOptions) clients.Factory {
+	return &contextClients{opts: opts}
+}
+
+// resolve gets the context for a client of the given transport, applying
+// any options which override its settings.
+func (f *contextClients) resolve(ctxType, transport string) (*config.ContextRecord, error) {
+	var record *config.ContextRecord
+	if f.opts.Record != nil {
+		if f.opts.Record.Type != ctxType {
+			return nil, fmt.Errorf("failed creating %s %s client: %w: '%s' is a %s context", ctxType, transport, ErrWrongCtxType, f.opts.Record.Name, f.opts.Record.Type)
+		}
+		resolved := *f.opts.Record
+		record = &resolved
+	} else {
+		var err error
+		if record, err = ResolveContext(f.opts.Context, ctxType); err != nil {
+			return nil, fmt.Errorf("failed creating %s %s client: %w", ctxType, transport, err)
+		}
+	}
+
+	if f.opts.TLSCert != "" {
+		record.Context.ClientCert = f.opts.TLSCert
+	}
+	return record, nil
+}
+
+// ResolveContext gets a copy of the named context, which must be of the
+// given type. If no name is given, the current context for the type is used,
+// unless an ephemeral context is set for it (see SetEphemeralContexts).
+// A named context always takes precedence over ephemeral contexts.
+func ResolveContext(name, ctxType string) (*config.ContextRecord, error) {
+	var record *config.ContextRecord
+	if name == "" {
+		record = currentContext(ctxType)
+		if record == nil {
+			return nil, fmt.Errorf("%w: no %s context is set", ErrNoCurrentCtx, ctxType)
+		}
+	} else {
+		record = config.GetContext(name)
+		if record == nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidCtx, name)
+		}
+		if record.Type != ctxType {
+			return nil, fmt.Errorf("%w: '%s' is a %s context, not a %s context", ErrWrongCtxType, name, record.Type, ctxType)
+		}
+	}
+
+	resolved := *record
+	return &resolved, nil
+}
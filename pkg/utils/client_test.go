@@ -0,0 +1,153 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+func TestResolveContext_current(t *testing.T) {
+	defer config.Purge()
+	err := config.AddContext(&config.ContextRecord{
+		Name: "testctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, config.SetCurrentContext("testctx"))
+
+	ctx, err := ResolveContext("", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "testctx", ctx.Name)
+
+	_, err = ResolveContext("", "plugin")
+	assert.ErrorIs(t, err, ErrNoCurrentCtx)
+}
+
+func TestResolveContext_returnsCopy(t *testing.T) {
+	defer config.Purge()
+	err := config.AddContext(&config.ContextRecord{
+		Name: "testctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	})
+	assert.NoError(t, err)
+
+	ctx, err := ResolveContext("testctx", "server")
+	assert.NoError(t, err)
+	ctx.Context.ClientCert = "override.pem"
+
+	assert.Empty(t, config.GetContext("testctx").Context.ClientCert)
+}
+
+func TestResolveContext_errors(t *testing.T) {
+	defer config.Purge()
+	err := config.AddContext(&config.ContextRecord{
+		Name: "testctx",
+		Type: "plugin",
+		Context: config.Context{
+			Address: "localhost:5001",
+		},
+	})
+	assert.NoError(t, err)
+
+	_, err = ResolveContext("other", "plugin")
+	assert.ErrorIs(t, err, ErrInvalidCtx)
+
+	_, err = ResolveContext("testctx", "server")
+	assert.ErrorIs(t, err, ErrWrongCtxType)
+	assert.EqualError(t, err, "specified context has the wrong type: 'testctx' is a plugin context, not a server context")
+}
+
+func TestClientFactory_tlsCertOverride(t *testing.T) {
+	defer config.Purge()
+	err := config.AddContext(&config.ContextRecord{
+		Name: "testctx",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	})
+	assert.NoError(t, err)
+
+	// The cert override applies to every client type the factory creates.
+	factory := NewClientFactory(ClientOptions{Context: "testctx", TLSCert: "/tmp/test/does-not-exist.pem"})
+
+	client, err := factory.HTTP()
+	assert.Nil(t, client)
+	assert.Error(t, err)
+
+	client, err = factory.WebSocket()
+	assert.Nil(t, client)
+	assert.Error(t, err)
+}
+
+func TestClientFactory_errorMessage(t *testing.T) {
+	_, err := NewClientFactory(ClientOptions{}).WebSocket()
+	assert.EqualError(t, err, "failed creating server WebSocket client: no current context: no server context is set")
+}
+
+func TestClientFactory_record(t *testing.T) {
+	defer config.Purge()
+
+	// The record is used without being added to the configuration.
+	factory := NewClientFactory(ClientOptions{Record: &config.ContextRecord{
+		Name: "adhoc",
+		Type: "server",
+		Context: config.Context{
+			Address: "localhost:5000",
+		},
+	}})
+
+	client, err := factory.HTTP()
+	assert.NoError(t, err)
+	assert.NotNil(t, client)
+	assert.Empty(t, config.GetContexts())
+
+	_, _, err = factory.GRPC()
+	assert.ErrorIs(t, err, ErrWrongCtxType)
+	assert.EqualError(t, err, "failed creating plugin gRPC client: specified context has the wrong type: 'adhoc' is a server context")
+}
+
+func TestClientFactory_noRetry(t *testing.T) {
+	factory := NewClientFactory(ClientOptions{
+		Record: &config.ContextRecord{
+			Name: "adhoc",
+			Type: "server",
+			Context: config.Context{
+				Address: "localhost:5000",
+			},
+		},
+		Timeout: 100 * time.Millisecond,
+		NoRetry: true,
+	})
+
+	client, err := factory.HTTP()
+	assert.NoError(t, err)
+	rc := client.(*httpServerClient).client
+	assert.Equal(t, 0, rc.RetryCount)
+	assert.Equal(t, 100*time.Millisecond, rc.GetClient().Timeout)
+	assert.Equal(t, uint(0), client.GetOptions().HTTP.Retry.Count)
+}
@@ -0,0 +1,91 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"context"
+
+	"github.com/spf13/cobra"
+	"github.com/vapor-ware/synse-client-go/synse"
+	synsegrpc "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+// Factory creates the clients which commands use to communicate with
+// Synse Server and Synse plugins.
+//
+// Commands get a Factory rather than creating clients directly, so tests
+// can provide fake clients by attaching a Factory to the command context.
+type Factory interface {
+	// HTTP creates a Synse Server HTTP client.
+	HTTP() (synse.Client, error)
+
+	// WebSocket creates a Synse Server WebSocket client.
+	WebSocket() (synse.Client, error)
+
+	// GRPC creates a connection and client for a Synse plugin.
+	GRPC() (*grpc.ClientConn, synsegrpc.V3PluginClient, error)
+}
+
+type factoryKey struct{}
+
+// WithFactory returns a copy of the context which carries the Factory.
+func WithFactory(ctx context.Context, factory Factory) context.Context {
+	return context.WithValue(ctx, factoryKey{}, factory)
+}
+
+// FromContext gets the Factory attached to the context, if any.
+func FromContext(ctx context.Context) (Factory, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	factory, ok := ctx.Value(factoryKey{}).(Factory)
+	return factory, ok
+}
+
+// FromCmd gets the Factory attached to the command context. If there is
+// none, the fallback Factory is used.
+func FromCmd(cmd *cobra.Command, fallback Factory) Factory {
+	if factory, ok := FromContext(cmd.Context()); ok {
+		return factory
+	}
+	return fallback
+}
+
+// ContextFactory is a Factory which can also create the Factory for a named
+// context. It is used for commands which are run against multiple contexts.
+type ContextFactory interface {
+	Factory
+
+	// ForContext gets the Factory for the named context.
+	ForContext(name string) Factory
+}
+
+// ForContext gets the Factory for the named context from the command
+// context. If the attached Factory is a ContextFactory, the Factory for the
+// context is used; any other attached Factory is used as-is. If there is no
+// attached Factory, the fallback Factory is used.
+func ForContext(cmd *cobra.Command, name string, fallback Factory) Factory {
+	factory, ok := FromContext(cmd.Context())
+	if !ok {
+		return fallback
+	}
+	if f, ok := factory.(ContextFactory); ok {
+		return f.ForContext(name)
+	}
+	return factory
+}
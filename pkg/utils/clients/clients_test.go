@@ -0,0 +1,101 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package clients
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-client-go/synse"
+	synsegrpc "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+)
+
+type testFactory struct {
+	name string
+}
+
+func (f *testFactory) HTTP() (synse.Client, error)      { return nil, nil }
+func (f *testFactory) WebSocket() (synse.Client, error) { return nil, nil }
+func (f *testFactory) GRPC() (*grpc.ClientConn, synsegrpc.V3PluginClient, error) {
+	return nil, nil, nil
+}
+
+type testContextFactory struct {
+	testFactory
+}
+
+func (f *testContextFactory) ForContext(name string) Factory {
+	return &testFactory{name: name}
+}
+
+func TestFromContext(t *testing.T) {
+	factory := &testFactory{name: "test"}
+
+	f, ok := FromContext(WithFactory(context.Background(), factory))
+	assert.True(t, ok)
+	assert.Equal(t, factory, f)
+}
+
+func TestFromContext_notSet(t *testing.T) {
+	f, ok := FromContext(context.Background())
+	assert.False(t, ok)
+	assert.Nil(t, f)
+}
+
+func TestFromCmd(t *testing.T) {
+	factory := &testFactory{name: "test"}
+	fallback := &testFactory{name: "fallback"}
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(WithFactory(context.Background(), factory))
+	assert.Equal(t, factory, FromCmd(cmd, fallback))
+}
+
+func TestFromCmd_fallback(t *testing.T) {
+	fallback := &testFactory{name: "fallback"}
+
+	cmd := &cobra.Command{}
+	assert.Equal(t, fallback, FromCmd(cmd, fallback))
+}
+
+func TestForContext(t *testing.T) {
+	factory := &testContextFactory{testFactory{name: "test"}}
+	fallback := &testFactory{name: "fallback"}
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(WithFactory(context.Background(), factory))
+	assert.Equal(t, &testFactory{name: "ctx"}, ForContext(cmd, "ctx", fallback))
+}
+
+func TestForContext_notContextFactory(t *testing.T) {
+	factory := &testFactory{name: "test"}
+	fallback := &testFactory{name: "fallback"}
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(WithFactory(context.Background(), factory))
+	assert.Equal(t, factory, ForContext(cmd, "ctx", fallback))
+}
+
+func TestForContext_fallback(t *testing.T) {
+	fallback := &testFactory{name: "fallback"}
+
+	cmd := &cobra.Command{}
+	assert.Equal(t, fallback, ForContext(cmd, "ctx", fallback))
+}
@@ -0,0 +1,182 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// Column is a column of tabular output. Its value for each record is got
+// either from a JSONPath evaluated against the JSON form of the record (as
+// it is written with -o json), or from a function of the record.
+type Column struct {
+	// Name is the header name of the column.
+	Name string
+
+	spec  string
+	path  *jsonPath
+	value func(data interface{}) (interface{}, error)
+}
+
+// FuncColumn creates a column which gets its value for each record from
+// the function.
+func FuncColumn(name string, f func(data interface{}) (interface{}, error)) Column {
+	return Column{Name: name, value: f}
+}
+
+// String gets the column as it is given to the --columns flag.
+func (c Column) String() string {
+	return c.Name + ":" + c.spec
+}
+
+// ParseColumns parses a comma separated list of columns, each of which is
+// a header name and a JSONPath (e.g. "ID:.id,TAGS:.tags[*]"). Commas within
+// brackets, braces and quotes do not separate columns. An empty list has
+// no columns.
+func ParseColumns(value string) ([]Column, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var columns []Column
+	for _, spec := range splitColumns(value) {
+		name, path, _ := strings.Cut(spec, ":")
+		name = strings.TrimSpace(name)
+		path = strings.TrimSpace(path)
+		if name == "" || path == "" {
+			return nil, fmt.Errorf("invalid column '%s': expected NAME:PATH", spec)
+		}
+
+		parsed, err := parseJSONPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid column '%s': %v", spec, err)
+		}
+		columns = append(columns, Column{Name: name, spec: path, path: parsed})
+	}
+	return columns, nil
+}
+
+// MustParseColumns parses a list of columns (see ParseColumns), panicking
+// if it is not valid. It is used for the columns which are built in to
+// commands.
+func MustParseColumns(value string) []Column {
+	columns, err := ParseColumns(value)
+	if err != nil {
+		panic(err)
+	}
+	return columns
+}
+
+// splitColumns splits a list of columns at the commas which are not within
+// brackets, braces or quotes.
+func splitColumns(value string) []string {
+	var (
+		specs []string
+		depth int
+		quote byte
+		start int
+	)
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{' || c == '(':
+			depth++
+		case c == ']' || c == '}' || c == ')':
+			depth--
+		case c == ',' && depth == 0:
+			specs = append(specs, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(specs, value[start:])
+}
+
+// columnNames gets the header names of the columns.
+func columnNames(columns []Column) []string {
+	names := make([]string, 0, len(columns))
+	for _, c := range columns {
+		names = append(names, c.Name)
+	}
+	return names
+}
+
+// columnRowFunc creates a row function which adds the values of the
+// columns to those of the base row function. If there is no base row
+// function, the rows have only the values of the columns.
+func (p *Printer) columnRowFunc(base func(data interface{}) ([]interface{}, error), columns []Column) func(data interface{}) ([]interface{}, error) {
+	return func(data interface{}) ([]interface{}, error) {
+		var row []interface{}
+		if base != nil {
+			r, err := base(data)
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, r...)
+		}
+
+		// The JSON form of the record is only made once for the row, and
+		// only if a column needs it.
+		var decoded interface{}
+		for _, c := range columns {
+			if c.value != nil {
+				v, err := c.value(data)
+				if err != nil {
+					return nil, err
+				}
+				row = append(row, v)
+				continue
+			}
+
+			if decoded == nil {
+				var err error
+				decoded, err = p.jsonForm(data)
+				if err != nil {
+					return nil, err
+				}
+			}
+			var buf bytes.Buffer
+			if err := c.path.Execute(&buf, decoded); err != nil {
+				return nil, err
+			}
+			row = append(row, strings.TrimSpace(buf.String()))
+		}
+		return row, nil
+	}
+}
+
+// jsonForm gets a record as it is decoded from its JSON output, after it
+// is transformed by the printer's transform function, if it has one.
+func (p *Printer) jsonForm(data interface{}) (interface{}, error) {
+	if p.transformFunc != nil {
+		var err error
+		data, err = p.transform(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return decodeJSONData(data)
+}
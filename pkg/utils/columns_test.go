@@ -0,0 +1,94 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseColumns(t *testing.T) {
+	tests := []struct {
+		value string
+		names []string
+		specs []string
+	}{
+		{value: "", names: []string{}, specs: []string{}},
+		{value: "ID:.id", names: []string{"ID"}, specs: []string{"ID:.id"}},
+		{value: "ID:.id, TYPE : .type", names: []string{"ID", "TYPE"}, specs: []string{"ID:.id", "TYPE:.type"}},
+		{value: "ID:{.id},TAGS:{.tags[*]}", names: []string{"ID", "TAGS"}, specs: []string{"ID:{.id}", "TAGS:{.tags[*]}"}},
+		{value: "MODE:.capabilities.mode", names: []string{"MODE"}, specs: []string{"MODE:.capabilities.mode"}},
+		{value: `LED:{.outputs[?(@.type=="a,b")].name}`, names: []string{"LED"}, specs: []string{`LED:{.outputs[?(@.type=="a,b")].name}`}},
+		{value: `KV:{range .items[*]}{.k}={.v}{","}{end}`, names: []string{"KV"}, specs: []string{`KV:{range .items[*]}{.k}={.v}{","}{end}`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			columns, err := ParseColumns(tt.value)
+			assert.NoError(t, err)
+
+			names := []string{}
+			specs := []string{}
+			for _, c := range columns {
+				names = append(names, c.Name)
+				specs = append(specs, c.String())
+			}
+			assert.Equal(t, tt.names, names)
+			assert.Equal(t, tt.specs, specs)
+		})
+	}
+}
+
+func TestParseColumns_error(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{value: "ID", err: "invalid column 'ID': expected NAME:PATH"},
+		{value: "ID:", err: "invalid column 'ID:': expected NAME:PATH"},
+		{value: ":.id", err: "invalid column ':.id': expected NAME:PATH"},
+		{value: "ID:.id,", err: "invalid column '': expected NAME:PATH"},
+		{value: "ID:{.id", err: "invalid column 'ID:{.id': unclosed '{' in '{.id'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			columns, err := ParseColumns(tt.value)
+			assert.Nil(t, columns)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestMustParseColumns(t *testing.T) {
+	assert.Len(t, MustParseColumns("ID:.id,TYPE:.type"), 2)
+	assert.Panics(t, func() {
+		MustParseColumns("ID")
+	})
+}
+
+func TestFuncColumn(t *testing.T) {
+	c := FuncColumn("FOO", func(data interface{}) (interface{}, error) {
+		return data, nil
+	})
+	assert.Equal(t, "FOO", c.Name)
+
+	v, err := c.value("bar")
+	assert.NoError(t, err)
+	assert.Equal(t, "bar", v)
+}
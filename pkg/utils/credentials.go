@@ -0,0 +1,230 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// credentialCacheDir is the directory, relative to the user's cache
+// directory, in which the output of credential helpers is cached.
+const credentialCacheDir = "synse/credentials"
+
+// userCacheDir gets the user's cache directory. It is a variable so that
+// tests can replace it.
+var userCacheDir = os.UserCacheDir
+
+// helperCredentials are the credentials returned by a credential helper.
+type helperCredentials struct {
+	Token    string            `json:"token,omitempty"`
+	Username string            `json:"username,omitempty"`
+	Password string            `json:"password,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+
+	// Expiry is the time until which the credentials may be cached. If it
+	// is zero, the credentials are not cached.
+	Expiry time.Time `json:"expiry,omitempty"`
+}
+
+// empty checks whether no credentials are set.
+func (c *helperCredentials) empty() bool {
+	return c.Token == "" && c.Username == "" && c.Password == "" && len(c.Headers) == 0
+}
+
+// getCredentials gets the credentials from the credential helper of a
+// context. Credentials are cached until their expiry, and the helper is
+// only run when there are no cached credentials for the context.
+//
+// The helper follows the protocol used by git credential helpers. It is
+// run as a shell command with the "get" argument, and is given the
+// protocol, host and context name as key=value lines on stdin. It writes
+// the credentials as key=value lines to stdout:
+//
+//	token=...                 a bearer token
+//	username=... password=... basic auth credentials
+//	header=Name: value        a header to send (may be repeated)
+//	ttl=...                   how long to cache the credentials (e.g. 300 or 5m)
+//	password_expiry_utc=...   when the credentials expire, in unix seconds
+//
+// Other keys are ignored.
+func getCredentials(ctx *config.ContextRecord) (*helperCredentials, error) {
+	helper := ctx.Context.CredentialHelper
+	cacheFile := credentialCacheFile(ctx)
+	if creds := readCachedCredentials(cacheFile); creds != nil {
+		log.WithFields(log.Fields{
+			"context": ctx.Name,
+			"expiry":  creds.Expiry,
+		}).Debug("auth: using cached credentials")
+		return creds, nil
+	}
+
+	protocol := "http"
+	if ctx.Context.TLSEnabled() {
+		protocol = "https"
+	}
+
+	log.WithFields(log.Fields{
+		"context": ctx.Name,
+		"helper":  helper,
+	}).Debug("auth: running credential helper")
+	cmd := exec.Command("sh", "-c", helper+` "$@"`, helper, "get")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("protocol=%s\nhost=%s\ncontext=%s\n\n", protocol, ctx.Context.Address, ctx.Name))
+	cmd.Stderr = os.Stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("credential helper '%s' failed: %v", helper, err)
+	}
+
+	creds, err := parseCredentials(string(out), time.Now())
+	if err != nil {
+		return nil, fmt.Errorf("credential helper '%s' returned %v", helper, err)
+	}
+	if creds.empty() {
+		return nil, fmt.Errorf("credential helper '%s' returned no credentials", helper)
+	}
+
+	if !creds.Expiry.IsZero() {
+		writeCachedCredentials(cacheFile, creds)
+	}
+	return creds, nil
+}
+
+// parseCredentials parses the key=value lines written by a credential
+// helper. Expiry times are relative to the given time.
+func parseCredentials(out string, now time.Time) (*helperCredentials, error) {
+	creds := &helperCredentials{}
+	for _, line := range strings.Split(out, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("a malformed line (expected key=value)")
+		}
+
+		switch key {
+		case "token":
+			creds.Token = value
+		case "username":
+			creds.Username = value
+		case "password":
+			creds.Password = value
+		case "header":
+			name, headerValue, ok := strings.Cut(value, ":")
+			if !ok {
+				return nil, fmt.Errorf("a malformed header (expected 'Name: value')")
+			}
+			if creds.Headers == nil {
+				creds.Headers = map[string]string{}
+			}
+			creds.Headers[strings.TrimSpace(name)] = strings.TrimSpace(headerValue)
+		case "ttl":
+			ttl, err := parseTTL(value)
+			if err != nil {
+				return nil, fmt.Errorf("an invalid ttl: %v", err)
+			}
+			creds.Expiry = now.Add(ttl)
+		case "password_expiry_utc":
+			seconds, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("an invalid password_expiry_utc: %v", err)
+			}
+			creds.Expiry = time.Unix(seconds, 0)
+		}
+	}
+	return creds, nil
+}
+
+// parseTTL parses a ttl given either in seconds or as a duration string.
+func parseTTL(value string) (time.Duration, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Duration(seconds) * time.Second, nil
+	}
+	return time.ParseDuration(value)
+}
+
+// credentialCacheFile gets the path of the file which caches the
+// credentials for the context. The cache is keyed by the context's name,
+// address and credential helper, so changing any of them invalidates it.
+// The path is empty if there is no cache directory.
+func credentialCacheFile(ctx *config.ContextRecord) string {
+	dir, err := userCacheDir()
+	if err != nil {
+		log.WithError(err).Debug("auth: no cache directory for credentials")
+		return ""
+	}
+	key := sha256.Sum256([]byte(strings.Join([]string{ctx.Name, ctx.Context.Address, ctx.Context.CredentialHelper}, "\x00")))
+	return filepath.Join(dir, credentialCacheDir, hex.EncodeToString(key[:])+".json")
+}
+
+// readCachedCredentials gets the credentials cached in the file, if they
+// have not expired. Failures to read the cache are not errors; they are
+// treated as though nothing was cached.
+func readCachedCredentials(path string) *helperCredentials {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithError(err).Debug("auth: failed to read cached credentials")
+		}
+		return nil
+	}
+
+	var creds helperCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		log.WithError(err).Debug("auth: failed to parse cached credentials")
+		return nil
+	}
+	if !time.Now().Before(creds.Expiry) {
+		return nil
+	}
+	return &creds
+}
+
+// writeCachedCredentials caches the credentials in the file, which is only
+// readable by the user. Failures to write the cache are logged and
+// otherwise ignored.
+func writeCachedCredentials(path string, creds *helperCredentials) {
+	if path == "" {
+		return
+	}
+	data, err := json.Marshal(creds)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(path), 0700)
+	}
+	if err == nil {
+		err = os.WriteFile(path, data, 0600)
+	}
+	if err != nil {
+		log.WithError(err).Debug("auth: failed to cache credentials")
+	}
+}
@@ -0,0 +1,211 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// credentialHelper is a credential helper script for tests, which records
+// its input and the number of times it is run.
+type credentialHelper struct {
+	dir string
+}
+
+// newCredentialHelper creates a credential helper script which writes the
+// given output.
+func newCredentialHelper(t *testing.T, output string) *credentialHelper {
+	dir := t.TempDir()
+	script := `#!/bin/sh
+[ "$1" = get ] || exit 1
+cat > "` + dir + `/input"
+echo >> "` + dir + `/calls"
+cat "` + dir + `/output"
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "output"), []byte(output), 0600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "helper"), []byte(script), 0700))
+	return &credentialHelper{dir: dir}
+}
+
+// command gets the command which runs the helper.
+func (h *credentialHelper) command() string {
+	return filepath.Join(h.dir, "helper")
+}
+
+// calls gets the number of times the helper was run.
+func (h *credentialHelper) calls(t *testing.T) int {
+	data, err := os.ReadFile(filepath.Join(h.dir, "calls"))
+	if os.IsNotExist(err) {
+		return 0
+	}
+	assert.NoError(t, err)
+	return strings.Count(string(data), "\n")
+}
+
+// input gets the input the helper was last run with.
+func (h *credentialHelper) input(t *testing.T) string {
+	data, err := os.ReadFile(filepath.Join(h.dir, "input"))
+	assert.NoError(t, err)
+	return string(data)
+}
+
+func TestParseCredentials(t *testing.T) {
+	now := time.Date(2019, 4, 22, 13, 30, 0, 0, time.UTC)
+
+	creds, err := parseCredentials(`
+token=abc
+username=user
+password=p=ss
+header=X-Api-Key: 123
+header=X-Tenant:vapor
+ttl=300
+unknown=ignored
+`, now)
+	assert.NoError(t, err)
+	assert.Equal(t, &helperCredentials{
+		Token:    "abc",
+		Username: "user",
+		Password: "p=ss",
+		Headers:  map[string]string{"X-Api-Key": "123", "X-Tenant": "vapor"},
+		Expiry:   now.Add(5 * time.Minute),
+	}, creds)
+}
+
+func TestParseCredentials_expiry(t *testing.T) {
+	now := time.Date(2019, 4, 22, 13, 30, 0, 0, time.UTC)
+
+	creds, err := parseCredentials("token=abc\nttl=1h\n", now)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), creds.Expiry)
+
+	creds, err = parseCredentials("token=abc\npassword_expiry_utc=1555940000\n", now)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Unix(1555940000, 0), creds.Expiry)
+}
+
+func TestParseCredentials_errors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		out  string
+		err  string
+	}{
+		{"no separator", "token", "a malformed line (expected key=value)"},
+		{"bad header", "header=X-Api-Key", "a malformed header (expected 'Name: value')"},
+		{"bad ttl", "ttl=soon", `an invalid ttl: time: invalid duration "soon"`},
+		{"bad expiry", "password_expiry_utc=soon", `an invalid password_expiry_utc: strconv.ParseInt: parsing "soon": invalid syntax`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseCredentials(tt.out, time.Now())
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestGetCredentials_cached(t *testing.T) {
+	test.UseCacheDir(t, &userCacheDir)
+	helper := newCredentialHelper(t, "token=abc\nttl=60\n")
+	ctx := &config.ContextRecord{
+		Name:    "testctx",
+		Type:    "server",
+		Context: config.Context{Address: "localhost:5000", CredentialHelper: helper.command()},
+	}
+
+	for i := 0; i < 2; i++ {
+		creds, err := getCredentials(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, "abc", creds.Token)
+	}
+	assert.Equal(t, 1, helper.calls(t))
+	assert.Equal(t, "protocol=http\nhost=localhost:5000\ncontext=testctx\n\n", helper.input(t))
+
+	// A different address is not served from the cache.
+	ctx.Context.Address = "localhost:5050"
+	_, err := getCredentials(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, helper.calls(t))
+}
+
+func TestGetCredentials_notCached(t *testing.T) {
+	dir := test.UseCacheDir(t, &userCacheDir)
+	helper := newCredentialHelper(t, "token=abc\n")
+	ctx := &config.ContextRecord{
+		Name:    "testctx",
+		Type:    "server",
+		Context: config.Context{Address: "localhost:5000", CredentialHelper: helper.command()},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := getCredentials(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, helper.calls(t))
+	assert.NoDirExists(t, filepath.Join(dir, credentialCacheDir))
+}
+
+func TestGetCredentials_expired(t *testing.T) {
+	dir := test.UseCacheDir(t, &userCacheDir)
+	helper := newCredentialHelper(t, "token=abc\npassword_expiry_utc=1555940000\n")
+	ctx := &config.ContextRecord{
+		Name:    "testctx",
+		Type:    "server",
+		Context: config.Context{Address: "localhost:5000", CredentialHelper: helper.command()},
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := getCredentials(ctx)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, helper.calls(t))
+
+	// The cache file is only readable by the user.
+	info, err := os.Stat(credentialCacheFile(ctx))
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	assert.True(t, strings.HasPrefix(credentialCacheFile(ctx), dir))
+}
+
+func TestGetCredentials_errors(t *testing.T) {
+	test.UseCacheDir(t, &userCacheDir)
+
+	// The helpers end with ':' so that the "get" argument is ignored.
+	for _, tt := range []struct {
+		name   string
+		helper string
+		err    string
+	}{
+		{"helper fails", "false", "credential helper 'false' failed: exit status 1"},
+		{"no credentials", "echo ttl=60;:", "credential helper 'echo ttl=60;:' returned no credentials"},
+		{"malformed", "echo token;:", "credential helper 'echo token;:' returned a malformed line (expected key=value)"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getCredentials(&config.ContextRecord{
+				Name:    "testctx",
+				Type:    "server",
+				Context: config.Context{Address: "localhost:5000", CredentialHelper: tt.helper},
+			})
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/pflag"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// ApplyContextDefaults sets the flags which were not set explicitly to the
+// defaults of the context a command is run against. Flags which the command
+// does not have are ignored. The default output format is only applied if
+// no output format flag is set, and the default tags are only applied if no
+// devices are given.
+func ApplyContextDefaults(flags *pflag.FlagSet, defaults *config.Defaults, devices bool) error {
+	if defaults.IsZero() {
+		return nil
+	}
+
+	values := map[string]string{
+		"ns":      defaults.Namespace,
+		"timeout": defaults.Timeout,
+	}
+	if defaults.NoHeader {
+		values["no-header"] = "true"
+	}
+	if defaults.Output != "" && !flags.Changed("json") && !flags.Changed("yaml") {
+		values["output"] = defaults.Output
+	}
+	if len(defaults.Tags) != 0 && !devices {
+		values["tag"] = strings.Join(defaults.Tags, ",")
+	}
+
+	for name, value := range values {
+		if value == "" || flags.Lookup(name) == nil || flags.Changed(name) {
+			continue
+		}
+		log.WithFields(log.Fields{
+			"flag":  name,
+			"value": value,
+		}).Debug("applying context default")
+		if err := flags.Set(name, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
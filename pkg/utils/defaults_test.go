@@ -0,0 +1,138 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// defaultsFlags holds the values of the flags which context defaults apply
+// to.
+type defaultsFlags struct {
+	ns       string
+	output   Output
+	noHeader bool
+	timeout  time.Duration
+	tags     []string
+}
+
+func newDefaultsFlagSet(f *defaultsFlags) *pflag.FlagSet {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.StringVar(&f.ns, "ns", "", "")
+	AddOutputFlags(flags, &f.output)
+	flags.BoolVar(&f.noHeader, "no-header", false, "")
+	flags.DurationVar(&f.timeout, "timeout", 0, "")
+	flags.StringSliceVar(&f.tags, "tag", []string{}, "")
+	return flags
+}
+
+var testDefaults = &config.Defaults{
+	Namespace: "vapor",
+	Output:    "yaml",
+	NoHeader:  true,
+	Timeout:   "10s",
+	Tags:      []string{"type:temperature", "vapor/rack:1"},
+}
+
+func TestApplyContextDefaults(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse(nil))
+
+	assert.NoError(t, ApplyContextDefaults(flags, testDefaults, false))
+	assert.Equal(t, OutputYAML, f.output.Format)
+	assert.Equal(t, "vapor", f.ns)
+	assert.True(t, f.noHeader)
+	assert.Equal(t, 10*time.Second, f.timeout)
+	assert.Equal(t, []string{"type:temperature", "vapor/rack:1"}, f.tags)
+}
+
+func TestApplyContextDefaults_explicitFlags(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse([]string{"--ns", "other", "--json", "--timeout", "1s", "--tag", "type:led"}))
+
+	assert.NoError(t, ApplyContextDefaults(flags, testDefaults, false))
+	assert.Equal(t, OutputJSON, f.output.Format)
+	assert.Equal(t, "other", f.ns)
+	assert.True(t, f.noHeader)
+	assert.Equal(t, time.Second, f.timeout)
+	assert.Equal(t, []string{"type:led"}, f.tags)
+}
+
+func TestApplyContextDefaults_explicitOutput(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse([]string{"-o", "jsonpath={.name}"}))
+
+	assert.NoError(t, ApplyContextDefaults(flags, testDefaults, false))
+	assert.Equal(t, OutputJSONPath, f.output.Format)
+	assert.Equal(t, "{.name}", f.output.Arg)
+}
+
+func TestApplyContextDefaults_devices(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse(nil))
+
+	assert.NoError(t, ApplyContextDefaults(flags, testDefaults, true))
+	assert.Empty(t, f.tags)
+	assert.Equal(t, "vapor", f.ns)
+}
+
+func TestApplyContextDefaults_tableOutput(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse(nil))
+
+	assert.NoError(t, ApplyContextDefaults(flags, &config.Defaults{Output: "table"}, false))
+	assert.Equal(t, OutputTable, f.output.Format)
+	assert.True(t, f.output.IsTable())
+}
+
+func TestApplyContextDefaults_missingFlags(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddOutputFlags(flags, &output)
+	assert.NoError(t, flags.Parse(nil))
+
+	assert.NoError(t, ApplyContextDefaults(flags, &config.Defaults{Output: "json", Namespace: "vapor"}, false))
+	assert.Equal(t, OutputJSON, output.Format)
+}
+
+func TestApplyContextDefaults_none(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse(nil))
+
+	assert.NoError(t, ApplyContextDefaults(flags, nil, false))
+	assert.NoError(t, ApplyContextDefaults(flags, &config.Defaults{}, false))
+	assert.Equal(t, defaultsFlags{output: Output{Fields: []string{}}, tags: []string{}}, f)
+}
+
+func TestApplyContextDefaults_invalidTimeout(t *testing.T) {
+	var f defaultsFlags
+	flags := newDefaultsFlagSet(&f)
+	assert.NoError(t, flags.Parse(nil))
+
+	assert.Error(t, ApplyContextDefaults(flags, &config.Defaults{Timeout: "soon"}, false))
+}
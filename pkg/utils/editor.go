@@ -0,0 +1,98 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// defaultEditor is the editor which is used if neither $VISUAL nor
+// $EDITOR is set.
+const defaultEditor = "vi"
+
+// Editor holds the command used to launch an interactive text editor.
+type Editor struct {
+	Args []string
+}
+
+// NewDefaultEditor creates an Editor from the user's environment. The
+// $VISUAL variable is preferred over $EDITOR; if neither is set, vi is used.
+// The editor command may include arguments (e.g. "code --wait").
+func NewDefaultEditor() Editor {
+	for _, env := range []string{"VISUAL", "EDITOR"} {
+		if e := strings.TrimSpace(os.Getenv(env)); e != "" {
+			return Editor{Args: strings.Fields(e)}
+		}
+	}
+	return Editor{Args: []string{defaultEditor}}
+}
+
+// Launch opens the file at the given path in the editor and waits for
+// the editor to exit.
+func (e Editor) Launch(path string) error {
+	if len(e.Args) == 0 {
+		return fmt.Errorf("no editor configured")
+	}
+
+	args := append(append([]string{}, e.Args[1:]...), path)
+	cmd := exec.Command(e.Args[0], args...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	log.WithFields(log.Fields{
+		"editor": e.Args,
+		"file":   path,
+	}).Debug("launching editor")
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to launch editor '%s': %v", strings.Join(e.Args, " "), err)
+	}
+	return nil
+}
+
+// LaunchTempFile writes the contents of the reader to a new temporary file
+// and opens it in the editor. Once the editor exits, the edited contents
+// are returned along with the path to the temporary file. The caller is
+// responsible for removing the file.
+func (e Editor) LaunchTempFile(prefix, suffix string, r io.Reader) ([]byte, string, error) {
+	f, err := os.CreateTemp("", prefix+"*"+suffix)
+	if err != nil {
+		return nil, "", err
+	}
+	path := f.Name()
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return nil, path, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, path, err
+	}
+
+	if err := e.Launch(path); err != nil {
+		return nil, path, err
+	}
+
+	data, err := os.ReadFile(path)
+	return data, path, err
+}
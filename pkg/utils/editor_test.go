@@ -0,0 +1,90 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDefaultEditor(t *testing.T) {
+	cases := []struct {
+		description string
+		visual      string
+		editor      string
+		expected    []string
+	}{
+		{
+			description: "no editor set",
+			expected:    []string{"vi"},
+		},
+		{
+			description: "only EDITOR set",
+			editor:      "nano",
+			expected:    []string{"nano"},
+		},
+		{
+			description: "VISUAL preferred over EDITOR",
+			visual:      "vim",
+			editor:      "nano",
+			expected:    []string{"vim"},
+		},
+		{
+			description: "editor with arguments",
+			editor:      "code --wait",
+			expected:    []string{"code", "--wait"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Setenv("VISUAL", c.visual)
+		t.Setenv("EDITOR", c.editor)
+
+		editor := NewDefaultEditor()
+		assert.Equal(t, c.expected, editor.Args, c.description)
+	}
+}
+
+func TestEditor_LaunchTempFile(t *testing.T) {
+	script := filepath.Join(t.TempDir(), "editor.sh")
+	err := os.WriteFile(script, []byte("#!/bin/sh\necho 'edited' >> \"$1\"\n"), 0755)
+	assert.NoError(t, err)
+
+	editor := Editor{Args: []string{script}}
+	data, path, err := editor.LaunchTempFile("test-", ".yaml", strings.NewReader("original\n"))
+	defer os.Remove(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "original\nedited\n", string(data))
+	assert.True(t, strings.HasSuffix(path, ".yaml"))
+}
+
+func TestEditor_LaunchError(t *testing.T) {
+	editor := Editor{Args: []string{filepath.Join(t.TempDir(), "no-such-editor")}}
+	err := editor.Launch("some-file")
+	assert.Error(t, err)
+}
+
+func TestEditor_LaunchNoArgs(t *testing.T) {
+	editor := Editor{}
+	err := editor.Launch("some-file")
+	assert.Error(t, err)
+}
@@ -0,0 +1,146 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// Environment variables which set the context a command is run against for
+// a single invocation, without changing the CLI configuration.
+const (
+	// EnvServerAddress is the address of the Synse Server to use in place
+	// of the current server context.
+	EnvServerAddress = "SYNSE_SERVER_ADDRESS"
+
+	// EnvPluginAddress is the address of the Synse plugin to use in place
+	// of the current plugin context.
+	EnvPluginAddress = "SYNSE_PLUGIN_ADDRESS"
+
+	// EnvContext is the name of a context to use in place of the current
+	// context of its type.
+	EnvContext = "SYNSE_CONTEXT"
+)
+
+var (
+	// ephemeral holds the in-memory contexts, keyed by type, which are used
+	// in place of the current contexts.
+	ephemeral = map[string]*config.ContextRecord{}
+
+	// contextOverride is the name of the context which is used in place of
+	// the current context of its type.
+	contextOverride string
+)
+
+// SetEphemeralContexts sets the contexts used in place of the current
+// contexts for a single invocation of the CLI. They are never added to the
+// CLI configuration.
+//
+// If an address is given, an in-memory context of the given type is created
+// for it. Otherwise, the SYNSE_SERVER_ADDRESS and SYNSE_PLUGIN_ADDRESS
+// environment variables create in-memory server and plugin contexts. The
+// SYNSE_CONTEXT environment variable names a configured context to use in
+// place of the current context of its type. In-memory contexts take
+// precedence over it.
+func SetEphemeralContexts(ctxType, address string) error {
+	ClearEphemeralContexts()
+
+	if address != "" {
+		if err := setEphemeralContext(ctxType, address, "--address"); err != nil {
+			return err
+		}
+	}
+	for _, e := range []struct {
+		ctxType string
+		env     string
+	}{
+		{"server", EnvServerAddress},
+		{"plugin", EnvPluginAddress},
+	} {
+		if ephemeral[e.ctxType] != nil {
+			continue
+		}
+		if addr := os.Getenv(e.env); addr != "" {
+			if err := setEphemeralContext(e.ctxType, addr, "$"+e.env); err != nil {
+				return err
+			}
+		}
+	}
+
+	if name := os.Getenv(EnvContext); name != "" {
+		if config.GetContext(name) == nil {
+			return fmt.Errorf("%w: %s (set by $%s)", ErrInvalidCtx, name, EnvContext)
+		}
+		contextOverride = name
+	}
+	return nil
+}
+
+// setEphemeralContext creates the in-memory context of the given type for
+// the address, which was set by the given flag or environment variable.
+func setEphemeralContext(ctxType, address, source string) error {
+	record := &config.ContextRecord{
+		Name: address,
+		Type: ctxType,
+		Context: config.Context{
+			Address: address,
+		},
+	}
+	if err := config.ValidateContext(record); err != nil {
+		return fmt.Errorf("invalid context set by %s: %v", source, err)
+	}
+
+	log.WithFields(log.Fields{
+		"type":    ctxType,
+		"address": address,
+		"source":  source,
+	}).Debug("using ephemeral context")
+	ephemeral[ctxType] = record
+	return nil
+}
+
+// ClearEphemeralContexts clears the contexts set by SetEphemeralContexts.
+func ClearEphemeralContexts() {
+	ephemeral = map[string]*config.ContextRecord{}
+	contextOverride = ""
+}
+
+// HasEphemeralContexts checks whether any contexts are set by
+// SetEphemeralContexts, in which case the CLI configuration should not be
+// persisted.
+func HasEphemeralContexts() bool {
+	return len(ephemeral) != 0 || contextOverride != ""
+}
+
+// currentContext gets the context which is used when no context is named
+// for the given type: the in-memory context, the context named by
+// SYNSE_CONTEXT, or the current context, in that order.
+func currentContext(ctxType string) *config.ContextRecord {
+	if record := ephemeral[ctxType]; record != nil {
+		return record
+	}
+	if contextOverride != "" {
+		if record := config.GetContext(contextOverride); record != nil && record.Type == ctxType {
+			return record
+		}
+	}
+	return config.GetCurrentContext()[ctxType]
+}
@@ -0,0 +1,180 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+var ephemeralTestContexts = []config.ContextRecord{
+	{Name: "local", Type: "server", Context: config.Context{Address: "localhost:5000"}},
+	{Name: "remote", Type: "server", Context: config.Context{Address: "10.0.0.1:5000"}},
+	{Name: "emulator", Type: "plugin", Context: config.Context{Address: "localhost:5001"}},
+}
+
+func TestSetEphemeralContexts_none(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+	test.AddContexts(t, ephemeralTestContexts, "local", "emulator")
+
+	assert.NoError(t, SetEphemeralContexts("server", ""))
+	assert.False(t, HasEphemeralContexts())
+
+	ctx, err := ResolveContext("", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "local", ctx.Name)
+}
+
+func TestSetEphemeralContexts_address(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+	test.AddContexts(t, ephemeralTestContexts, "local", "emulator")
+	t.Setenv(EnvServerAddress, "10.0.0.2:5000")
+
+	assert.NoError(t, SetEphemeralContexts("server", "10.0.0.3:5000"))
+	assert.True(t, HasEphemeralContexts())
+
+	// The flag takes precedence over the environment.
+	ctx, err := ResolveContext("", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.3:5000", ctx.Name)
+	assert.Equal(t, "10.0.0.3:5000", ctx.Context.Address)
+
+	// Named contexts and other types are resolved from the config.
+	ctx, err = ResolveContext("remote", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.1:5000", ctx.Context.Address)
+	ctx, err = ResolveContext("", "plugin")
+	assert.NoError(t, err)
+	assert.Equal(t, "emulator", ctx.Name)
+
+	// The ephemeral context is never added to the config.
+	assert.Len(t, config.GetContexts(), 3)
+	assert.Equal(t, "local", config.GetCurrentContext()["server"].Name)
+}
+
+func TestSetEphemeralContexts_env(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+	t.Setenv(EnvServerAddress, "10.0.0.2:5000")
+	t.Setenv(EnvPluginAddress, "10.0.0.2:5001")
+
+	assert.NoError(t, SetEphemeralContexts("plugin", ""))
+
+	ctx, err := ResolveContext("", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "server", ctx.Type)
+	assert.Equal(t, "10.0.0.2:5000", ctx.Context.Address)
+
+	ctx, err = ResolveContext("", "plugin")
+	assert.NoError(t, err)
+	assert.Equal(t, "plugin", ctx.Type)
+	assert.Equal(t, "10.0.0.2:5001", ctx.Context.Address)
+}
+
+func TestSetEphemeralContexts_context(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+	test.AddContexts(t, ephemeralTestContexts, "local", "emulator")
+	t.Setenv(EnvContext, "remote")
+
+	assert.NoError(t, SetEphemeralContexts("server", ""))
+	assert.True(t, HasEphemeralContexts())
+
+	ctx, err := ResolveContext("", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", ctx.Name)
+
+	// Only the current context of the same type is replaced.
+	ctx, err = ResolveContext("", "plugin")
+	assert.NoError(t, err)
+	assert.Equal(t, "emulator", ctx.Name)
+	assert.Equal(t, "local", config.GetCurrentContext()["server"].Name)
+}
+
+func TestSetEphemeralContexts_contextWithAddress(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+	test.AddContexts(t, ephemeralTestContexts, "local", "emulator")
+	t.Setenv(EnvContext, "remote")
+
+	assert.NoError(t, SetEphemeralContexts("server", "10.0.0.3:5000"))
+
+	ctx, err := ResolveContext("", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "10.0.0.3:5000", ctx.Context.Address)
+}
+
+func TestSetEphemeralContexts_namedContext(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+	test.AddContexts(t, ephemeralTestContexts, "local", "emulator")
+	t.Setenv(EnvServerAddress, "10.0.0.2:5000")
+
+	assert.NoError(t, SetEphemeralContexts("server", ""))
+	assert.True(t, HasEphemeralContexts())
+
+	// A named context is used in place of the ephemeral context.
+	ctx, err := ResolveContext("remote", "server")
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", ctx.Name)
+	assert.Equal(t, "10.0.0.1:5000", ctx.Context.Address)
+}
+
+func TestSetEphemeralContexts_errors(t *testing.T) {
+	defer func() {
+		config.Purge()
+		ClearEphemeralContexts()
+	}()
+
+	t.Run("unknown context", func(t *testing.T) {
+		t.Setenv(EnvContext, "missing")
+		err := SetEphemeralContexts("server", "")
+		assert.ErrorIs(t, err, ErrInvalidCtx)
+		assert.EqualError(t, err, "specified context does not exist: missing (set by $SYNSE_CONTEXT)")
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		err := SetEphemeralContexts("server", "%zz")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid context set by --address: ")
+		assert.False(t, HasEphemeralContexts())
+	})
+
+	t.Run("invalid env address", func(t *testing.T) {
+		t.Setenv(EnvPluginAddress, "%zz")
+		err := SetEphemeralContexts("server", "")
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid context set by $SYNSE_PLUGIN_ADDRESS: ")
+	})
+}
@@ -0,0 +1,313 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+)
+
+// MaxConcurrentRequests is the maximum number of contexts which a request
+// is made against at once.
+const MaxConcurrentRequests = 16
+
+// Errors for invalid context selections.
+var (
+	ErrMultipleSelections = errors.New("cannot combine --contexts, --all-contexts, --context-pattern and --selector")
+	ErrSelectionAndCtx    = errors.New("cannot use --with-context with --contexts, --all-contexts, --context-pattern or --selector")
+)
+
+// ContextSelection holds the command line options which select the contexts
+// a command is run against. At most one of them may be set.
+type ContextSelection struct {
+	// Names are the names of the contexts to select.
+	Names []string
+
+	// All selects all contexts of the type.
+	All bool
+
+	// Pattern is a glob pattern (e.g. "site-*") which selects the contexts
+	// with a matching name.
+	Pattern string
+
+	// Selector is a label selector (e.g. "env=prod,site!=lab") which
+	// selects the contexts with matching labels.
+	Selector string
+}
+
+// Empty checks whether the selection selects no contexts, in which case
+// a command runs against a single context as usual.
+func (s ContextSelection) Empty() bool {
+	return len(s.Names) == 0 && !s.All && s.Pattern == "" && s.Selector == ""
+}
+
+// Resolve gets the names of the selected contexts, which must all be of the
+// given type. Contexts selected by name are returned in the order given.
+// Otherwise, they are sorted by name.
+func (s ContextSelection) Resolve(ctxType string) ([]string, error) {
+	var set int
+	for _, ok := range []bool{len(s.Names) != 0, s.All, s.Pattern != "", s.Selector != ""} {
+		if ok {
+			set++
+		}
+	}
+	if set > 1 {
+		return nil, ErrMultipleSelections
+	}
+
+	if len(s.Names) != 0 {
+		var names []string
+		seen := map[string]bool{}
+		for _, name := range s.Names {
+			if seen[name] {
+				continue
+			}
+			if _, err := ResolveContext(name, ctxType); err != nil {
+				return nil, err
+			}
+			seen[name] = true
+			names = append(names, name)
+		}
+		return names, nil
+	}
+
+	if s.Pattern != "" {
+		if _, err := path.Match(s.Pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid context pattern '%s': %v", s.Pattern, err)
+		}
+	}
+	var selector config.Selector
+	if s.Selector != "" {
+		var err error
+		if selector, err = config.ParseSelector(s.Selector); err != nil {
+			return nil, err
+		}
+	}
+
+	var names []string
+	for _, ctx := range config.GetContexts() {
+		ctx := ctx
+		if ctx.Type != ctxType || !selector.Matches(&ctx) {
+			continue
+		}
+		if s.Pattern != "" {
+			if ok, _ := path.Match(s.Pattern, ctx.Name); !ok {
+				continue
+			}
+		}
+		names = append(names, ctx.Name)
+	}
+	if len(names) == 0 {
+		switch {
+		case s.Pattern != "":
+			return nil, fmt.Errorf("no %s contexts match '%s'", ctxType, s.Pattern)
+		case s.Selector != "":
+			return nil, fmt.Errorf("no %s contexts match selector '%s'", ctxType, s.Selector)
+		}
+		return nil, fmt.Errorf("no %s contexts to select", ctxType)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ContextResult holds the result of a request made against a context.
+type ContextResult struct {
+	// Context is the name of the context the request was made against.
+	Context string
+
+	// Data is the data returned by the request. It is nil if the request
+	// failed, or if there was nothing to print.
+	Data interface{}
+
+	// Err is the error returned by the request, if it failed.
+	Err error
+}
+
+// Request gets the data for a command using clients from the factory. If
+// there is nothing to print, it returns nil data.
+type Request func(factory clients.Factory) (interface{}, error)
+
+// StreamRequest gets the data for a command using clients from the factory,
+// passing each item of data to emit as it is received.
+type StreamRequest func(factory clients.Factory, emit func(data interface{}) error) error
+
+// Fanout makes a command's request against one or more contexts.
+type Fanout struct {
+	// Selection selects the contexts the request is made against. If it is
+	// empty, the request is made against a single context.
+	Selection ContextSelection
+
+	// Type is the type of the contexts the request is made against.
+	Type string
+
+	// Context is the name of the context the command was explicitly given
+	// (e.g. via --with-context). It may not be used with a Selection.
+	Context string
+
+	// Factory creates the factory for the clients of the named context. If
+	// the Selection is empty, it is called with an empty name.
+	Factory func(name string) clients.Factory
+
+	// Err is the writer that failures for individual contexts are reported
+	// to.
+	Err io.Writer
+}
+
+// Run makes the request and writes the data it returns with the printer.
+//
+// If contexts are selected, the request is made against each of them
+// concurrently, and their results are merged into a single output (see
+// Printer.WriteContexts). A request which fails for a context is reported
+// to the Fanout's error writer without aborting the others. If any fail,
+// an error is returned once all results have been written.
+func (f *Fanout) Run(printer *Printer, request Request) error {
+	if f.Selection.Empty() {
+		data, err := request(f.Factory(""))
+		if err != nil || data == nil {
+			return err
+		}
+		return printer.Write(data)
+	}
+
+	if f.Context != "" {
+		return ErrSelectionAndCtx
+	}
+	names, err := f.Selection.Resolve(f.Type)
+	if err != nil {
+		return err
+	}
+
+	results := FanOut(names, f.Factory, request)
+	if err := printer.WriteContexts(results); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Err == nil {
+			continue
+		}
+		failed++
+		if _, err := fmt.Fprintf(f.Err, "Error: context '%s': %v\n", r.Context, r.Err); err != nil {
+			return err
+		}
+	}
+	if failed != 0 {
+		return fmt.Errorf("request failed for %d of %d contexts", failed, len(results))
+	}
+	return nil
+}
+
+// Stream makes the request and writes each item of data it emits with the
+// printer as it is received (see Printer.NewStream).
+//
+// If contexts are selected, the items for each context are collected, and
+// sorted if the printer has a less function, and the results are merged
+// into a single output as with Run.
+func (f *Fanout) Stream(printer *Printer, request StreamRequest) error {
+	if f.Selection.Empty() {
+		stream := printer.NewStream()
+		// Print any rows which were received before a failure.
+		defer stream.Flush() // nolint: errcheck
+		if err := request(f.Factory(""), stream.Write); err != nil {
+			return err
+		}
+		return stream.Close()
+	}
+
+	return f.Run(printer, func(factory clients.Factory) (interface{}, error) {
+		var data []interface{}
+		err := request(factory, func(item interface{}) error {
+			data = append(data, item)
+			return nil
+		})
+		if err != nil || len(data) == 0 {
+			return nil, err
+		}
+		printer.sortData(data)
+		return data, nil
+	})
+}
+
+// Targets gets the contexts the request is made against. If no contexts
+// are selected, this is the single context for the command, if it can be
+// resolved.
+func (f *Fanout) Targets() ([]*config.ContextRecord, error) {
+	if f.Selection.Empty() {
+		return Targets(f.Context, f.Type), nil
+	}
+	if f.Context != "" {
+		return nil, ErrSelectionAndCtx
+	}
+	names, err := f.Selection.Resolve(f.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []*config.ContextRecord
+	for _, name := range names {
+		ctx, err := ResolveContext(name, f.Type)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, ctx)
+	}
+	return targets, nil
+}
+
+// FanOut makes the request against each of the named contexts concurrently,
+// using the factory created for each context. The results are returned in
+// the same order as the names.
+func FanOut(names []string, factory func(name string) clients.Factory, request Request) []ContextResult {
+	results := make([]ContextResult, len(names))
+	sem := make(chan struct{}, MaxConcurrentRequests)
+
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			log.WithField("context", name).Debug("making request against context")
+			data, err := request(factory(name))
+			if err != nil {
+				log.WithFields(log.Fields{
+					"context": name,
+					"error":   err,
+				}).Debug("request failed for context")
+				data = nil
+			}
+			results[i] = ContextResult{
+				Context: name,
+				Data:    data,
+				Err:     err,
+			}
+		}(i, name)
+	}
+	wg.Wait()
+	return results
+}
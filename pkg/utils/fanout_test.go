@@ -0,0 +1,359 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"github.com/vapor-ware/synse-cli/pkg/utils/clients"
+)
+
+// fanoutTestContexts are server and plugin contexts to select from.
+var fanoutTestContexts = []config.ContextRecord{
+	{Name: "site-b", Type: "server", Context: config.Context{Address: "b:5000"}, Labels: map[string]string{"env": "prod"}},
+	{Name: "site-a", Type: "server", Context: config.Context{Address: "a:5000"}, Labels: map[string]string{"env": "prod"}},
+	{Name: "lab", Type: "server", Context: config.Context{Address: "lab:5000"}, Labels: map[string]string{"env": "lab"}},
+	{Name: "site-p", Type: "plugin", Context: config.Context{Address: "p:5001"}},
+}
+
+// namedFactory is a clients.Factory which records the context it was
+// created for.
+type namedFactory struct {
+	clients.Factory
+	name string
+}
+
+func TestContextSelection_Empty(t *testing.T) {
+	assert.True(t, ContextSelection{}.Empty())
+	assert.False(t, ContextSelection{Names: []string{"a"}}.Empty())
+	assert.False(t, ContextSelection{All: true}.Empty())
+	assert.False(t, ContextSelection{Pattern: "a*"}.Empty())
+	assert.False(t, ContextSelection{Selector: "env=prod"}.Empty())
+}
+
+func TestContextSelection_Resolve(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	tests := []struct {
+		desc      string
+		selection ContextSelection
+		expected  []string
+	}{
+		{
+			desc:      "names are kept in order",
+			selection: ContextSelection{Names: []string{"site-b", "lab", "site-b"}},
+			expected:  []string{"site-b", "lab"},
+		},
+		{
+			desc:      "all contexts of the type",
+			selection: ContextSelection{All: true},
+			expected:  []string{"lab", "site-a", "site-b"},
+		},
+		{
+			desc:      "pattern",
+			selection: ContextSelection{Pattern: "site-*"},
+			expected:  []string{"site-a", "site-b"},
+		},
+		{
+			desc:      "label selector",
+			selection: ContextSelection{Selector: "env=prod"},
+			expected:  []string{"site-a", "site-b"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			names, err := test.selection.Resolve("server")
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
+
+func TestContextSelection_Resolve_errors(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	tests := []struct {
+		desc      string
+		selection ContextSelection
+		err       string
+	}{
+		{
+			desc:      "unknown name",
+			selection: ContextSelection{Names: []string{"site-a", "other"}},
+			err:       "specified context does not exist: other",
+		},
+		{
+			desc:      "wrong type",
+			selection: ContextSelection{Names: []string{"site-p"}},
+			err:       "specified context has the wrong type: 'site-p' is a plugin context, not a server context",
+		},
+		{
+			desc:      "no match",
+			selection: ContextSelection{Pattern: "dc-*"},
+			err:       "no server contexts match 'dc-*'",
+		},
+		{
+			desc:      "invalid pattern",
+			selection: ContextSelection{Pattern: "site-["},
+			err:       "invalid context pattern 'site-[': syntax error in pattern",
+		},
+		{
+			desc:      "no selector match",
+			selection: ContextSelection{Selector: "env=dev"},
+			err:       "no server contexts match selector 'env=dev'",
+		},
+		{
+			desc:      "invalid selector",
+			selection: ContextSelection{Selector: "env=prod,"},
+			err:       "invalid label selector 'env=prod,': empty requirement",
+		},
+		{
+			desc:      "combined",
+			selection: ContextSelection{Names: []string{"site-a"}, All: true},
+			err:       ErrMultipleSelections.Error(),
+		},
+		{
+			desc:      "combined with selector",
+			selection: ContextSelection{Pattern: "site-*", Selector: "env=prod"},
+			err:       ErrMultipleSelections.Error(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			_, err := test.selection.Resolve("server")
+			assert.EqualError(t, err, test.err)
+		})
+	}
+}
+
+func TestContextSelection_Resolve_noContexts(t *testing.T) {
+	_, err := ContextSelection{All: true}.Resolve("server")
+	assert.EqualError(t, err, "no server contexts to select")
+}
+
+func TestFanOut(t *testing.T) {
+	names := []string{"c", "a", "b"}
+
+	// Each request blocks until all have started, so the test only passes
+	// if they are made concurrently.
+	var started sync.WaitGroup
+	started.Add(len(names))
+	results := FanOut(names, func(name string) clients.Factory {
+		return &namedFactory{name: name}
+	}, func(factory clients.Factory) (interface{}, error) {
+		started.Done()
+		done := make(chan struct{})
+		go func() {
+			started.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("requests were not concurrent")
+		}
+
+		name := factory.(*namedFactory).name
+		if name == "a" {
+			return "ignored", fmt.Errorf("failed")
+		}
+		return name + "-data", nil
+	})
+
+	assert.Equal(t, []ContextResult{
+		{Context: "c", Data: "c-data"},
+		{Context: "a", Err: fmt.Errorf("failed")},
+		{Context: "b", Data: "b-data"},
+	}, results)
+}
+
+func TestFanout_Run_single(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("NAME")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data}, nil
+	})
+
+	f := &Fanout{
+		Type: "server",
+		Factory: func(name string) clients.Factory {
+			return &namedFactory{name: name}
+		},
+	}
+	err := f.Run(p, func(factory clients.Factory) (interface{}, error) {
+		assert.Equal(t, "", factory.(*namedFactory).name)
+		return "single", nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME\nsingle\n", out.String())
+}
+
+func TestFanout_Run_multiple(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("NAME")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data}, nil
+	})
+
+	errOut := &bytes.Buffer{}
+	f := &Fanout{
+		Selection: ContextSelection{All: true},
+		Type:      "server",
+		Factory: func(name string) clients.Factory {
+			return &namedFactory{name: name}
+		},
+		Err: errOut,
+	}
+	err := f.Run(p, func(factory clients.Factory) (interface{}, error) {
+		name := factory.(*namedFactory).name
+		if name == "lab" {
+			return nil, fmt.Errorf("connection refused")
+		}
+		return []string{name + "-1", name + "-2"}, nil
+	})
+	assert.EqualError(t, err, "request failed for 1 of 3 contexts")
+	assert.Equal(t, heredoc.Doc(`
+		CONTEXT   NAME
+		site-a    site-a-1
+		site-a    site-a-2
+		site-b    site-b-1
+		site-b    site-b-2
+	`), out.String())
+	assert.Equal(t, "Error: context 'lab': connection refused\n", errOut.String())
+}
+
+func TestFanout_Run_withContext(t *testing.T) {
+	f := &Fanout{
+		Selection: ContextSelection{All: true},
+		Type:      "server",
+		Context:   "site-a",
+	}
+	err := f.Run(&Printer{}, func(factory clients.Factory) (interface{}, error) {
+		t.Fatal("request should not be made")
+		return nil, nil
+	})
+	assert.Equal(t, ErrSelectionAndCtx, err)
+}
+
+func TestFanout_Stream_single(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("NAME")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data}, nil
+	})
+
+	f := &Fanout{
+		Type: "server",
+		Factory: func(name string) clients.Factory {
+			return &namedFactory{name: name}
+		},
+	}
+	err := f.Stream(p, func(factory clients.Factory, emit func(interface{}) error) error {
+		assert.Equal(t, "", factory.(*namedFactory).name)
+		for _, name := range []string{"b", "a"} {
+			if err := emit(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME\nb\na\n", out.String())
+}
+
+func TestFanout_Stream_singleErr(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV}, false)
+	p.SetHeader("NAME")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data}, nil
+	})
+
+	f := &Fanout{
+		Type: "server",
+		Factory: func(name string) clients.Factory {
+			return &namedFactory{name: name}
+		},
+	}
+	err := f.Stream(p, func(factory clients.Factory, emit func(interface{}) error) error {
+		if err := emit("a"); err != nil {
+			return err
+		}
+		return fmt.Errorf("connection reset")
+	})
+	assert.EqualError(t, err, "connection reset")
+	assert.Equal(t, "NAME\na\n", out.String())
+}
+
+func TestFanout_Stream_multiple(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("NAME")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data}, nil
+	})
+	p.SetLessFunc(func(a, b interface{}) bool {
+		return a.(string) < b.(string)
+	})
+
+	f := &Fanout{
+		Selection: ContextSelection{Pattern: "site-*"},
+		Type:      "server",
+		Factory: func(name string) clients.Factory {
+			return &namedFactory{name: name}
+		},
+		Err: &bytes.Buffer{},
+	}
+	err := f.Stream(p, func(factory clients.Factory, emit func(interface{}) error) error {
+		name := factory.(*namedFactory).name
+		for _, n := range []string{"2", "1"} {
+			if err := emit(name + "-" + n); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, heredoc.Doc(`
+		CONTEXT   NAME
+		site-a    site-a-1
+		site-a    site-a-2
+		site-b    site-b-1
+		site-b    site-b-2
+	`), out.String())
+}
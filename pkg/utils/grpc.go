@@ -17,7 +17,10 @@
 package utils
 
 import (
-	"github.com/pkg/errors"
+	"context"
+	"net"
+	"time"
+
 	log "github.com/sirupsen/logrus"
 	"github.com/vapor-ware/synse-cli/pkg/config"
 	synse "github.com/vapor-ware/synse-server-grpc/go"
@@ -26,56 +29,58 @@ import (
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-// Errors relating to gRPC client creation.
-var (
-	ErrNoCurrentPluginCtx = errors.New("failed creating plugin gRPC client: no current plugin context")
-	ErrInvalidPluginCtx   = errors.New("failed creating plugin gRPC client: specified context does not exist")
-	ErrNotAPluginCtx      = errors.New("failed creating plugin gRPC client: specified context is not a plugin context")
-)
-
-// NewSynseGrpcClient creates a new instance of a Synse gRPC client
-// for communicating with Synse plugins.
-func NewSynseGrpcClient(ctx, certFile string) (*grpc.ClientConn, synse.V3PluginClient, error) {
-	var pluginContext *config.ContextRecord
-
-	if ctx == "" {
-		// If no specific context is specified, get the current context.
-		currentContexts := config.GetCurrentContext()
-		pluginContext = currentContexts["plugin"]
-		if pluginContext == nil {
-			return nil, nil, ErrNoCurrentPluginCtx
-		}
-	} else {
-		// Get the named context.
-		pluginContext = config.GetContext(ctx)
-		if pluginContext == nil {
-			return nil, nil, ErrInvalidPluginCtx
-		}
-		if pluginContext.Type != "plugin" {
-			return nil, nil, ErrNotAPluginCtx
-		}
+// GRPC creates a new instance of a Synse gRPC client for communicating
+// with a Synse plugin.
+func (f *contextClients) GRPC() (*grpc.ClientConn, synse.V3PluginClient, error) {
+	pluginContext, err := f.resolve("plugin", "gRPC")
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// If a cert file was provided, use it to override any cert file which may
-	// already be configured for the context.
-	if certFile != "" {
-		pluginContext.Context.ClientCert = certFile
+	tlsConfig, err := NewTLSConfig(pluginContext.Context)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var dialOptions []grpc.DialOption
-	if pluginContext.Context.ClientCert == "" {
+	if tlsConfig == nil {
 		log.Debug("grpc client: with insecure")
 		dialOptions = append(dialOptions, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	} else {
-		creds, err := credentials.NewClientTLSFromFile(pluginContext.Context.ClientCert, "")
-		if err != nil {
-			return nil, nil, err
-		}
-		log.WithField("cert", pluginContext.Context.ClientCert).Debug("grpc client: with credentials")
-		dialOptions = append(dialOptions, grpc.WithTransportCredentials(creds))
+		log.Debug("grpc client: with tls")
+		dialOptions = append(dialOptions, grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)))
 	}
 
-	conn, err := grpc.Dial(pluginContext.Context.Address, dialOptions...)
+	if f.opts.Timeout > 0 {
+		log.WithField("timeout", f.opts.Timeout).Debug("grpc client: with timeout")
+		dialOptions = append(dialOptions,
+			grpc.WithUnaryInterceptor(timeoutUnaryInterceptor(f.opts.Timeout)),
+			grpc.WithStreamInterceptor(timeoutStreamInterceptor(f.opts.Timeout)),
+		)
+	}
+
+	target := pluginContext.Context.Address
+	if path, ok := config.UnixSocketPath(target); ok {
+		log.WithField("path", path).Debug("grpc client: with unix socket")
+		target = unixHost
+		dial := unixDialer(path)
+		dialOptions = append(dialOptions, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dial(ctx, "unix", address)
+		}))
+	}
+
+	// gRPC uses the HTTPS proxy from the environment, as for TLS connections.
+	dial, err := proxyDialer(pluginContext.Context, "https")
+	if err != nil {
+		return nil, nil, err
+	}
+	if dial != nil {
+		dialOptions = append(dialOptions, grpc.WithContextDialer(func(ctx context.Context, address string) (net.Conn, error) {
+			return dial(ctx, "tcp", address)
+		}))
+	}
+
+	conn, err := grpc.Dial(target, dialOptions...)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -83,3 +88,43 @@ func NewSynseGrpcClient(ctx, certFile string) (*grpc.ClientConn, synse.V3PluginC
 
 	return conn, client, nil
 }
+
+// timeoutUnaryInterceptor creates an interceptor which limits each unary
+// call to the given timeout.
+func timeoutUnaryInterceptor(timeout time.Duration) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+// timeoutStreamInterceptor creates an interceptor which limits each
+// streaming call, including receiving all of its messages, to the given
+// timeout.
+func timeoutStreamInterceptor(timeout time.Duration) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		return &timeoutStream{ClientStream: stream, cancel: cancel}, nil
+	}
+}
+
+// timeoutStream releases the context of a stream with a timeout once the
+// stream ends.
+type timeoutStream struct {
+	grpc.ClientStream
+	cancel context.CancelFunc
+}
+
+func (s *timeoutStream) RecvMsg(m interface{}) error {
+	err := s.ClientStream.RecvMsg(m)
+	if err != nil {
+		s.cancel()
+	}
+	return err
+}
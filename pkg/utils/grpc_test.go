@@ -17,21 +17,29 @@
 package utils
 
 import (
+	"context"
+	"io"
+	"net"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/vapor-ware/synse-cli/pkg/config"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
-func TestNewSynseGrpcClient_noContext(t *testing.T) {
-	conn, client, err := NewSynseGrpcClient("", "")
+func TestClientFactory_GRPC_noContext(t *testing.T) {
+	conn, client, err := NewClientFactory(ClientOptions{}).GRPC()
 
 	assert.Nil(t, conn)
 	assert.Nil(t, client)
 	assert.Error(t, err)
 }
 
-func TestNewSynseGrpcClient_currentContext(t *testing.T) {
+func TestClientFactory_GRPC_currentContext(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -45,13 +53,13 @@ func TestNewSynseGrpcClient_currentContext(t *testing.T) {
 	err = config.SetCurrentContext("testctx")
 	assert.NoError(t, err)
 
-	conn, client, err := NewSynseGrpcClient("", "")
+	conn, client, err := NewClientFactory(ClientOptions{}).GRPC()
 	assert.NotNil(t, conn)
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
 
-func TestNewSynseGrpcClient_namedContext(t *testing.T) {
+func TestClientFactory_GRPC_namedContext(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -62,29 +70,29 @@ func TestNewSynseGrpcClient_namedContext(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	conn, client, err := NewSynseGrpcClient("testctx", "")
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
 	assert.NotNil(t, conn)
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
 
-func TestNewSynseGrpcClient_currentContextNotSet(t *testing.T) {
-	conn, client, err := NewSynseGrpcClient("", "")
+func TestClientFactory_GRPC_currentContextNotSet(t *testing.T) {
+	conn, client, err := NewClientFactory(ClientOptions{}).GRPC()
 	assert.Nil(t, conn)
 	assert.Nil(t, client)
 	assert.Error(t, err)
-	assert.Equal(t, ErrNoCurrentPluginCtx, err)
+	assert.ErrorIs(t, err, ErrNoCurrentCtx)
 }
 
-func TestNewSynseGrpcClient_namedContextNotFound(t *testing.T) {
-	conn, client, err := NewSynseGrpcClient("testctx", "")
+func TestClientFactory_GRPC_namedContextNotFound(t *testing.T) {
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
 	assert.Nil(t, conn)
 	assert.Nil(t, client)
 	assert.Error(t, err)
-	assert.Equal(t, ErrInvalidPluginCtx, err)
+	assert.ErrorIs(t, err, ErrInvalidCtx)
 }
 
-func TestNewSynseGrpcClient_notAPluginCtx(t *testing.T) {
+func TestClientFactory_GRPC_notAPluginCtx(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -95,14 +103,14 @@ func TestNewSynseGrpcClient_notAPluginCtx(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	conn, client, err := NewSynseGrpcClient("testctx", "")
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
 	assert.Nil(t, conn)
 	assert.Nil(t, client)
 	assert.Error(t, err)
-	assert.Equal(t, ErrNotAPluginCtx, err)
+	assert.ErrorIs(t, err, ErrWrongCtxType)
 }
 
-func TestNewSynseGrpcClient_invalidCert(t *testing.T) {
+func TestClientFactory_GRPC_invalidCert(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -113,8 +121,67 @@ func TestNewSynseGrpcClient_invalidCert(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	conn, client, err := NewSynseGrpcClient("testctx", "not-a-cert")
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx", TLSCert: "not-a-cert"}).GRPC()
 	assert.Nil(t, conn)
 	assert.Nil(t, client)
 	assert.Error(t, err)
 }
+
+// slowPlugin implements plugin gRPC API calls which take longer than the
+// timeouts used in tests.
+type slowPlugin struct {
+	synse.V3PluginServer
+}
+
+func (p *slowPlugin) Test(ctx context.Context, _ *synse.Empty) (*synse.V3TestStatus, error) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+	}
+	return &synse.V3TestStatus{Ok: true}, nil
+}
+
+func (p *slowPlugin) Devices(_ *synse.V3DeviceSelector, stream synse.V3Plugin_DevicesServer) error {
+	if err := stream.Send(&synse.V3Device{Id: "1"}); err != nil {
+		return err
+	}
+	select {
+	case <-stream.Context().Done():
+	case <-time.After(5 * time.Second):
+	}
+	return nil
+}
+
+func TestClientFactory_GRPC_timeout(t *testing.T) {
+	defer config.Purge()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer()
+	synse.RegisterV3PluginServer(server, &slowPlugin{})
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "testctx",
+		Type:    "plugin",
+		Context: config.Context{Address: lis.Addr().String()},
+	}))
+
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx", Timeout: 100 * time.Millisecond}).GRPC()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = client.Test(context.Background(), &synse.Empty{})
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), err)
+
+	// The timeout covers receiving all messages of a stream.
+	stream, err := client.Devices(context.Background(), &synse.V3DeviceSelector{})
+	assert.NoError(t, err)
+	device, err := stream.Recv()
+	assert.NoError(t, err)
+	assert.Equal(t, "1", device.Id)
+	_, err = stream.Recv()
+	assert.NotEqual(t, io.EOF, err)
+	assert.Equal(t, codes.DeadlineExceeded, status.Code(err), err)
+}
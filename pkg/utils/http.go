@@ -17,45 +17,48 @@
 package utils
 
 import (
-	"github.com/pkg/errors"
+	"net/http"
+
 	"github.com/vapor-ware/synse-cli/pkg/config"
 	"github.com/vapor-ware/synse-client-go/synse"
 )
 
-// Errors relating to HTTP client creation.
-var (
-	ErrNoCurrentServerCtx = errors.New("failed creating server HTTP client: no current server context")
-	ErrInvalidServerCtx   = errors.New("failed creating server HTTP client: specified context does not exist")
-	ErrNotAServerCtx      = errors.New("failed creating server HTTP client: specified context is not a server context")
-)
+// HTTP creates a new Synse HTTP client for communicating with Synse Server
+// via its HTTP API.
+func (f *contextClients) HTTP() (synse.Client, error) {
+	serverContext, err := f.resolve("server", "HTTP")
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := NewTLSConfig(serverContext.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := authHeaders(serverContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Requests to a server on a unix socket are made to a placeholder host,
+	// over a transport which dials the socket.
+	address := serverContext.Context.Address
+	socket, unix := config.UnixSocketPath(address)
+	if unix {
+		address = unixHost
+	}
 
-// NewSynseHTTPClient creates a new Synse HTTP client for communicating with
-// Synse Server instances.
-func NewSynseHTTPClient(ctx string, certFile string) (synse.Client, error) {
-	var serverContext *config.ContextRecord
-
-	if ctx == "" {
-		// If no specific context is specified, get the current context.
-		currentContexts := config.GetCurrentContext()
-		serverContext = currentContexts["server"]
-		if serverContext == nil {
-			return nil, ErrNoCurrentServerCtx
-		}
-	} else {
-		// Get the named context.
-		serverContext = config.GetContext(ctx)
-		if serverContext == nil {
-			return nil, ErrInvalidServerCtx
-		}
-		if serverContext.Type != "server" {
-			return nil, ErrNotAServerCtx
-		}
+	var transport http.RoundTripper = proxyTransport(serverContext.Context)
+	if unix {
+		transport = unixTransport(socket)
 	}
 
-	return synse.NewHTTPClientV3(&synse.Options{
-		Address: serverContext.Context.Address,
-		TLS: synse.TLSOptions{
-			CertFile: certFile,
-		},
-	})
+	return newHTTPServerClient(serverClientOptions{
+		address:   address,
+		timeout:   f.opts.Timeout,
+		noRetry:   f.opts.NoRetry,
+		tlsConfig: tlsConfig,
+		header:    headers,
+	}, transport)
 }
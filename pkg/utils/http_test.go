@@ -23,14 +23,14 @@ import (
 	"github.com/vapor-ware/synse-cli/pkg/config"
 )
 
-func TestNewSynseHTTPClient_noContext(t *testing.T) {
-	client, err := NewSynseHTTPClient("", "")
+func TestClientFactory_HTTP_noContext(t *testing.T) {
+	client, err := NewClientFactory(ClientOptions{}).HTTP()
 
 	assert.Nil(t, client)
 	assert.Error(t, err)
 }
 
-func TestNewSynseHTTPClient_invalidURI(t *testing.T) {
+func TestClientFactory_HTTP_invalidURI(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -41,12 +41,12 @@ func TestNewSynseHTTPClient_invalidURI(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	client, err := NewSynseHTTPClient("", "")
+	client, err := NewClientFactory(ClientOptions{}).HTTP()
 	assert.Nil(t, client)
 	assert.Error(t, err)
 }
 
-func TestNewSynseHTTPClient_noCurrentCtx(t *testing.T) {
+func TestClientFactory_HTTP_noCurrentCtx(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -60,12 +60,12 @@ func TestNewSynseHTTPClient_noCurrentCtx(t *testing.T) {
 	err = config.SetCurrentContext("testctx")
 	assert.NoError(t, err)
 
-	client, err := NewSynseHTTPClient("", "")
+	client, err := NewClientFactory(ClientOptions{}).HTTP()
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
 
-func TestNewSynseHTTPClient_namedContext(t *testing.T) {
+func TestClientFactory_HTTP_namedContext(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -76,26 +76,26 @@ func TestNewSynseHTTPClient_namedContext(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	client, err := NewSynseHTTPClient("testctx", "")
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
 	assert.NotNil(t, client)
 	assert.NoError(t, err)
 }
 
-func TestNewSynseHTTPClient_currentContextNotSet(t *testing.T) {
-	client, err := NewSynseHTTPClient("", "")
+func TestClientFactory_HTTP_currentContextNotSet(t *testing.T) {
+	client, err := NewClientFactory(ClientOptions{}).HTTP()
 	assert.Nil(t, client)
 	assert.Error(t, err)
-	assert.Equal(t, ErrNoCurrentServerCtx, err)
+	assert.ErrorIs(t, err, ErrNoCurrentCtx)
 }
 
-func TestNewSynseHTTPClient_namedContextNotFound(t *testing.T) {
-	client, err := NewSynseHTTPClient("testctx", "")
+func TestClientFactory_HTTP_namedContextNotFound(t *testing.T) {
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
 	assert.Nil(t, client)
 	assert.Error(t, err)
-	assert.Equal(t, ErrInvalidServerCtx, err)
+	assert.ErrorIs(t, err, ErrInvalidCtx)
 }
 
-func TestNewSynseHTTPClient_notAServerCtx(t *testing.T) {
+func TestClientFactory_HTTP_notAServerCtx(t *testing.T) {
 	defer config.Purge()
 	err := config.AddContext(&config.ContextRecord{
 		Name: "testctx",
@@ -106,8 +106,8 @@ func TestNewSynseHTTPClient_notAServerCtx(t *testing.T) {
 	})
 	assert.NoError(t, err)
 
-	client, err := NewSynseHTTPClient("testctx", "")
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
 	assert.Nil(t, client)
 	assert.Error(t, err)
-	assert.Equal(t, ErrNotAServerCtx, err)
+	assert.ErrorIs(t, err, ErrWrongCtxType)
 }
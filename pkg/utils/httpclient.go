@@ -0,0 +1,439 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+// The synse-client-go clients only expose some of the transport options the
+// CLI needs (e.g. there is no way to set a CA bundle, a custom dialer or
+// request headers). The CLI implements the synse.Client interface itself, so
+// it owns the transports of its clients. The clients follow the behavior of
+// the synse-client-go clients.
+
+// Defaults for the Synse Server clients, as used by synse-client-go.
+const (
+	defaultHTTPTimeout      = 2 * time.Second
+	defaultHandshakeTimeout = 45 * time.Second
+	defaultRetryCount       = 3
+	defaultRetryWaitTime    = 100 * time.Millisecond
+	defaultRetryMaxWaitTime = 2 * time.Second
+)
+
+// The Synse Server v3 API routes.
+const (
+	serverAPIVersion   = "v3"
+	serverTestURI      = "test"
+	serverVersionURI   = "version"
+	serverConfigURI    = "config"
+	serverPluginURI    = "plugin"
+	serverHealthURI    = "plugin/health"
+	serverScanURI      = "scan"
+	serverTagsURI      = "tags"
+	serverInfoURI      = "info"
+	serverReadURI      = "read"
+	serverReadCacheURI = "readcache"
+	serverWriteURI     = "write"
+	serverWriteWaitURI = "write/wait"
+	serverTxnURI       = "transaction"
+	serverConnectURI   = "connect"
+)
+
+// serverClientOptions are the options for the Synse Server clients created
+// by the CLI.
+type serverClientOptions struct {
+	// address is the host[:port] of the server.
+	address string
+
+	// timeout is the time limit for an HTTP request, or for the WebSocket
+	// handshake. If it is zero, the synse-client-go default is used.
+	timeout time.Duration
+
+	// noRetry disables retries of failed HTTP requests.
+	noRetry bool
+
+	// tlsConfig is the TLS configuration, if TLS is enabled.
+	tlsConfig *tls.Config
+
+	// header holds the headers sent with every request.
+	header http.Header
+}
+
+// options gets the synse-client-go form of the options, as reported by the
+// GetOptions method of the clients.
+func (o *serverClientOptions) options() *synse.Options {
+	retries := uint(defaultRetryCount)
+	if o.noRetry {
+		retries = 0
+	}
+	return &synse.Options{
+		Address: o.address,
+		HTTP: synse.HTTPOptions{
+			Timeout: o.httpTimeout(),
+			Retry: synse.RetryOptions{
+				Count:       retries,
+				WaitTime:    defaultRetryWaitTime,
+				MaxWaitTime: defaultRetryMaxWaitTime,
+			},
+		},
+		WebSocket: synse.WebSocketOptions{
+			HandshakeTimeout: o.handshakeTimeout(),
+		},
+		TLS: synse.TLSOptions{
+			Enabled: o.tlsConfig != nil,
+		},
+	}
+}
+
+// httpTimeout gets the time limit for an HTTP request.
+func (o *serverClientOptions) httpTimeout() time.Duration {
+	if o.timeout == 0 {
+		return defaultHTTPTimeout
+	}
+	return o.timeout
+}
+
+// handshakeTimeout gets the time limit for the WebSocket handshake.
+func (o *serverClientOptions) handshakeTimeout() time.Duration {
+	if o.timeout == 0 {
+		return defaultHandshakeTimeout
+	}
+	return o.timeout
+}
+
+// serverURL builds the URL of a route of the server for the scheme. Routes
+// other than the status and version routes are versioned.
+func (o *serverClientOptions) serverURL(scheme string, versioned bool, path ...string) string {
+	if versioned {
+		path = append([]string{serverAPIVersion}, path...)
+	}
+	u := &url.URL{
+		Scheme: scheme,
+		Host:   o.address,
+		Path:   "/" + strings.Join(path, "/"),
+	}
+	return u.String()
+}
+
+// httpServerClient is a Synse Server client for the HTTP API.
+type httpServerClient struct {
+	opts   serverClientOptions
+	client *resty.Client
+	scheme string
+}
+
+// newHTTPServerClient creates a Synse Server HTTP client which makes its
+// requests over the transport.
+func newHTTPServerClient(opts serverClientOptions, transport http.RoundTripper) (synse.Client, error) {
+	if opts.address == "" {
+		return nil, errors.New("failed to create a http client: no address is specified")
+	}
+
+	retries := defaultRetryCount
+	if opts.noRetry {
+		retries = 0
+	}
+	client := resty.New().
+		SetTransport(transport).
+		SetTimeout(opts.httpTimeout()).
+		SetRetryCount(retries).
+		SetRetryWaitTime(defaultRetryWaitTime).
+		SetRetryMaxWaitTime(defaultRetryMaxWaitTime)
+	for name, values := range opts.header {
+		for _, value := range values {
+			client.Header.Add(name, value)
+		}
+	}
+
+	scheme := "http"
+	if opts.tlsConfig != nil {
+		scheme = "https"
+		client.SetTLSClientConfig(opts.tlsConfig)
+	}
+	return &httpServerClient{
+		opts:   opts,
+		client: client,
+		scheme: scheme,
+	}, nil
+}
+
+// Open fulfils the synse.Client interface, but has no effect for the HTTP client.
+func (c *httpServerClient) Open() error {
+	return nil
+}
+
+// Close fulfils the synse.Client interface, but has no effect for the HTTP client.
+func (c *httpServerClient) Close() error {
+	return nil
+}
+
+// GetOptions returns the options of the client.
+func (c *httpServerClient) GetOptions() *synse.Options {
+	return c.opts.options()
+}
+
+// Status returns the status info.
+func (c *httpServerClient) Status() (*scheme.Status, error) {
+	out := new(scheme.Status)
+	if err := c.get(false, nil, out, serverTestURI); err != nil {
+		return nil, errors.Wrap(err, "failed to request `/test` endpoint")
+	}
+	return out, nil
+}
+
+// Version returns the version info.
+func (c *httpServerClient) Version() (*scheme.Version, error) {
+	out := new(scheme.Version)
+	if err := c.get(false, nil, out, serverVersionURI); err != nil {
+		return nil, errors.Wrap(err, "failed to request `/version` endpoint")
+	}
+	return out, nil
+}
+
+// Config returns the config info.
+func (c *httpServerClient) Config() (*scheme.Config, error) {
+	out := new(scheme.Config)
+	if err := c.get(true, nil, out, serverConfigURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Plugins returns the summary of all plugins registered with the server.
+func (c *httpServerClient) Plugins() ([]*scheme.PluginMeta, error) {
+	var out []*scheme.PluginMeta
+	if err := c.get(true, nil, &out, serverPluginURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Plugin returns data from a specific plugin.
+func (c *httpServerClient) Plugin(id string) (*scheme.Plugin, error) {
+	out := new(scheme.Plugin)
+	if err := c.get(true, nil, out, serverPluginURI, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PluginHealth returns the summary of the health of registered plugins.
+func (c *httpServerClient) PluginHealth() (*scheme.PluginHealth, error) {
+	out := new(scheme.PluginHealth)
+	if err := c.get(true, nil, out, serverHealthURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Scan returns the devices which the server knows about.
+func (c *httpServerClient) Scan(opts scheme.ScanOptions) ([]*scheme.Scan, error) {
+	var out []*scheme.Scan
+	if err := c.get(true, opts, &out, serverScanURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Tags returns the tags currently associated with devices.
+func (c *httpServerClient) Tags(opts scheme.TagsOptions) ([]string, error) {
+	var out []string
+	if err := c.get(true, opts, &out, serverTagsURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Info returns the meta info and capabilities of a specific device.
+func (c *httpServerClient) Info(id string) (*scheme.Info, error) {
+	out := new(scheme.Info)
+	if err := c.get(true, nil, out, serverInfoURI, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Read returns data from the devices which match the options.
+func (c *httpServerClient) Read(opts scheme.ReadOptions) ([]*scheme.Read, error) {
+	var out []*scheme.Read
+	if err := c.get(true, opts, &out, serverReadURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadDevice returns data from a specific device.
+func (c *httpServerClient) ReadDevice(id string) ([]*scheme.Read, error) {
+	var out []*scheme.Read
+	if err := c.get(true, nil, &out, serverReadURI, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadCache returns cached reading data from the registered plugins. The
+// readings are sent to the channel as they are decoded from the response.
+func (c *httpServerClient) ReadCache(opts scheme.ReadCacheOptions, out chan<- *scheme.Read) error {
+	defer close(out)
+
+	errScheme := new(scheme.Error)
+	resp, err := c.client.R().
+		SetDoNotParseResponse(true).
+		SetQueryParamsFromValues(queryParams(opts)).
+		SetError(errScheme).
+		Get(c.opts.serverURL(c.scheme, true, serverReadCacheURI))
+	if err := checkResponse(err, errScheme); err != nil {
+		return err
+	}
+	body := resp.RawBody()
+	defer body.Close() // nolint: errcheck
+
+	// The error response is not parsed for a raw response.
+	if resp.IsError() {
+		if err := json.NewDecoder(body).Decode(errScheme); err != nil {
+			return errors.Errorf("got a %d response from synse server", resp.StatusCode())
+		}
+		return checkResponse(nil, errScheme)
+	}
+
+	dec := json.NewDecoder(body)
+	for dec.More() {
+		read := new(scheme.Read)
+		if err := dec.Decode(read); err != nil {
+			return errors.Wrap(err, "failed to decode a JSON response into an appropriate struct")
+		}
+		out <- read
+	}
+	return nil
+}
+
+// ReadStream is not supported by the HTTP API.
+func (c *httpServerClient) ReadStream(opts scheme.ReadStreamOptions, out chan<- *scheme.Read, stop chan struct{}) error {
+	return errors.New("Streamed readings is not currently supported via the HTTP API")
+}
+
+// WriteAsync writes data to a device, without waiting for it to complete.
+func (c *httpServerClient) WriteAsync(id string, opts []scheme.WriteData) ([]*scheme.Write, error) {
+	var out []*scheme.Write
+	if err := c.post(opts, &out, serverWriteURI, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WriteSync writes data to a device, waiting for the write to complete.
+func (c *httpServerClient) WriteSync(id string, opts []scheme.WriteData) ([]*scheme.Transaction, error) {
+	var out []*scheme.Transaction
+	if err := c.post(opts, &out, serverWriteWaitURI, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Transactions returns the sorted list of all cached transaction IDs.
+func (c *httpServerClient) Transactions() ([]string, error) {
+	var out []string
+	if err := c.get(true, nil, &out, serverTxnURI); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Transaction returns the state and status of a write transaction.
+func (c *httpServerClient) Transaction(id string) (*scheme.Transaction, error) {
+	out := new(scheme.Transaction)
+	if err := c.get(true, nil, out, serverTxnURI, id); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// get makes a GET request to the route, with the params (a struct of
+// options) as the query parameters.
+func (c *httpServerClient) get(versioned bool, params interface{}, out interface{}, path ...string) error {
+	errScheme := new(scheme.Error)
+	req := c.client.R().SetResult(out).SetError(errScheme)
+	if params != nil {
+		req.SetQueryParamsFromValues(queryParams(params))
+	}
+	_, err := req.Get(c.opts.serverURL(c.scheme, versioned, path...))
+	return checkResponse(err, errScheme)
+}
+
+// post makes a POST request to the versioned route with the body.
+func (c *httpServerClient) post(body interface{}, out interface{}, path ...string) error {
+	errScheme := new(scheme.Error)
+	_, err := c.client.R().
+		SetBody(body).
+		SetResult(out).
+		SetError(errScheme).
+		Post(c.opts.serverURL(c.scheme, true, path...))
+	return checkResponse(err, errScheme)
+}
+
+// checkResponse checks the result of a request to the server.
+func checkResponse(err error, errResp *scheme.Error) error {
+	if err != nil {
+		return errors.Wrap(err, "failed to make a request to synse server")
+	}
+	if *errResp != (scheme.Error{}) {
+		return serverError(errResp)
+	}
+	return nil
+}
+
+// serverError creates an error from an error response from the server.
+func serverError(e *scheme.Error) error {
+	return errors.Errorf(
+		"got a %v error response from synse server at %v, saying %v, with context: %v",
+		e.HTTPCode, e.Timestamp, e.Description, e.Context,
+	)
+}
+
+// queryParams gets the fields of a struct of options as query parameters,
+// named for the lowercased field names.
+func queryParams(s interface{}) url.Values {
+	out := url.Values{}
+	v := reflect.ValueOf(s)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := v.Field(i)
+		var values []string
+		if field.Kind() == reflect.Slice {
+			for j := 0; j < field.Len(); j++ {
+				values = append(values, fmt.Sprint(field.Index(j)))
+			}
+		} else {
+			values = append(values, fmt.Sprint(field))
+		}
+		out[strings.ToLower(t.Field(i).Name)] = values
+	}
+	return out
+}
@@ -0,0 +1,134 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+// newTestHTTPServerClient creates an HTTP client for a test server with the
+// handler.
+func newTestHTTPServerClient(t *testing.T, handler http.HandlerFunc) *httpServerClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := newHTTPServerClient(serverClientOptions{
+		address: strings.TrimPrefix(server.URL, "http://"),
+		noRetry: true,
+		header:  http.Header{"X-Api-Key": {"123"}},
+	}, http.DefaultTransport)
+	assert.NoError(t, err)
+	return client.(*httpServerClient)
+}
+
+func TestHTTPServerClient_noAddress(t *testing.T) {
+	_, err := newHTTPServerClient(serverClientOptions{}, http.DefaultTransport)
+	assert.EqualError(t, err, "failed to create a http client: no address is specified")
+}
+
+func TestHTTPServerClient_Status(t *testing.T) {
+	client := newTestHTTPServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/test", r.URL.Path)
+		assert.Equal(t, "123", r.Header.Get("X-Api-Key"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","timestamp":"2019-04-22T13:30:00Z"}`))
+	})
+
+	status, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func TestHTTPServerClient_Scan(t *testing.T) {
+	client := newTestHTTPServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/scan", r.URL.Path)
+		assert.Equal(t, []string{"default/foo", "default/bar"}, r.URL.Query()["tags"])
+		assert.Equal(t, "true", r.URL.Query().Get("force"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"1"},{"id":"2"}]`))
+	})
+
+	devices, err := client.Scan(scheme.ScanOptions{Tags: []string{"default/foo", "default/bar"}, Force: true})
+	assert.NoError(t, err)
+	assert.Len(t, devices, 2)
+	assert.Equal(t, "2", devices[1].ID)
+}
+
+func TestHTTPServerClient_WriteSync(t *testing.T) {
+	client := newTestHTTPServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v3/write/wait/123", r.URL.Path)
+
+		var body []scheme.WriteData
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.Equal(t, []scheme.WriteData{{Action: "state", Data: "on"}}, body)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[{"id":"txn","status":"DONE"}]`))
+	})
+
+	txns, err := client.WriteSync("123", []scheme.WriteData{{Action: "state", Data: "on"}})
+	assert.NoError(t, err)
+	assert.Len(t, txns, 1)
+	assert.Equal(t, "DONE", txns[0].Status)
+}
+
+func TestHTTPServerClient_errorResponse(t *testing.T) {
+	client := newTestHTTPServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"http_code":404,"description":"device not found","timestamp":"now","context":"123"}`))
+	})
+
+	_, err := client.Info("123")
+	assert.EqualError(t, err, "got a 404 error response from synse server at now, saying device not found, with context: 123")
+}
+
+func TestHTTPServerClient_ReadCache(t *testing.T) {
+	client := newTestHTTPServerClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/readcache", r.URL.Path)
+		assert.Equal(t, "2019-04-22T13:30:00Z", r.URL.Query().Get("start"))
+		_, _ = w.Write([]byte(`{"device":"1"}` + "\n" + `{"device":"2"}` + "\n"))
+	})
+
+	readings := make(chan *scheme.Read, 10)
+	assert.NoError(t, client.ReadCache(scheme.ReadCacheOptions{Start: "2019-04-22T13:30:00Z"}, readings))
+
+	var devices []string
+	for r := range readings {
+		devices = append(devices, r.Device)
+	}
+	assert.Equal(t, []string{"1", "2"}, devices)
+}
+
+func TestHTTPServerClient_GetOptions(t *testing.T) {
+	client, err := newHTTPServerClient(serverClientOptions{address: "localhost:5000"}, http.DefaultTransport)
+	assert.NoError(t, err)
+
+	opts := client.GetOptions()
+	assert.Equal(t, "localhost:5000", opts.Address)
+	assert.Equal(t, defaultHTTPTimeout, opts.HTTP.Timeout)
+	assert.Equal(t, uint(defaultRetryCount), opts.HTTP.Retry.Count)
+	assert.False(t, opts.TLS.Enabled)
+}
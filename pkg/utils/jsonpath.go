@@ -0,0 +1,642 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath template, in the form used by kubectl. It
+// is text with actions in braces, each of which is a path expression (e.g.
+// {.items[*].name}), a quoted string (e.g. {"\n"}), or a {range PATH} loop
+// which is closed by {end}. A template with no braces is a single path.
+//
+// Paths are made up of fields (.name or ['name']), wildcards (.* or [*]),
+// recursive descent (..name), indexes ([0], [-1]), slices ([1:3]) and
+// filters ([?(@.type=="led")], [?(@.info)]). They start at the current
+// element ('@', the default) or at the root ('$'). Missing fields select
+// nothing, rather than being errors.
+type jsonPath struct {
+	nodes []jpNode
+}
+
+// jpNode is a node of a parsed JSONPath template.
+type jpNode interface {
+	exec(w io.Writer, root, cur interface{}) error
+}
+
+// jpText is literal text in a JSONPath template.
+type jpText string
+
+func (t jpText) exec(w io.Writer, _, _ interface{}) error {
+	_, err := io.WriteString(w, string(t))
+	return err
+}
+
+// jpPath is a path expression, which writes the values it selects
+// separated by spaces.
+type jpPath struct {
+	fromRoot bool
+	steps    []jpStep
+}
+
+func (p jpPath) eval(root, cur interface{}) []interface{} {
+	values := []interface{}{cur}
+	if p.fromRoot {
+		values = []interface{}{root}
+	}
+	for _, step := range p.steps {
+		values = step.apply(root, values)
+	}
+	return values
+}
+
+func (p jpPath) exec(w io.Writer, root, cur interface{}) error {
+	var out []string
+	for _, v := range p.eval(root, cur) {
+		s, err := formatJSONPathValue(v)
+		if err != nil {
+			return err
+		}
+		out = append(out, s)
+	}
+	_, err := io.WriteString(w, strings.Join(out, " "))
+	return err
+}
+
+// jpRange executes its body for each of the values selected by its path.
+type jpRange struct {
+	path jpPath
+	body []jpNode
+}
+
+func (r *jpRange) exec(w io.Writer, root, cur interface{}) error {
+	for _, v := range r.path.eval(root, cur) {
+		for _, n := range r.body {
+			if err := n.exec(w, root, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Execute writes the template for the data to the writer. The data should
+// be as decoded from JSON (see decodeJSONData).
+func (j *jsonPath) Execute(w io.Writer, data interface{}) error {
+	for _, n := range j.nodes {
+		if err := n.exec(w, data, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatJSONPathValue formats a selected value for output. Strings are
+// written as they are, and other values as JSON.
+func formatJSONPathValue(v interface{}) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// parseJSONPath parses a JSONPath template.
+func parseJSONPath(text string) (*jsonPath, error) {
+	if !strings.Contains(text, "{") {
+		text = "{" + text + "}"
+	}
+
+	root := &jpRange{}
+	stack := []*jpRange{root}
+	for text != "" {
+		start := strings.IndexByte(text, '{')
+		if start == -1 {
+			start = len(text)
+		}
+		top := stack[len(stack)-1]
+		if start != 0 {
+			top.body = append(top.body, jpText(text[:start]))
+			text = text[start:]
+			continue
+		}
+
+		end, err := closingIndex(text, '{', '}')
+		if err != nil {
+			return nil, err
+		}
+		action := strings.TrimSpace(text[1:end])
+		text = text[end+1:]
+
+		switch {
+		case action == "end":
+			if len(stack) == 1 {
+				return nil, errors.New("{end} without {range}")
+			}
+			stack = stack[:len(stack)-1]
+
+		case strings.HasPrefix(action, "range "):
+			path, err := parseJSONPathExpr(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, err
+			}
+			r := &jpRange{path: path}
+			top.body = append(top.body, r)
+			stack = append(stack, r)
+
+		case strings.HasPrefix(action, `"`) || strings.HasPrefix(action, `'`):
+			s, err := unquoteJSONPath(action)
+			if err != nil {
+				return nil, err
+			}
+			top.body = append(top.body, jpText(s))
+
+		default:
+			path, err := parseJSONPathExpr(action)
+			if err != nil {
+				return nil, err
+			}
+			top.body = append(top.body, path)
+		}
+	}
+	if len(stack) != 1 {
+		return nil, errors.New("{range} without {end}")
+	}
+	return &jsonPath{nodes: root.body}, nil
+}
+
+// closingIndex gets the index of the bracket which closes the one at the
+// start of the text, skipping over quoted strings.
+func closingIndex(text string, open, close byte) (int, error) {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == open:
+			depth++
+		case c == close:
+			depth--
+			if depth == 0 {
+				return i, nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("unclosed '%c' in '%s'", open, text)
+}
+
+// unquoteJSONPath unquotes a single or double quoted string.
+func unquoteJSONPath(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		s = `"` + strings.ReplaceAll(strings.ReplaceAll(s[1:len(s)-1], `\'`, `'`), `"`, `\"`) + `"`
+	}
+	unquoted, err := strconv.Unquote(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return unquoted, nil
+}
+
+// parseJSONPathExpr parses a path expression, e.g. .items[*].name.
+func parseJSONPathExpr(expr string) (jpPath, error) {
+	var p jpPath
+	s := expr
+	switch {
+	case strings.HasPrefix(s, "$"):
+		p.fromRoot = true
+		s = s[1:]
+	case strings.HasPrefix(s, "@"):
+		s = s[1:]
+	case s != "" && s[0] != '.' && s[0] != '[':
+		// Allow the leading dot to be left out, e.g. {items[0]}.
+		s = "." + s
+	}
+
+	for s != "" {
+		switch {
+		case strings.HasPrefix(s, ".."):
+			s = s[2:]
+			name := jsonPathName(s)
+			if strings.HasPrefix(s, "*") {
+				name = "*"
+			}
+			if name == "" {
+				return p, fmt.Errorf("missing field name after '..' in '%s'", expr)
+			}
+			s = s[len(name):]
+			p.steps = append(p.steps, jpRecursive(name))
+
+		case s[0] == '.':
+			s = s[1:]
+			// A bare dot may come before a subscript, e.g. {.[*].name}.
+			if s == "" || s[0] == '[' {
+				break
+			}
+			if s[0] == '*' {
+				s = s[1:]
+				p.steps = append(p.steps, jpWildcard{})
+				break
+			}
+			name := jsonPathName(s)
+			if name == "" {
+				return p, fmt.Errorf("missing field name in '%s'", expr)
+			}
+			s = s[len(name):]
+			p.steps = append(p.steps, jpField(name))
+
+		case s[0] == '[':
+			end, err := closingIndex(s, '[', ']')
+			if err != nil {
+				return p, err
+			}
+			step, err := parseJSONPathSubscript(strings.TrimSpace(s[1:end]))
+			if err != nil {
+				return p, fmt.Errorf("%v in '%s'", err, expr)
+			}
+			s = s[end+1:]
+			p.steps = append(p.steps, step)
+
+		default:
+			return p, fmt.Errorf("unexpected '%c' in '%s'", s[0], expr)
+		}
+	}
+	return p, nil
+}
+
+// jsonPathName gets the field name at the start of a path.
+func jsonPathName(s string) string {
+	end := strings.IndexAny(s, ".[")
+	if end == -1 {
+		return s
+	}
+	return s[:end]
+}
+
+// parseJSONPathSubscript parses the contents of a [] subscript.
+func parseJSONPathSubscript(sub string) (jpStep, error) {
+	switch {
+	case sub == "*":
+		return jpWildcard{}, nil
+
+	case strings.HasPrefix(sub, "?(") && strings.HasSuffix(sub, ")"):
+		return parseJSONPathFilter(strings.TrimSpace(sub[2 : len(sub)-1]))
+
+	case strings.HasPrefix(sub, `"`) || strings.HasPrefix(sub, `'`):
+		name, err := unquoteJSONPath(sub)
+		if err != nil {
+			return nil, err
+		}
+		return jpField(name), nil
+
+	case strings.Contains(sub, ":"):
+		var bounds [2]*int
+		for i, b := range strings.SplitN(sub, ":", 2) {
+			b = strings.TrimSpace(b)
+			if b == "" {
+				continue
+			}
+			n, err := strconv.Atoi(b)
+			if err != nil {
+				return nil, fmt.Errorf("invalid slice [%s]", sub)
+			}
+			bounds[i] = &n
+		}
+		return jpSlice{start: bounds[0], end: bounds[1]}, nil
+	}
+
+	n, err := strconv.Atoi(sub)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subscript [%s]", sub)
+	}
+	return jpIndex(n), nil
+}
+
+// jsonPathOps are the comparison operators of filters. Operators which are
+// prefixes of others come after them.
+var jsonPathOps = []string{"==", "!=", "<=", ">=", "<", ">"}
+
+// parseJSONPathFilter parses a filter expression, e.g. @.type=="led".
+func parseJSONPathFilter(expr string) (jpStep, error) {
+	f := jpFilter{}
+	left := expr
+	if i, op := jsonPathOpIndex(expr); op != "" {
+		f.op = op
+		left = strings.TrimSpace(expr[:i])
+		value, err := parseJSONPathLiteral(strings.TrimSpace(expr[i+len(op):]))
+		if err != nil {
+			return nil, err
+		}
+		f.value = value
+	}
+	if !strings.HasPrefix(left, "@") {
+		return nil, fmt.Errorf("filter '%s' must start with '@'", expr)
+	}
+	path, err := parseJSONPathExpr(left)
+	if err != nil {
+		return nil, err
+	}
+	f.path = path
+	return f, nil
+}
+
+// jsonPathOpIndex finds the first comparison operator in a filter
+// expression which is not in a quoted string.
+func jsonPathOpIndex(expr string) (int, string) {
+	var quote byte
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		default:
+			for _, op := range jsonPathOps {
+				if strings.HasPrefix(expr[i:], op) {
+					return i, op
+				}
+			}
+		}
+	}
+	return -1, ""
+}
+
+// parseJSONPathLiteral parses a literal value in a filter: a quoted string,
+// a number, true, false or null.
+func parseJSONPathLiteral(s string) (interface{}, error) {
+	if strings.HasPrefix(s, `"`) || strings.HasPrefix(s, `'`) {
+		return unquoteJSONPath(s)
+	}
+	switch s {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if n, err := strconv.ParseFloat(s, 64); err == nil {
+		return n, nil
+	}
+	return nil, fmt.Errorf("invalid value '%s' in filter", s)
+}
+
+// jpStep is a step of a path expression, which selects values from each of
+// the values selected by the previous step.
+type jpStep interface {
+	apply(root interface{}, values []interface{}) []interface{}
+}
+
+// jpField selects the named field of objects.
+type jpField string
+
+func (f jpField) apply(_ interface{}, values []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range values {
+		if m, ok := v.(map[string]interface{}); ok {
+			if field, ok := m[string(f)]; ok {
+				out = append(out, field)
+			}
+		}
+	}
+	return out
+}
+
+// jpWildcard selects the elements of arrays and the fields of objects.
+type jpWildcard struct{}
+
+func (jpWildcard) apply(_ interface{}, values []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range values {
+		out = append(out, jsonPathChildren(v)...)
+	}
+	return out
+}
+
+// jpIndex selects an element of arrays. Negative indexes count back from
+// the end of the array.
+type jpIndex int
+
+func (i jpIndex) apply(_ interface{}, values []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range values {
+		if a, ok := v.([]interface{}); ok {
+			n := int(i)
+			if n < 0 {
+				n += len(a)
+			}
+			if n >= 0 && n < len(a) {
+				out = append(out, a[n])
+			}
+		}
+	}
+	return out
+}
+
+// jpSlice selects a range of the elements of arrays.
+type jpSlice struct {
+	start, end *int
+}
+
+func (s jpSlice) apply(_ interface{}, values []interface{}) []interface{} {
+	bound := func(b *int, def, length int) int {
+		if b == nil {
+			return def
+		}
+		n := *b
+		if n < 0 {
+			n += length
+		}
+		if n < 0 {
+			return 0
+		}
+		if n > length {
+			return length
+		}
+		return n
+	}
+
+	var out []interface{}
+	for _, v := range values {
+		if a, ok := v.([]interface{}); ok {
+			start, end := bound(s.start, 0, len(a)), bound(s.end, len(a), len(a))
+			if start < end {
+				out = append(out, a[start:end]...)
+			}
+		}
+	}
+	return out
+}
+
+// jpRecursive selects the named field, or all values for '*', at any depth.
+type jpRecursive string
+
+func (r jpRecursive) apply(_ interface{}, values []interface{}) []interface{} {
+	var out []interface{}
+	var walk func(v interface{})
+	walk = func(v interface{}) {
+		if m, ok := v.(map[string]interface{}); ok && r != "*" {
+			if field, ok := m[string(r)]; ok {
+				out = append(out, field)
+			}
+		}
+		for _, child := range jsonPathChildren(v) {
+			if r == "*" {
+				out = append(out, child)
+			}
+			walk(child)
+		}
+	}
+	for _, v := range values {
+		walk(v)
+	}
+	return out
+}
+
+// jpFilter selects the elements of arrays for which the path selects a
+// value, and, if there is an operator, the value compares true with the
+// filter's value.
+type jpFilter struct {
+	path  jpPath
+	op    string
+	value interface{}
+}
+
+func (f jpFilter) apply(root interface{}, values []interface{}) []interface{} {
+	var out []interface{}
+	for _, v := range values {
+		a, ok := v.([]interface{})
+		if !ok {
+			a = []interface{}{v}
+		}
+		for _, elem := range a {
+			selected := f.path.eval(root, elem)
+			if len(selected) == 0 {
+				continue
+			}
+			if f.op == "" || compareJSONPath(selected[0], f.op, f.value) {
+				out = append(out, elem)
+			}
+		}
+	}
+	return out
+}
+
+// compareJSONPath compares a selected value with a filter value. Numbers
+// are compared numerically, strings lexically, and other values may only
+// be compared for equality.
+func compareJSONPath(v interface{}, op string, value interface{}) bool {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		if err != nil {
+			return false
+		}
+		v = f
+	}
+
+	var cmp int
+	switch a := v.(type) {
+	case float64:
+		b, ok := value.(float64)
+		if !ok {
+			return op == "!="
+		}
+		cmp = compareOrdered(a, b)
+	case string:
+		b, ok := value.(string)
+		if !ok {
+			return op == "!="
+		}
+		cmp = strings.Compare(a, b)
+	default:
+		equal := reflect.DeepEqual(v, value)
+		switch op {
+		case "==":
+			return equal
+		case "!=":
+			return !equal
+		}
+		return false
+	}
+
+	switch op {
+	case "==":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func compareOrdered(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// jsonPathChildren gets the elements of an array, or the fields of an
+// object in the order of their names.
+func jsonPathChildren(v interface{}) []interface{} {
+	switch t := v.(type) {
+	case []interface{}:
+		return t
+	case map[string]interface{}:
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		children := make([]interface{}, 0, len(keys))
+		for _, k := range keys {
+			children = append(children, t[k])
+		}
+		return children
+	}
+	return nil
+}
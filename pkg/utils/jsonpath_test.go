@@ -0,0 +1,145 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var jsonPathData = map[string]interface{}{
+	"kind": "devices",
+	"devices": []interface{}{
+		map[string]interface{}{
+			"id":   "1",
+			"type": "temperature",
+			"info": "cpu temperature",
+			"tags": []interface{}{"system/type:temperature", "vapor/rack:1"},
+			"data": map[string]interface{}{"value": 21.5},
+		},
+		map[string]interface{}{
+			"id":   "2",
+			"type": "led",
+			"tags": []interface{}{"system/type:led"},
+			"data": map[string]interface{}{"value": "on"},
+		},
+		map[string]interface{}{
+			"id":   "3",
+			"type": "temperature",
+			"tags": []interface{}{},
+			"data": map[string]interface{}{"value": 30.0},
+		},
+	},
+}
+
+func TestJSONPath_Execute(t *testing.T) {
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{template: "{.kind}", expected: "devices"},
+		{template: ".kind", expected: "devices"},
+		{template: "{$.kind}", expected: "devices"},
+		{template: "{['kind']}", expected: "devices"},
+		{template: "kind: {.kind}", expected: "kind: devices"},
+		{template: "{.missing}", expected: ""},
+		{template: "{.devices[*].id}", expected: "1 2 3"},
+		{template: "{.devices.*.id}", expected: "1 2 3"},
+		{template: "{.devices[0].id}", expected: "1"},
+		{template: "{.devices[-1].id}", expected: "3"},
+		{template: "{.devices[5].id}", expected: ""},
+		{template: "{.devices[0:2].id}", expected: "1 2"},
+		{template: "{.devices[1:].id}", expected: "2 3"},
+		{template: "{.devices[0].tags}", expected: `["system/type:temperature","vapor/rack:1"]`},
+		{template: "{.devices[0].data}", expected: `{"value":21.5}`},
+		{template: "{..value}", expected: "21.5 on 30"},
+		{template: `{.devices[?(@.type=="led")].id}`, expected: "2"},
+		{template: `{.devices[?(@.type!="led")].id}`, expected: "1 3"},
+		{template: `{.devices[?(@.data.value>25)].id}`, expected: "3"},
+		{template: `{.devices[?(@.data.value<=21.5)].id}`, expected: "1"},
+		{template: `{.devices[?(@.info)].id}`, expected: "1"},
+		{template: `{.devices[?(@.type=='a==b')].id}`, expected: ""},
+		{template: `{range .devices[*]}{.id}{"\t"}{.type}{"\n"}{end}`, expected: "1\ttemperature\n2\tled\n3\ttemperature\n"},
+		{template: `{range .devices[*]}{range .tags[*]}{@}{","}{end}{end}`, expected: "system/type:temperature,vapor/rack:1,system/type:led,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			j, err := parseJSONPath(tt.template)
+			assert.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			assert.NoError(t, j.Execute(out, jsonPathData))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestJSONPath_ExecuteList(t *testing.T) {
+	// Output such as 'server scan' is a list at the top level.
+	data := jsonPathData["devices"]
+
+	tests := []struct {
+		template string
+		expected string
+	}{
+		{template: "{[*].id}", expected: "1 2 3"},
+		{template: "{.[*].id}", expected: "1 2 3"},
+		{template: "{$[*].id}", expected: "1 2 3"},
+		{template: "{$.[*].id}", expected: "1 2 3"},
+		{template: "{.[0].id}", expected: "1"},
+		{template: "{.[-1].id}", expected: "3"},
+		{template: "{.[1:].type}", expected: "led temperature"},
+		{template: "{.*.id}", expected: "1 2 3"},
+		{template: "{..id}", expected: "1 2 3"},
+		{template: `{.[?(@.type=="temperature")].id}`, expected: "1 3"},
+		{template: `{range .[*]}{.id}{"\n"}{end}`, expected: "1\n2\n3\n"},
+		{template: `{range .[?(@.type=="led")]}{.id}{":"}{.data.value}{end}`, expected: "2:on"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			j, err := parseJSONPath(tt.template)
+			assert.NoError(t, err)
+
+			out := &bytes.Buffer{}
+			assert.NoError(t, j.Execute(out, data))
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestParseJSONPath_errors(t *testing.T) {
+	tests := []string{
+		"{.kind",
+		"{end}",
+		"{range .devices[*]}{.id}",
+		`{"unterminated}`,
+		"{.devices[}",
+		"{.devices[a:b]}",
+		"{.devices[?(@.type==)]}",
+	}
+
+	for _, tt := range tests {
+		t.Run(tt, func(t *testing.T) {
+			_, err := parseJSONPath(tt)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,333 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+)
+
+// The output formats supported by the -o/--output flag.
+const (
+	OutputTable        = "table"
+	OutputWide         = "wide"
+	OutputCSV          = "csv"
+	OutputTSV          = "tsv"
+	OutputJSON         = "json"
+	OutputYAML         = "yaml"
+	OutputNDJSON       = "ndjson"
+	OutputJSONPath     = "jsonpath"
+	OutputGoTemplate   = "go-template"
+	OutputTemplateFile = "template-file"
+)
+
+// ErrMultipleOutputs is the error for a command given more than one of the
+// output format flags.
+var ErrMultipleOutputs = errors.New("cannot use multiple formatting flags at once")
+
+// outputFormats describes the supported output formats, for help and error
+// messages.
+const outputFormats = "table, wide, csv, tsv, json, ndjson, yaml, jsonpath=TEMPLATE, go-template=TEMPLATE, template-file=PATH"
+
+// Output is the output format of a command, set with the -o/--output flag.
+// It implements pflag.Value, so that the format, and any template it has,
+// is checked when the flag is parsed, before the command makes a request.
+type Output struct {
+	// Format is the name of the output format, e.g. "json". It is empty if
+	// the format was not set, in which case the command's default is used.
+	Format string
+
+	// Arg is the argument of the jsonpath, go-template and template-file
+	// formats: the template, or the path of the template file.
+	Arg string
+
+	// Fields are the names of the columns to include in table, wide, CSV
+	// and TSV output, set with the --fields flag. If no fields are set, all
+	// columns are included.
+	Fields []string
+
+	// Columns are custom columns for table, wide, CSV and TSV output, set
+	// with the --columns flag. If any are set, they are used in place of
+	// the command's columns.
+	Columns []Column
+
+	// Filter selects the items of the data which are printed, and SortBy
+	// are the keys they are sorted by. They are set with the --filter and
+	// --sort-by flags, for commands which have them (see AddQueryFlags).
+	Filter *Filter
+	SortBy []SortKey
+
+	// flag is the name of the flag which set the format.
+	flag string
+
+	jsonPath *jsonPath
+	template *template.Template
+}
+
+// ParseOutput parses an output format, e.g. "yaml" or "jsonpath={.name}".
+func ParseOutput(value string) (Output, error) {
+	format, arg, hasArg := strings.Cut(value, "=")
+	o := Output{Format: format, Arg: arg}
+
+	switch format {
+	case OutputTable, OutputWide, OutputCSV, OutputTSV, OutputJSON, OutputNDJSON, OutputYAML:
+		if hasArg {
+			return Output{}, fmt.Errorf("output format '%s' does not take an argument", format)
+		}
+		return o, nil
+
+	case OutputJSONPath, OutputGoTemplate, OutputTemplateFile:
+		if arg == "" {
+			return Output{}, fmt.Errorf("output format '%s' requires an argument (e.g. %s=...)", format, format)
+		}
+	default:
+		return Output{}, fmt.Errorf("unsupported output format '%s' (supported: %s)", value, outputFormats)
+	}
+
+	var err error
+	switch format {
+	case OutputJSONPath:
+		o.jsonPath, err = parseJSONPath(arg)
+		if err != nil {
+			return Output{}, fmt.Errorf("invalid jsonpath template: %v", err)
+		}
+
+	case OutputGoTemplate:
+		o.template, err = template.New("output").Parse(arg)
+		if err != nil {
+			return Output{}, fmt.Errorf("invalid go-template: %v", err)
+		}
+
+	case OutputTemplateFile:
+		text, err := os.ReadFile(arg)
+		if err != nil {
+			return Output{}, fmt.Errorf("cannot read template file: %v", err)
+		}
+		o.template, err = template.New(arg).Parse(string(text))
+		if err != nil {
+			return Output{}, fmt.Errorf("invalid template file: %v", err)
+		}
+	}
+	return o, nil
+}
+
+// String gets the output format as it is given to the -o/--output flag. If
+// no format was set, the table format is used.
+func (o *Output) String() string {
+	if o.Format == "" {
+		return OutputTable
+	}
+	if o.Arg != "" {
+		return o.Format + "=" + o.Arg
+	}
+	return o.Format
+}
+
+// Set sets the output format from the -o/--output flag.
+func (o *Output) Set(value string) error {
+	return o.set(value, "output")
+}
+
+// Type gets the type name of the flag value, for help messages.
+func (o *Output) Type() string {
+	return "format"
+}
+
+// set sets the output format from the named flag. The format may be set by
+// the same flag more than once, in which case the last value is used, but
+// it may not be set by different flags.
+func (o *Output) set(value, flag string) error {
+	if o.flag != "" && o.flag != flag {
+		return ErrMultipleOutputs
+	}
+	parsed, err := ParseOutput(value)
+	if err != nil {
+		return err
+	}
+
+	// Only the format is set; the columns, filter and sorting set by the
+	// other output flags are kept.
+	o.Format = parsed.Format
+	o.Arg = parsed.Arg
+	o.flag = flag
+	o.jsonPath = parsed.jsonPath
+	o.template = parsed.template
+	return nil
+}
+
+// WithDefault gets the output format, or the given format if none was set.
+func (o Output) WithDefault(format string) Output {
+	if o.Format == "" {
+		o.Format = format
+	}
+	return o
+}
+
+// IsTable checks whether the output is a table, which is the case for the
+// table and wide formats, and if no format was set.
+func (o Output) IsTable() bool {
+	return o.Format == "" || o.Format == OutputTable || o.Format == OutputWide
+}
+
+// IsDelimited checks whether the output is CSV or TSV.
+func (o Output) IsDelimited() bool {
+	return o.Format == OutputCSV || o.Format == OutputTSV
+}
+
+// IsStreamable checks whether the output can be written an item at a time,
+// which is the case for tables, CSV, TSV and NDJSON.
+func (o Output) IsStreamable() bool {
+	return o.IsTable() || o.IsDelimited() || o.Format == OutputNDJSON
+}
+
+// outputAlias is the value of the --json and --yaml flags, which are kept
+// as aliases of -o json and -o yaml.
+type outputAlias struct {
+	output *Output
+	format string
+}
+
+func (a *outputAlias) String() string {
+	return strconv.FormatBool(a.output.Format == a.format)
+}
+
+func (a *outputAlias) Set(value string) error {
+	set, err := strconv.ParseBool(value)
+	if err != nil || !set {
+		return err
+	}
+	return a.output.set(a.format, a.format)
+}
+
+func (a *outputAlias) Type() string {
+	return "bool"
+}
+
+// columnsValue is the value of the --columns flag, which sets the custom
+// columns of an output.
+type columnsValue struct {
+	output *Output
+}
+
+func (c *columnsValue) String() string {
+	specs := make([]string, 0, len(c.output.Columns))
+	for _, column := range c.output.Columns {
+		specs = append(specs, column.String())
+	}
+	return strings.Join(specs, ",")
+}
+
+func (c *columnsValue) Set(value string) error {
+	columns, err := ParseColumns(value)
+	if err != nil {
+		return err
+	}
+	c.output.Columns = columns
+	return nil
+}
+
+func (c *columnsValue) Type() string {
+	return "columns"
+}
+
+// AddOutputFlags adds the output flags to a command's flags. The -o/--output
+// flag sets the output format. The --fields flag selects the columns of
+// tabular output, and the --columns flag sets custom columns for it.
+//
+// The --json and --yaml flags are also added as hidden aliases of -o json and
+// -o yaml, for compatibility.
+func AddOutputFlags(flags *pflag.FlagSet, output *Output) {
+	flags.VarP(output, "output", "o", "output format: "+outputFormats)
+	flags.Lookup("output").DefValue = output.String()
+	flags.StringSliceVar(&output.Fields, "fields", []string{}, "columns to include in table, wide, csv and tsv output, by header name (e.g. ID,VALUE)")
+	flags.Var(&columnsValue{output: output}, "columns", "custom columns for table, wide, csv and tsv output, as NAME:JSONPATH pairs evaluated against the JSON output (e.g. ID:.id,TAGS:.tags[*])")
+	for _, format := range []string{OutputJSON, OutputYAML} {
+		flags.Var(&outputAlias{output: output, format: format}, format, "print output as "+strings.ToUpper(format)+" (same as -o "+format+")")
+		f := flags.Lookup(format)
+		f.NoOptDefVal = "true"
+		f.Hidden = true
+	}
+}
+
+// filterValue is the value of the --filter flag, which sets the filter of
+// an output.
+type filterValue struct {
+	output *Output
+}
+
+func (f *filterValue) String() string {
+	if f.output.Filter == nil {
+		return ""
+	}
+	return f.output.Filter.String()
+}
+
+func (f *filterValue) Set(value string) error {
+	filter, err := ParseFilter(value)
+	if err != nil {
+		return err
+	}
+	f.output.Filter = filter
+	return nil
+}
+
+func (f *filterValue) Type() string {
+	return "expression"
+}
+
+// sortByValue is the value of the --sort-by flag, which sets the sort keys
+// of an output. Keys given to the flag more than once are added to those
+// given before.
+type sortByValue struct {
+	output *Output
+}
+
+func (s *sortByValue) String() string {
+	keys := make([]string, 0, len(s.output.SortBy))
+	for _, k := range s.output.SortBy {
+		keys = append(keys, k.String())
+	}
+	return strings.Join(keys, ",")
+}
+
+func (s *sortByValue) Set(value string) error {
+	keys, err := ParseSortKeys(value)
+	if err != nil {
+		return err
+	}
+	s.output.SortBy = append(s.output.SortBy, keys...)
+	return nil
+}
+
+func (s *sortByValue) Type() string {
+	return "fields"
+}
+
+// AddQueryFlags adds the --filter flag, which selects the items of a
+// command's results, and the --sort-by flag, which sorts them, to a
+// command's flags. Both are evaluated against the JSON form of each item,
+// so they apply to commands whose results are lists of items.
+func AddQueryFlags(flags *pflag.FlagSet, output *Output) {
+	flags.Var(&filterValue{output: output}, "filter", `only print the items which match an expression on their JSON fields (e.g. 'type == "temperature" && value > 35')`)
+	flags.Var(&sortByValue{output: output}, "sort-by", "sort the items by fields of their JSON output, each optionally followed by :asc or :desc (e.g. type,value:desc)")
+}
@@ -0,0 +1,250 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOutput(t *testing.T) {
+	tests := []struct {
+		value  string
+		format string
+		arg    string
+	}{
+		{value: "table", format: OutputTable},
+		{value: "wide", format: OutputWide},
+		{value: "csv", format: OutputCSV},
+		{value: "tsv", format: OutputTSV},
+		{value: "ndjson", format: OutputNDJSON},
+		{value: "json", format: OutputJSON},
+		{value: "yaml", format: OutputYAML},
+		{value: "jsonpath={.name}", format: OutputJSONPath, arg: "{.name}"},
+		{value: "jsonpath=.name", format: OutputJSONPath, arg: ".name"},
+		{value: "go-template={{.name}}", format: OutputGoTemplate, arg: "{{.name}}"},
+		{value: "go-template={{if eq .a \"b=c\"}}x{{end}}", format: OutputGoTemplate, arg: "{{if eq .a \"b=c\"}}x{{end}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			o, err := ParseOutput(tt.value)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.format, o.Format)
+			assert.Equal(t, tt.arg, o.Arg)
+			assert.Equal(t, tt.value, o.String())
+		})
+	}
+}
+
+func TestParseOutput_templateFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "output.tmpl")
+	assert.NoError(t, os.WriteFile(path, []byte("{{.name}}\n"), 0600))
+
+	o, err := ParseOutput("template-file=" + path)
+	assert.NoError(t, err)
+	assert.Equal(t, OutputTemplateFile, o.Format)
+	assert.Equal(t, path, o.Arg)
+	assert.NotNil(t, o.template)
+}
+
+func TestParseOutput_errors(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{
+			value: "",
+			err:   "unsupported output format '' (supported: table, wide, csv, tsv, json, ndjson, yaml, jsonpath=TEMPLATE, go-template=TEMPLATE, template-file=PATH)",
+		},
+		{
+			value: "xml",
+			err:   "unsupported output format 'xml' (supported: table, wide, csv, tsv, json, ndjson, yaml, jsonpath=TEMPLATE, go-template=TEMPLATE, template-file=PATH)",
+		},
+		{
+			value: "json=pretty",
+			err:   "output format 'json' does not take an argument",
+		},
+		{
+			value: "jsonpath",
+			err:   "output format 'jsonpath' requires an argument (e.g. jsonpath=...)",
+		},
+		{
+			value: "go-template=",
+			err:   "output format 'go-template' requires an argument (e.g. go-template=...)",
+		},
+		{
+			value: "jsonpath={.name",
+			err:   "invalid jsonpath template: unclosed '{' in '{.name'",
+		},
+		{
+			value: "go-template={{.name}",
+			err:   "invalid go-template: template: output:1: bad character U+007D '}'",
+		},
+		{
+			value: "template-file=/does/not/exist",
+			err:   "cannot read template file: open /does/not/exist: no such file or directory",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			_, err := ParseOutput(tt.value)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestOutput_WithDefault(t *testing.T) {
+	assert.Equal(t, OutputJSON, Output{}.WithDefault(OutputJSON).Format)
+	assert.Equal(t, OutputYAML, Output{Format: OutputYAML}.WithDefault(OutputJSON).Format)
+}
+
+func TestOutput_IsTable(t *testing.T) {
+	assert.True(t, Output{}.IsTable())
+	assert.True(t, Output{Format: OutputTable}.IsTable())
+	assert.True(t, Output{Format: OutputWide}.IsTable())
+	assert.False(t, Output{Format: OutputJSON}.IsTable())
+	assert.False(t, Output{Format: OutputJSONPath}.IsTable())
+}
+
+func TestAddOutputFlags(t *testing.T) {
+	tests := []struct {
+		args   []string
+		format string
+	}{
+		{args: nil, format: ""},
+		{args: []string{"-o", "wide"}, format: OutputWide},
+		{args: []string{"--output=yaml"}, format: OutputYAML},
+		{args: []string{"--json"}, format: OutputJSON},
+		{args: []string{"--yaml"}, format: OutputYAML},
+		{args: []string{"--json=false"}, format: ""},
+		{args: []string{"-o", "json", "-o", "yaml"}, format: OutputYAML},
+		{args: []string{"--json", "--json"}, format: OutputJSON},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			var output Output
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			AddOutputFlags(flags, &output)
+
+			assert.NoError(t, flags.Parse(tt.args))
+			assert.Equal(t, tt.format, output.Format)
+		})
+	}
+}
+
+func TestAddOutputFlags_fields(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddOutputFlags(flags, &output)
+
+	assert.NoError(t, flags.Parse([]string{"--fields", "id,value", "-o", "csv", "--fields", "unit"}))
+	assert.Equal(t, OutputCSV, output.Format)
+	assert.Equal(t, []string{"id", "value", "unit"}, output.Fields)
+}
+
+func TestAddOutputFlags_columns(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddOutputFlags(flags, &output)
+
+	assert.NoError(t, flags.Parse([]string{"--columns", "ID:.id,TAGS:{.tags[*]}", "-o", "wide"}))
+	assert.Equal(t, OutputWide, output.Format)
+	assert.Equal(t, []string{"ID", "TAGS"}, columnNames(output.Columns))
+	assert.Equal(t, "ID:.id,TAGS:{.tags[*]}", flags.Lookup("columns").Value.String())
+}
+
+func TestAddOutputFlags_columnsErr(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddOutputFlags(flags, &output)
+
+	err := flags.Parse([]string{"--columns", "ID"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid column 'ID': expected NAME:PATH")
+}
+
+func TestAddQueryFlags(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddOutputFlags(flags, &output)
+	AddQueryFlags(flags, &output)
+
+	assert.NoError(t, flags.Parse([]string{
+		"--sort-by", "type", "--filter", `value > 35`, "-o", "json", "--sort-by", "value:desc",
+	}))
+	assert.Equal(t, OutputJSON, output.Format)
+	assert.Equal(t, "value > 35", flags.Lookup("filter").Value.String())
+	assert.Equal(t, "type,value:desc", flags.Lookup("sort-by").Value.String())
+	assert.NotNil(t, output.Filter)
+	assert.Len(t, output.SortBy, 2)
+}
+
+func TestAddQueryFlags_error(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddQueryFlags(flags, &output)
+
+	err := flags.Parse([]string{"--filter", "value >"})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid filter 'value >': unexpected end of expression")
+}
+
+func TestOutput_IsDelimited(t *testing.T) {
+	assert.True(t, Output{Format: OutputCSV}.IsDelimited())
+	assert.True(t, Output{Format: OutputTSV}.IsDelimited())
+	assert.False(t, Output{}.IsDelimited())
+	assert.False(t, Output{Format: OutputTable}.IsDelimited())
+}
+
+func TestAddOutputFlags_multipleFormats(t *testing.T) {
+	tests := [][]string{
+		{"--json", "--yaml"},
+		{"-o", "json", "--yaml"},
+		{"--json", "-o", "table"},
+	}
+
+	for _, args := range tests {
+		t.Run(strings.Join(args, " "), func(t *testing.T) {
+			var output Output
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			AddOutputFlags(flags, &output)
+
+			err := flags.Parse(args)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), ErrMultipleOutputs.Error())
+		})
+	}
+}
+
+func TestAddOutputFlags_hiddenAliases(t *testing.T) {
+	var output Output
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	AddOutputFlags(flags, &output)
+
+	assert.False(t, flags.Lookup("output").Hidden)
+	assert.Equal(t, OutputTable, flags.Lookup("output").DefValue)
+	assert.True(t, flags.Lookup("json").Hidden)
+	assert.True(t, flags.Lookup("yaml").Hidden)
+}
@@ -17,12 +17,15 @@
 package utils
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
 	"reflect"
 	"strings"
 
+	"github.com/liggitt/tabwriter"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 )
@@ -35,9 +38,7 @@ var (
 
 // Printer defines the printing capabilities for CLI output.
 type Printer struct {
-	table    bool
-	json     bool
-	yaml     bool
+	output   Output
 	noHeader bool
 
 	// nativeYaml is a flag to denote whether a YAML output should be encoded
@@ -52,6 +53,11 @@ type Printer struct {
 	// printing out in tabular form.
 	rowFunc func(data interface{}) ([]interface{}, error)
 
+	// wideColumns are the columns which are added to those of the table
+	// for wide output. If there are none, wide output is the same as table
+	// output.
+	wideColumns []Column
+
 	// transformFunc is an optional function which may be specified which
 	// causes an additional step in data marshalling, where the data is marshaled
 	// into a map[string]interface{} and is passed to this function. This function
@@ -59,39 +65,47 @@ type Printer struct {
 	// printed out. Note that this only applies to YAML and JSON outputs.
 	transformFunc func(data map[string]interface{}) error
 
+	// lessFunc is an optional function which orders the data written with
+	// a Stream. If it is set, the stream holds all of the data until it is
+	// closed, so that it can be sorted.
+	lessFunc func(a, b interface{}) bool
+
 	header []string
 	out    io.Writer
 }
 
-// NewPrinter creates a new printer to use for output formatting.
-func NewPrinter(out io.Writer, useJSON, useYaml, noHeader bool) *Printer {
-	useTable := true
-	if useJSON || useYaml {
-		useTable = false
-	}
-
+// NewPrinter creates a new printer to use for output formatting. If the
+// output has no format set, the data is written as a table.
+func NewPrinter(out io.Writer, output Output, noHeader bool) *Printer {
 	return &Printer{
-		table:      useTable,
-		json:       useJSON,
-		yaml:       useYaml,
+		output:     output.WithDefault(OutputTable),
 		noHeader:   noHeader,
 		nativeYaml: true,
 		out:        out,
 	}
 }
 
-// Write writes the data to the Printer's specified output.
+// Write writes the data to the Printer's specified output. If the data is a
+// slice, only its items which match the output's filter are written, sorted
+// by the output's sort keys.
 func (p *Printer) Write(data interface{}) error {
-	if p.table {
-		return p.toTable(data)
+	data, err := p.query(data)
+	if err != nil {
+		return err
+	}
 
-	} else if p.json {
+	switch p.output.Format {
+	case OutputTable, OutputWide, OutputCSV, OutputTSV:
+		return p.toTable(data)
+	case OutputJSON:
 		return p.toJSON(data)
-
-	} else if p.yaml {
+	case OutputNDJSON:
+		return p.toNDJSON(data)
+	case OutputYAML:
 		return p.toYAML(data)
+	case OutputJSONPath, OutputGoTemplate, OutputTemplateFile:
+		return p.toTemplate(data)
 	}
-
 	return ErrNoOutputMode
 }
 
@@ -113,54 +127,313 @@ func (p *Printer) SetTransformFunc(f func(data map[string]interface{}) error) {
 	p.transformFunc = f
 }
 
+// SetLessFunc sets the function which orders the data written with a
+// Stream. This is optional; without it, a stream writes data in the order
+// it is received.
+func (p *Printer) SetLessFunc(f func(a, b interface{}) bool) {
+	p.lessFunc = f
+}
+
 // SetHeader sets the column header row for tabular formatting.
 func (p *Printer) SetHeader(header ...string) {
 	p.header = header
 }
 
-// toTable prints the data out in tabular format.
+// SetWideColumns sets the columns which are added to those of the table for
+// wide output, e.g. the tags of a device.
+func (p *Printer) SetWideColumns(columns ...Column) {
+	p.wideColumns = columns
+}
+
+// columns gets the header and row function for the table output. Columns
+// set with --columns replace those of the table, and wide output adds the
+// wide columns to them.
+func (p *Printer) columns() ([]string, func(data interface{}) ([]interface{}, error)) {
+	if len(p.output.Columns) != 0 {
+		return columnNames(p.output.Columns), p.columnRowFunc(nil, p.output.Columns)
+	}
+	if p.output.Format == OutputWide && len(p.wideColumns) != 0 && p.rowFunc != nil {
+		header := append(append([]string{}, p.header...), columnNames(p.wideColumns)...)
+		return header, p.columnRowFunc(p.rowFunc, p.wideColumns)
+	}
+	return p.header, p.rowFunc
+}
+
+// WriteContexts writes the merged results of a request made against
+// multiple contexts to the Printer's specified output.
+//
+// Tables get a leading CONTEXT column. JSON and YAML output is a list with
+// an item for each context, holding the context name and either the data
+// for the context or the error the request failed with. Contexts with no
+// data are omitted from tables; if none have data, nothing is written.
+func (p *Printer) WriteContexts(results []ContextResult) error {
+	if p.output.IsTable() || p.output.IsDelimited() {
+		return p.toContextTable(results)
+	}
+
+	results, err := p.queryContexts(results)
+	if err != nil {
+		return err
+	}
+
+	var output []contextOutput
+	for _, r := range results {
+		o := contextOutput{
+			Context: r.Context,
+			Data:    r.Data,
+		}
+		if r.Err != nil {
+			o.Error = r.Err.Error()
+		} else if r.Data != nil && p.transformFunc != nil {
+			data, err := p.transform(r.Data)
+			if err != nil {
+				return err
+			}
+			o.Data = data
+		}
+		output = append(output, o)
+	}
+
+	switch p.output.Format {
+	case OutputJSON:
+		return p.writeJSON(output)
+	case OutputNDJSON:
+		for _, o := range output {
+			if err := p.writeNDJSON(o); err != nil {
+				return err
+			}
+		}
+		return nil
+	case OutputYAML:
+		return p.writeYAML(output)
+	case OutputJSONPath, OutputGoTemplate, OutputTemplateFile:
+		return p.writeTemplate(output)
+	}
+	return ErrNoOutputMode
+}
+
+// queryContexts filters and sorts the data for each context (see query).
+func (p *Printer) queryContexts(results []ContextResult) ([]ContextResult, error) {
+	queried := make([]ContextResult, 0, len(results))
+	for _, r := range results {
+		if r.Data != nil {
+			data, err := p.query(r.Data)
+			if err != nil {
+				return nil, err
+			}
+			r.Data = data
+		}
+		queried = append(queried, r)
+	}
+	return queried, nil
+}
+
+// contextOutput is the JSON and YAML output for the result of a request
+// made against a context.
+type contextOutput struct {
+	Context string      `json:"context" yaml:"context"`
+	Data    interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	Error   string      `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// toTable prints the data out in tabular format, as a table or as CSV or TSV.
 func (p *Printer) toTable(data interface{}) error {
-	if p.rowFunc == nil {
+	header, rowFunc := p.columns()
+	if rowFunc == nil {
 		return ErrNoRowFunc
 	}
+	fields, err := p.fieldIndexes(header)
+	if err != nil {
+		return err
+	}
+
+	w := newRowWriter(p.out, p.output.Format)
+	defer w.Flush()
+
+	if err := p.writeHeader(w, selectFields(fields, header)); err != nil {
+		return err
+	}
+
+	rows, err := tableRows(rowFunc, data)
+	if err != nil {
+		return err
+	}
+	return writeRows(w, fields, rows)
+}
+
+// toContextTable prints the data for each context out in tabular format,
+// with a leading column for the context name.
+func (p *Printer) toContextTable(results []ContextResult) error {
+	header, rowFunc := p.columns()
+	if rowFunc == nil {
+		return ErrNoRowFunc
+	}
+	header = append([]string{"CONTEXT"}, header...)
+	results, err := p.queryContexts(results)
+	if err != nil {
+		return err
+	}
+	fields, err := p.fieldIndexes(header)
+	if err != nil {
+		return err
+	}
+
+	var rows [][]interface{}
+	for _, r := range results {
+		if r.Data == nil {
+			continue
+		}
+		ctxRows, err := tableRows(rowFunc, r.Data)
+		if err != nil {
+			return err
+		}
+		for _, row := range ctxRows {
+			rows = append(rows, append([]interface{}{r.Context}, row...))
+		}
+	}
+	if len(rows) == 0 {
+		return nil
+	}
 
-	w := NewTabWriter(p.out)
+	w := newRowWriter(p.out, p.output.Format)
 	defer w.Flush()
 
-	if err := p.writeHeader(w); err != nil {
+	if err := p.writeHeader(w, selectFields(fields, header)); err != nil {
 		return err
 	}
+	return writeRows(w, fields, rows)
+}
 
+// tableRows gets the table rows for the data, using the row function for each
+// element if the data is a slice.
+func tableRows(rowFunc func(data interface{}) ([]interface{}, error), data interface{}) ([][]interface{}, error) {
 	var rows [][]interface{}
 	switch reflect.TypeOf(data).Kind() {
 	case reflect.Slice:
 		s := reflect.ValueOf(data)
 
 		for i := 0; i < s.Len(); i++ {
-			row, err := p.rowFunc(s.Index(i).Interface())
+			row, err := rowFunc(s.Index(i).Interface())
 			if err != nil {
-				return err
+				return nil, err
 			}
 			rows = append(rows, row)
 		}
 	default:
-		row, err := p.rowFunc(data)
+		row, err := rowFunc(data)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		rows = append(rows, row)
 	}
+	return rows, nil
+}
+
+// fieldIndexes gets the indexes of the columns selected with --fields, in
+// the order they were given. Fields are matched to the header names without
+// regard to case. If no fields were given, all columns are used and the
+// indexes are nil.
+func (p *Printer) fieldIndexes(header []string) ([]int, error) {
+	if len(p.output.Fields) == 0 {
+		return nil, nil
+	}
 
+	var indexes []int
+	for _, field := range p.output.Fields {
+		index := -1
+		for i, name := range header {
+			if strings.EqualFold(field, name) {
+				index = i
+				break
+			}
+		}
+		if index == -1 {
+			return nil, fmt.Errorf("unknown field '%s' (fields: %s)", field, strings.Join(header, ", "))
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
+
+// selectFields gets the cells of a row at the field indexes. If there are
+// no field indexes, the row is returned as it is.
+func selectFields(fields []int, row []string) []string {
+	if fields == nil {
+		return row
+	}
+	selected := make([]string, 0, len(fields))
+	for _, i := range fields {
+		if i < len(row) {
+			selected = append(selected, row[i])
+		}
+	}
+	return selected
+}
+
+// writeRows writes the table rows out, with only the columns at the field
+// indexes if any are given.
+func writeRows(w rowWriter, fields []int, rows [][]interface{}) error {
 	for _, row := range rows {
-		fmtstr := "%v" + strings.Repeat("\t%v", len(row)-1) + "\n"
-		_, err := fmt.Fprintf(w, fmtstr, row...)
-		if err != nil {
+		cells := make([]string, 0, len(row))
+		for _, v := range row {
+			cells = append(cells, fmt.Sprint(v))
+		}
+		if err := w.Write(selectFields(fields, cells)); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// rowWriter writes the cells of table rows out, either aligned in columns
+// or as CSV or TSV records.
+type rowWriter interface {
+	Write(cells []string) error
+	Flush() error
+}
+
+// newRowWriter creates a rowWriter for the output format.
+func newRowWriter(out io.Writer, format string) rowWriter {
+	switch format {
+	case OutputCSV:
+		return &delimitedWriter{w: csv.NewWriter(out)}
+	case OutputTSV:
+		w := csv.NewWriter(out)
+		w.Comma = '\t'
+		return &delimitedWriter{w: w}
+	}
+	return &tabRowWriter{w: NewTabWriter(out)}
+}
+
+// tabRowWriter writes rows aligned in columns.
+type tabRowWriter struct {
+	w *tabwriter.Writer
+}
+
+func (t *tabRowWriter) Write(cells []string) error {
+	_, err := io.WriteString(t.w, strings.Join(cells, "\t")+"\n")
+	return err
+}
+
+func (t *tabRowWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// delimitedWriter writes rows as CSV or TSV records. Cells which contain
+// the delimiter, quotes or line breaks are quoted.
+type delimitedWriter struct {
+	w *csv.Writer
+}
+
+func (d *delimitedWriter) Write(cells []string) error {
+	return d.w.Write(cells)
+}
+
+func (d *delimitedWriter) Flush() error {
+	d.w.Flush()
+	return d.w.Error()
+}
+
 // transform takes the data to output, converts it to a map, and passes it to
 // the printer's transform function. This is process is a bit obtuse/hacky, but
 // there are not many other options for rectifying yaml/json output easily.
@@ -170,7 +443,7 @@ func (p *Printer) transform(data interface{}) (interface{}, error) {
 	// within the slice.
 	if reflect.TypeOf(data).Kind() == reflect.Slice {
 		s := reflect.ValueOf(data)
-		var res []interface{}
+		res := make([]interface{}, 0, s.Len())
 		for i := 0; i < s.Len(); i++ {
 			t, err := p.transform(s.Index(i).Interface())
 			if err != nil {
@@ -209,7 +482,11 @@ func (p *Printer) toJSON(data interface{}) error {
 			return err
 		}
 	}
+	return p.writeJSON(data)
+}
 
+// writeJSON writes the data out as JSON.
+func (p *Printer) writeJSON(data interface{}) error {
 	output, err := json.MarshalIndent(data, "", "  ")
 	if err != nil {
 		return err
@@ -218,12 +495,92 @@ func (p *Printer) toJSON(data interface{}) error {
 	return err
 }
 
+// toNDJSON prints the data out as newline delimited JSON, with a line for
+// each element if the data is a slice.
+func (p *Printer) toNDJSON(data interface{}) error {
+	if reflect.TypeOf(data).Kind() == reflect.Slice {
+		s := reflect.ValueOf(data)
+		for i := 0; i < s.Len(); i++ {
+			if err := p.toNDJSON(s.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if p.transformFunc != nil {
+		data, err = p.transform(data)
+		if err != nil {
+			return err
+		}
+	}
+	return p.writeNDJSON(data)
+}
+
+// writeNDJSON writes the data out as a single line of JSON.
+func (p *Printer) writeNDJSON(data interface{}) error {
+	output, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = p.out.Write(append(output, '\n'))
+	return err
+}
+
+// toTemplate prints the data out using the JSONPath or Go template of the
+// output format.
+func (p *Printer) toTemplate(data interface{}) error {
+	var err error
+
+	if p.transformFunc != nil {
+		data, err = p.transform(data)
+		if err != nil {
+			return err
+		}
+	}
+	return p.writeTemplate(data)
+}
+
+// writeTemplate writes the data out using the JSONPath or Go template of
+// the output format. The template is executed for the data as it is
+// decoded from its JSON output, so fields are named as they are in the
+// JSON output.
+func (p *Printer) writeTemplate(data interface{}) error {
+	decoded, err := decodeJSONData(data)
+	if err != nil {
+		return err
+	}
+
+	if p.output.jsonPath != nil {
+		return p.output.jsonPath.Execute(p.out, decoded)
+	}
+	if p.output.template != nil {
+		return p.output.template.Execute(p.out, decoded)
+	}
+	return ErrNoOutputMode
+}
+
+// decodeJSONData gets the data as it is decoded from its JSON encoding:
+// objects are maps, arrays are slices and numbers are json.Numbers.
+func decodeJSONData(data interface{}) (interface{}, error) {
+	j, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	d := json.NewDecoder(bytes.NewReader(j))
+	d.UseNumber()
+	if err := d.Decode(&decoded); err != nil {
+		return nil, err
+	}
+	return decoded, nil
+}
+
 // toYAML prints the data out in YAML format.
 func (p *Printer) toYAML(data interface{}) error {
-	var (
-		output []byte
-		err    error
-	)
+	var err error
 
 	if p.transformFunc != nil {
 		data, err = p.transform(data)
@@ -231,6 +588,15 @@ func (p *Printer) toYAML(data interface{}) error {
 			return err
 		}
 	}
+	return p.writeYAML(data)
+}
+
+// writeYAML writes the data out as YAML.
+func (p *Printer) writeYAML(data interface{}) error {
+	var (
+		output []byte
+		err    error
+	)
 
 	if p.nativeYaml {
 		output, err = yaml.Marshal(data)
@@ -247,10 +613,9 @@ func (p *Printer) toYAML(data interface{}) error {
 
 // writeHeader is a helper function to write the header row out
 // when headers are enabled in tabular output.
-func (p *Printer) writeHeader(out io.Writer) error {
+func (p *Printer) writeHeader(w rowWriter, header []string) error {
 	if !p.noHeader {
-		_, err := fmt.Fprintf(out, "%s\n", strings.Join(p.header, "\t"))
-		return err
+		return w.Write(header)
 	}
 	return nil
 }
@@ -31,29 +31,23 @@ type testOutput struct {
 }
 
 func TestNewPrinter_Table(t *testing.T) {
-	p := NewPrinter(&bytes.Buffer{}, false, false, false)
+	p := NewPrinter(&bytes.Buffer{}, Output{}, false)
 
-	assert.True(t, p.table)
-	assert.False(t, p.json)
-	assert.False(t, p.yaml)
+	assert.Equal(t, OutputTable, p.output.Format)
 	assert.False(t, p.noHeader)
 }
 
 func TestNewPrinter_JSON(t *testing.T) {
-	p := NewPrinter(&bytes.Buffer{}, true, false, false)
+	p := NewPrinter(&bytes.Buffer{}, Output{Format: OutputJSON}, false)
 
-	assert.False(t, p.table)
-	assert.True(t, p.json)
-	assert.False(t, p.yaml)
+	assert.Equal(t, OutputJSON, p.output.Format)
 	assert.False(t, p.noHeader)
 }
 
 func TestNewPrinter_Yaml(t *testing.T) {
-	p := NewPrinter(&bytes.Buffer{}, false, true, false)
+	p := NewPrinter(&bytes.Buffer{}, Output{Format: OutputYAML}, false)
 
-	assert.False(t, p.table)
-	assert.False(t, p.json)
-	assert.True(t, p.yaml)
+	assert.Equal(t, OutputYAML, p.output.Format)
 	assert.False(t, p.noHeader)
 }
 
@@ -84,6 +78,265 @@ func TestPrinter_Write_err(t *testing.T) {
 	assert.Equal(t, ErrNoOutputMode, err)
 }
 
+func TestPrinter_Write_jsonPath(t *testing.T) {
+	output, err := ParseOutput(`jsonpath={range [*]}{.foo}={.bar}{"\n"}{end}`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, output, false)
+
+	err = p.Write([]*testOutput{{Foo: "one", Bar: 1}, {Foo: "two", Bar: 2}})
+	assert.NoError(t, err)
+	assert.Equal(t, "one=1\ntwo=2\n", out.String())
+}
+
+func TestPrinter_Write_goTemplate(t *testing.T) {
+	output, err := ParseOutput(`go-template={{.foo}} is {{.bar}}`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, output, false)
+	p.SetTransformFunc(func(data map[string]interface{}) error {
+		data["foo"] = "TEST"
+		return nil
+	})
+
+	err = p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST is 2", out.String())
+}
+
+func TestPrinter_Write_templateErr(t *testing.T) {
+	output, err := ParseOutput(`go-template={{.foo.bar.baz}}`)
+	assert.NoError(t, err)
+
+	p := NewPrinter(&bytes.Buffer{}, output, false)
+	assert.Error(t, p.Write(&testOutput{Foo: "test", Bar: 2}))
+}
+
+func TestPrinter_Write_wide(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputWide}, false)
+	p.SetHeader("FOO")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data.(*testOutput).Foo}, nil
+	})
+	p.SetWideColumns(MustParseColumns("BAR:.bar")...)
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO    BAR
+			test   2
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_wideFuncColumn(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputWide}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+	p.SetWideColumns(FuncColumn("DOUBLE", func(data interface{}) (interface{}, error) {
+		return data.(*testOutput).Bar * 2, nil
+	}))
+
+	err := p.Write([]*testOutput{{Foo: "a", Bar: 1}, {Foo: "b", Bar: 2}})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO   BAR   DOUBLE
+			a     1     2
+			b     2     4
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_tableNoWideColumns(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputTable}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+	p.SetWideColumns(MustParseColumns("BAZ:.foo")...)
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO    BAR
+			test   2
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_columns(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Columns: MustParseColumns("NAME:.foo,COUNT:{.bar}")}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.Write([]*testOutput{{Foo: "a", Bar: 1}, {Foo: "b", Bar: 2}})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			NAME   COUNT
+			a      1
+			b      2
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_columnsNoRowFunc(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, Columns: MustParseColumns("NAME:.foo,MISSING:.baz")}, false)
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "NAME,MISSING\ntest,\n", out.String())
+}
+
+func TestPrinter_Write_columnsTransform(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputWide, Columns: MustParseColumns("FOO:.foo")}, true)
+	p.SetTransformFunc(func(data map[string]interface{}) error {
+		data["foo"] = "TEST"
+		return nil
+	})
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "TEST\n", out.String())
+}
+
+func TestPrinter_Write_columnsFields(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Columns: MustParseColumns("NAME:.foo,COUNT:.bar"), Fields: []string{"count"}}, false)
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			COUNT
+			2
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_wideNoWideRowFunc(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputWide}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO    BAR
+			test   2
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_csv(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.Write([]*testOutput{
+		{Foo: "a,b", Bar: 1},
+		{Foo: `say "hi"`, Bar: 2},
+		{Foo: "100%", Bar: 3},
+		{Foo: "two\nlines", Bar: 4},
+	})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO,BAR
+			"a,b",1
+			"say ""hi""",2
+			100%,3
+			"two
+			lines",4
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_tsv(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputTSV}, true)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.Write([]*testOutput{
+		{Foo: "a,b", Bar: 1},
+		{Foo: "a\tb", Bar: 2},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "a,b\t1\n\"a\tb\"\t2\n", out.String())
+}
+
+func TestPrinter_Write_fields(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Fields: []string{"bar", "FOO"}}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.Write(&testOutput{Foo: "100%", Bar: 2})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			BAR   FOO
+			2     100%
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_unknownField(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, Fields: []string{"baz"}}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.Write(&testOutput{Foo: "test", Bar: 2})
+	assert.EqualError(t, err, "unknown field 'baz' (fields: FOO, BAR)")
+	assert.Empty(t, out.String())
+}
+
+func TestPrinter_Write_ndjson(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputNDJSON}, false)
+
+	err := p.Write([]*testOutput{{Foo: "one", Bar: 1}, {Foo: "two", Bar: 2}})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			{"foo":"one","bar":1}
+			{"foo":"two","bar":2}
+		`),
+		out.String(),
+	)
+}
+
 func TestPrinter_toJSON(t *testing.T) {
 	data := testOutput{
 		Foo: "test",
@@ -234,7 +487,9 @@ func TestPrinter_writeHeader_noHeader(t *testing.T) {
 		noHeader: true,
 	}
 
-	err := p.writeHeader(out)
+	w := newRowWriter(out, OutputTSV)
+	err := p.writeHeader(w, p.header)
+	assert.NoError(t, w.Flush())
 	assert.NoError(t, err)
 	assert.Empty(t, out.String())
 }
@@ -246,7 +501,247 @@ func TestPrinter_writeHeader_withHeader(t *testing.T) {
 		noHeader: false,
 	}
 
-	err := p.writeHeader(out)
+	w := newRowWriter(out, OutputTSV)
+	err := p.writeHeader(w, p.header)
+	assert.NoError(t, w.Flush())
 	assert.NoError(t, err)
 	assert.Equal(t, "FOO\tBAR\n", out.String())
 }
+
+// contextResults are the results of a request made against multiple contexts.
+var contextResults = []ContextResult{
+	{Context: "a", Data: []*testOutput{{Foo: "one", Bar: 1}, {Foo: "two", Bar: 2}}},
+	{Context: "b", Err: fmt.Errorf("connection refused")},
+	{Context: "c", Data: &testOutput{Foo: "three", Bar: 3}},
+	{Context: "d"},
+}
+
+func testOutputRowFunc(data interface{}) ([]interface{}, error) {
+	o := data.(*testOutput)
+	return []interface{}{o.Foo, o.Bar}, nil
+}
+
+func TestPrinter_WriteContexts_table(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.WriteContexts(contextResults)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			CONTEXT   FOO     BAR
+			a         one     1
+			a         two     2
+			c         three   3
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_wide(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputWide}, false)
+	p.SetHeader("FOO")
+	p.SetRowFunc(func(data interface{}) ([]interface{}, error) {
+		return []interface{}{data.(*testOutput).Foo}, nil
+	})
+	p.SetWideColumns(MustParseColumns("BAR:.bar")...)
+
+	err := p.WriteContexts(contextResults)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			CONTEXT   FOO     BAR
+			a         one     1
+			a         two     2
+			c         three   3
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_tableNoHeader(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, true)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.WriteContexts(contextResults)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			a     one     1
+			a     two     2
+			c     three   3
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_tableNoData(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.WriteContexts([]ContextResult{{Context: "a"}, {Context: "b", Err: fmt.Errorf("error")}})
+	assert.NoError(t, err)
+	assert.Empty(t, out.String())
+}
+
+func TestPrinter_WriteContexts_json(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputJSON}, false)
+
+	err := p.WriteContexts(contextResults)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			[
+			  {
+			    "context": "a",
+			    "data": [
+			      {
+			        "foo": "one",
+			        "bar": 1
+			      },
+			      {
+			        "foo": "two",
+			        "bar": 2
+			      }
+			    ]
+			  },
+			  {
+			    "context": "b",
+			    "error": "connection refused"
+			  },
+			  {
+			    "context": "c",
+			    "data": {
+			      "foo": "three",
+			      "bar": 3
+			    }
+			  },
+			  {
+			    "context": "d"
+			  }
+			]
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_yaml(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputYAML}, false)
+
+	err := p.WriteContexts(contextResults[:3])
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			- context: a
+			  data:
+			  - foo: one
+			    bar: 1
+			  - foo: two
+			    bar: 2
+			- context: b
+			  error: connection refused
+			- context: c
+			  data:
+			    foo: three
+			    bar: 3
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_transform(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputJSON}, false)
+	p.SetTransformFunc(func(data map[string]interface{}) error {
+		delete(data, "bar")
+		return nil
+	})
+
+	err := p.WriteContexts(contextResults[2:3])
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			[
+			  {
+			    "context": "c",
+			    "data": {
+			      "foo": "three"
+			    }
+			  }
+			]
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_jsonPath(t *testing.T) {
+	output, err := ParseOutput(`jsonpath={range [*]}{.context}={.data..foo}{.error}{"\n"}{end}`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, output, false)
+
+	err = p.WriteContexts(contextResults)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			a=one two
+			b=connection refused
+			c=three
+			d=
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_csv(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, Fields: []string{"context", "foo"}}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err := p.WriteContexts(contextResults)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			CONTEXT,FOO
+			a,one
+			a,two
+			c,three
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_WriteContexts_ndjson(t *testing.T) {
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputNDJSON}, false)
+
+	err := p.WriteContexts(contextResults[1:3])
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			{"context":"b","error":"connection refused"}
+			{"context":"c","data":{"foo":"three","bar":3}}
+		`),
+		out.String(),
+	)
+}
@@ -0,0 +1,86 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// ErrProtectedCtx is the error for a write which is refused because it is
+// made against a protected context.
+var ErrProtectedCtx = errors.New("refusing to write to protected context")
+
+// ConfirmWrite checks that a write may be made against each of the
+// contexts. Writes to protected contexts are refused unless they are
+// forced. A forced write must be confirmed by typing the name of each
+// protected context it is made against; the prompts are written to out and
+// the answers are read from in.
+func ConfirmWrite(in io.Reader, out io.Writer, force bool, contexts []*config.ContextRecord) error {
+	var protected []string
+	for _, ctx := range contexts {
+		if ctx.Protected {
+			protected = append(protected, ctx.Name)
+		}
+	}
+	if len(protected) == 0 {
+		return nil
+	}
+	if !force {
+		return fmt.Errorf("%w '%s' (use --force-protected to write to it)", ErrProtectedCtx, protected[0])
+	}
+
+	r := bufio.NewReader(in)
+	for _, name := range protected {
+		if _, err := fmt.Fprintf(out, "Context '%s' is protected. Type its name to confirm the write: ", name); err != nil {
+			return err
+		}
+		answer, err := r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if err == io.EOF {
+			// Terminate the prompt line, since no newline was entered.
+			if _, err := fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+		if strings.TrimSpace(answer) != name {
+			return fmt.Errorf("%w '%s': the write was not confirmed", ErrProtectedCtx, name)
+		}
+		log.WithField("context", name).Debug("confirmed write to protected context")
+	}
+	return nil
+}
+
+// Targets gets the context a command which is run against a single
+// context makes its request against: the named context, or the current
+// context of the type. If the context can not be resolved, there is no
+// target, and the failure is left for the request to report.
+func Targets(name, ctxType string) []*config.ContextRecord {
+	ctx, err := ResolveContext(name, ctxType)
+	if err != nil {
+		return nil
+	}
+	return []*config.ContextRecord{ctx}
+}
@@ -0,0 +1,148 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+func TestConfirmWrite_unprotected(t *testing.T) {
+	out := bytes.Buffer{}
+	err := ConfirmWrite(strings.NewReader(""), &out, false, []*config.ContextRecord{
+		{Name: "site-a", Type: "server"},
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, out.String())
+}
+
+func TestConfirmWrite_noContexts(t *testing.T) {
+	assert.NoError(t, ConfirmWrite(strings.NewReader(""), &bytes.Buffer{}, false, nil))
+}
+
+func TestConfirmWrite_notForced(t *testing.T) {
+	out := bytes.Buffer{}
+	err := ConfirmWrite(strings.NewReader("prod\n"), &out, false, []*config.ContextRecord{
+		{Name: "site-a", Type: "server"},
+		{Name: "prod", Type: "server", Protected: true},
+	})
+	assert.EqualError(t, err, "refusing to write to protected context 'prod' (use --force-protected to write to it)")
+	assert.True(t, errors.Is(err, ErrProtectedCtx))
+	assert.Empty(t, out.String())
+}
+
+func TestConfirmWrite_confirmed(t *testing.T) {
+	out := bytes.Buffer{}
+	err := ConfirmWrite(strings.NewReader("prod\n  prod-2  \n"), &out, true, []*config.ContextRecord{
+		{Name: "prod", Type: "server", Protected: true},
+		{Name: "site-a", Type: "server"},
+		{Name: "prod-2", Type: "server", Protected: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t,
+		"Context 'prod' is protected. Type its name to confirm the write: "+
+			"Context 'prod-2' is protected. Type its name to confirm the write: ",
+		out.String(),
+	)
+}
+
+func TestConfirmWrite_confirmedNoNewline(t *testing.T) {
+	out := bytes.Buffer{}
+	err := ConfirmWrite(strings.NewReader("prod"), &out, true, []*config.ContextRecord{
+		{Name: "prod", Type: "server", Protected: true},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Context 'prod' is protected. Type its name to confirm the write: \n", out.String())
+}
+
+func TestConfirmWrite_notConfirmed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"wrong name", "site-a\n"},
+		{"no input", ""},
+		{"prefix", "pro\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ConfirmWrite(strings.NewReader(tt.input), &bytes.Buffer{}, true, []*config.ContextRecord{
+				{Name: "prod", Type: "server", Protected: true},
+			})
+			assert.EqualError(t, err, "refusing to write to protected context 'prod': the write was not confirmed")
+			assert.True(t, errors.Is(err, ErrProtectedCtx))
+		})
+	}
+}
+
+func TestTargets(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	targets := Targets("site-a", "server")
+	assert.Len(t, targets, 1)
+	assert.Equal(t, "site-a", targets[0].Name)
+}
+
+func TestTargets_unresolved(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	assert.Nil(t, Targets("missing", "server"))
+	assert.Nil(t, Targets("", "server"))
+}
+
+func TestFanout_Targets(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	f := &Fanout{Selection: ContextSelection{Pattern: "site-*"}, Type: "server"}
+	targets, err := f.Targets()
+	assert.NoError(t, err)
+	var names []string
+	for _, ctx := range targets {
+		names = append(names, ctx.Name)
+	}
+	assert.Equal(t, []string{"site-a", "site-b"}, names)
+}
+
+func TestFanout_Targets_single(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	f := &Fanout{Type: "server", Context: "lab"}
+	targets, err := f.Targets()
+	assert.NoError(t, err)
+	assert.Len(t, targets, 1)
+	assert.Equal(t, "lab", targets[0].Name)
+}
+
+func TestFanout_Targets_selectionAndCtx(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, fanoutTestContexts)
+
+	f := &Fanout{Selection: ContextSelection{All: true}, Type: "server", Context: "lab"}
+	_, err := f.Targets()
+	assert.Equal(t, ErrSelectionAndCtx, err)
+}
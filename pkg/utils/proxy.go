@@ -0,0 +1,181 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	"golang.org/x/net/http/httpproxy"
+	"golang.org/x/net/proxy"
+)
+
+// dialFunc is a function which makes network connections.
+type dialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+// proxyFunc gets the function which selects the proxy for a request to the
+// component of a context. If the context has a proxy, it is used for all
+// requests. Otherwise, the proxy is selected from the environment (see
+// httpproxy.FromEnvironment); a nil URL is a direct connection.
+func proxyFunc(ctx config.Context) func(*url.URL) (*url.URL, error) {
+	if ctx.Proxy != "" {
+		proxyURL, err := url.Parse(ctx.Proxy)
+		return func(*url.URL) (*url.URL, error) {
+			return proxyURL, err
+		}
+	}
+	return httpproxy.FromEnvironment().ProxyFunc()
+}
+
+// httpProxy gets the proxy function for the transport of an HTTP client.
+func httpProxy(ctx config.Context) func(*http.Request) (*url.URL, error) {
+	proxy := proxyFunc(ctx)
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// proxyTransport creates an HTTP transport which makes its connections to
+// the component of the context through its proxy.
+func proxyTransport(ctx config.Context) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = httpProxy(ctx)
+	return transport
+}
+
+// proxyDialer creates a function which connects to the address of the
+// context through its proxy, for clients which dial the connection
+// themselves. The scheme is the one used for the connection (http, https,
+// or https for gRPC), which selects the proxy from the environment. It
+// returns nil if the connection is direct.
+func proxyDialer(ctx config.Context, scheme string) (dialFunc, error) {
+	if _, ok := config.UnixSocketPath(ctx.Address); ok {
+		return nil, nil
+	}
+
+	proxyURL, err := proxyFunc(ctx)(&url.URL{Scheme: scheme, Host: ctx.Address})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get proxy: %v", err)
+	}
+	if proxyURL == nil {
+		return nil, nil
+	}
+
+	log.WithFields(log.Fields{
+		"address": ctx.Address,
+		"proxy":   proxyURL.Redacted(),
+	}).Debug("proxy: with proxy")
+
+	switch proxyURL.Scheme {
+	case "http", "https":
+		return connectDialer(proxyURL), nil
+	case "socks5":
+		d, err := proxy.FromURL(proxyURL, &net.Dialer{})
+		if err != nil {
+			return nil, err
+		}
+		return d.(proxy.ContextDialer).DialContext, nil
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%s'", proxyURL.Scheme)
+	}
+}
+
+// connectDialer creates a function which connects to an address through a
+// tunnel opened by an HTTP proxy with a CONNECT request.
+func connectDialer(proxyURL *url.URL) dialFunc {
+	return func(ctx context.Context, network, address string) (net.Conn, error) {
+		var d net.Dialer
+		conn, err := d.DialContext(ctx, network, canonicalAddr(proxyURL))
+		if err != nil {
+			return nil, err
+		}
+		if proxyURL.Scheme == "https" {
+			conn = tls.Client(conn, &tls.Config{ServerName: proxyURL.Hostname()})
+		}
+
+		// Interrupt the CONNECT request if the context is done before the
+		// tunnel is open.
+		stop := context.AfterFunc(ctx, func() { conn.Close() })
+		defer stop()
+
+		req := &http.Request{
+			Method: http.MethodConnect,
+			URL:    &url.URL{Opaque: address},
+			Host:   address,
+			Header: http.Header{},
+		}
+		if u := proxyURL.User; u != nil {
+			password, _ := u.Password()
+			req.Header.Set("Proxy-Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(u.Username()+":"+password)))
+		}
+		if err := req.Write(conn); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect to proxy: %v", err)
+		}
+
+		br := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(br, req)
+		if err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to connect to proxy: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			conn.Close()
+			return nil, fmt.Errorf("proxy refused connection to %s: %s", address, resp.Status)
+		}
+		if !stop() {
+			return nil, ctx.Err()
+		}
+		if br.Buffered() != 0 {
+			return &bufferedConn{Conn: conn, r: br}, nil
+		}
+		return conn, nil
+	}
+}
+
+// bufferedConn is a connection with data which was read ahead of the
+// response to a CONNECT request.
+type bufferedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *bufferedConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+// canonicalAddr gets the host:port of a proxy URL, using the default port
+// of its scheme if it has none.
+func canonicalAddr(u *url.URL) string {
+	if u.Port() != "" {
+		return u.Host
+	}
+	port := "80"
+	if u.Scheme == "https" {
+		port = "443"
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,405 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// proxiedAddress is the address of the component in the proxy tests. It is
+// only reachable through the proxy stand-ins, which connect to the backend
+// for any address they are asked to connect to.
+const proxiedAddress = "synse.test:5000"
+
+// proxyStandIn is a proxy for tests which connects all requests to a
+// single backend, recording the address each request was for.
+type proxyStandIn struct {
+	url string
+
+	mu      sync.Mutex
+	targets []string
+	auth    []string
+}
+
+func (p *proxyStandIn) record(target, auth string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.targets = append(p.targets, target)
+	p.auth = append(p.auth, auth)
+}
+
+// proxied gets the addresses which requests were made to through the proxy.
+func (p *proxyStandIn) proxied() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.targets...)
+}
+
+// newHTTPProxy starts an HTTP proxy stand-in for the backend at the given
+// address. It tunnels CONNECT requests and forwards other requests.
+func newHTTPProxy(t *testing.T, backend string) *proxyStandIn {
+	p := &proxyStandIn{}
+	forward := &httputil.ReverseProxy{Director: func(r *http.Request) {
+		r.URL.Scheme = "http"
+		r.URL.Host = backend
+	}}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		p.record(r.Host, r.Header.Get("Proxy-Authorization"))
+		if r.Method != http.MethodConnect {
+			forward.ServeHTTP(w, r)
+			return
+		}
+		if r.Host == "refused.test:5000" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		upstream, err := net.Dial("tcp", backend)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			upstream.Close()
+			return
+		}
+		if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+			conn.Close()
+			upstream.Close()
+			return
+		}
+		pipe(conn, upstream)
+	}))
+	t.Cleanup(server.Close)
+	p.url = server.URL
+	return p
+}
+
+// newSOCKS5Proxy starts a SOCKS5 proxy stand-in, without authentication,
+// for the backend at the given address.
+func newSOCKS5Proxy(t *testing.T, backend string) *proxyStandIn {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { lis.Close() })
+
+	p := &proxyStandIn{url: "socks5://" + lis.Addr().String()}
+	go func() {
+		for {
+			conn, err := lis.Accept()
+			if err != nil {
+				return
+			}
+			go p.serveSOCKS5(conn, backend)
+		}
+	}()
+	return p
+}
+
+func (p *proxyStandIn) serveSOCKS5(conn net.Conn, backend string) {
+	// Greeting: version, methods. Only "no authentication" is accepted.
+	buf := make([]byte, 262)
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		conn.Close()
+		return
+	}
+	if _, err := io.ReadFull(conn, buf[:buf[1]]); err != nil {
+		conn.Close()
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		conn.Close()
+		return
+	}
+
+	// Request: version, CONNECT, reserved, address type, address, port.
+	if _, err := io.ReadFull(conn, buf[:4]); err != nil {
+		conn.Close()
+		return
+	}
+	var host string
+	switch buf[3] {
+	case 1:
+		_, _ = io.ReadFull(conn, buf[:4])
+		host = net.IP(buf[:4]).String()
+	case 3:
+		_, _ = io.ReadFull(conn, buf[:1])
+		n := int(buf[0])
+		_, _ = io.ReadFull(conn, buf[:n])
+		host = string(buf[:n])
+	case 4:
+		_, _ = io.ReadFull(conn, buf[:16])
+		host = net.IP(buf[:16]).String()
+	}
+	if _, err := io.ReadFull(conn, buf[:2]); err != nil {
+		conn.Close()
+		return
+	}
+	p.record(net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(buf[:2])))), "")
+
+	upstream, err := net.Dial("tcp", backend)
+	if err != nil {
+		_, _ = conn.Write([]byte{5, 1, 0, 1, 0, 0, 0, 0, 0, 0})
+		conn.Close()
+		return
+	}
+	if _, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0}); err != nil {
+		conn.Close()
+		upstream.Close()
+		return
+	}
+	pipe(conn, upstream)
+}
+
+// pipe copies data between the connections until either is closed.
+func pipe(a, b net.Conn) {
+	done := make(chan struct{}, 2)
+	cp := func(dst, src net.Conn) {
+		_, _ = io.Copy(dst, src)
+		done <- struct{}{}
+	}
+	go cp(a, b)
+	go cp(b, a)
+	<-done
+	a.Close()
+	b.Close()
+}
+
+// newProxies starts an HTTP and a SOCKS5 proxy stand-in for the backend.
+func newProxies(t *testing.T, backend string) map[string]*proxyStandIn {
+	return map[string]*proxyStandIn{
+		"http":   newHTTPProxy(t, backend),
+		"socks5": newSOCKS5Proxy(t, backend),
+	}
+}
+
+// newStatusServer starts an HTTP server which responds to the Synse
+// '/test' endpoint, returning its address.
+func newStatusServer(t *testing.T) string {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","timestamp":"2019-04-22T13:30:00Z"}`))
+	}))
+	t.Cleanup(server.Close)
+	return strings.TrimPrefix(server.URL, "http://")
+}
+
+func TestClientFactory_HTTP_proxy(t *testing.T) {
+	for name, proxy := range newProxies(t, newStatusServer(t)) {
+		t.Run(name, func(t *testing.T) {
+			defer config.Purge()
+			addTLSContext(t, "server", config.Context{
+				Address: proxiedAddress,
+				Proxy:   proxy.url,
+			})
+
+			client, err := NewClientFactory(ClientOptions{Context: "testctx", NoRetry: true}).HTTP()
+			assert.NoError(t, err)
+
+			s, err := client.Status()
+			assert.NoError(t, err)
+			assert.Equal(t, "ok", s.Status)
+			assert.Equal(t, []string{proxiedAddress}, proxy.proxied())
+		})
+	}
+}
+
+func TestClientFactory_HTTP_envProxy(t *testing.T) {
+	defer config.Purge()
+	proxy := newHTTPProxy(t, newStatusServer(t))
+	t.Setenv("HTTP_PROXY", proxy.url)
+
+	addTLSContext(t, "server", config.Context{
+		Address: proxiedAddress,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx", NoRetry: true}).HTTP()
+	assert.NoError(t, err)
+
+	s, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", s.Status)
+	assert.Equal(t, []string{proxiedAddress}, proxy.proxied())
+}
+
+func TestClientFactory_HTTP_noProxy(t *testing.T) {
+	defer config.Purge()
+	proxy := newHTTPProxy(t, newStatusServer(t))
+	t.Setenv("HTTP_PROXY", proxy.url)
+	t.Setenv("NO_PROXY", "synse.test")
+
+	addTLSContext(t, "server", config.Context{
+		Address: proxiedAddress,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx", NoRetry: true}).HTTP()
+	assert.NoError(t, err)
+
+	// The address is only reachable through the proxy.
+	_, err = client.Status()
+	assert.Error(t, err)
+	assert.Empty(t, proxy.proxied())
+}
+
+func TestClientFactory_WebSocket_proxy(t *testing.T) {
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}))
+	defer server.Close()
+
+	for name, proxy := range newProxies(t, strings.TrimPrefix(server.URL, "http://")) {
+		t.Run(name, func(t *testing.T) {
+			defer config.Purge()
+			addTLSContext(t, "server", config.Context{
+				Address: proxiedAddress,
+				Proxy:   proxy.url,
+			})
+
+			client, err := NewClientFactory(ClientOptions{Context: "testctx"}).WebSocket()
+			assert.NoError(t, err)
+			assert.NoError(t, client.Open())
+			assert.Equal(t, []string{proxiedAddress}, proxy.proxied())
+		})
+	}
+}
+
+func TestClientFactory_GRPC_proxy(t *testing.T) {
+	// The server has no services registered. If the connection is made,
+	// requests fail as unimplemented rather than unavailable.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	for name, proxy := range newProxies(t, lis.Addr().String()) {
+		t.Run(name, func(t *testing.T) {
+			defer config.Purge()
+			addTLSContext(t, "plugin", config.Context{
+				Address: proxiedAddress,
+				Proxy:   proxy.url,
+			})
+
+			conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
+			assert.NoError(t, err)
+			defer conn.Close()
+
+			_, err = client.Test(context.Background(), &synse.Empty{})
+			assert.Equal(t, codes.Unimplemented, status.Code(err), err)
+			assert.Equal(t, []string{proxiedAddress}, proxy.proxied())
+		})
+	}
+
+	t.Run("env", func(t *testing.T) {
+		defer config.Purge()
+		proxy := newHTTPProxy(t, lis.Addr().String())
+		t.Setenv("HTTPS_PROXY", proxy.url)
+		addTLSContext(t, "plugin", config.Context{
+			Address: proxiedAddress,
+		})
+
+		conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
+		assert.NoError(t, err)
+		defer conn.Close()
+
+		_, err = client.Test(context.Background(), &synse.Empty{})
+		assert.Equal(t, codes.Unimplemented, status.Code(err), err)
+		assert.Equal(t, []string{proxiedAddress}, proxy.proxied())
+	})
+}
+
+func TestProxyDialer_direct(t *testing.T) {
+	t.Setenv("HTTPS_PROXY", "http://proxy:3128")
+	t.Setenv("NO_PROXY", "synse.local")
+
+	for _, address := range []string{
+		"synse.local:5000",
+		"localhost:5000",
+		"unix:///tmp/synse.sock",
+	} {
+		dial, err := proxyDialer(config.Context{Address: address}, "https")
+		assert.NoError(t, err)
+		assert.Nil(t, dial, address)
+	}
+
+	dial, err := proxyDialer(config.Context{Address: proxiedAddress}, "https")
+	assert.NoError(t, err)
+	assert.NotNil(t, dial)
+}
+
+func TestConnectDialer_auth(t *testing.T) {
+	backend := newStatusServer(t)
+	proxy := newHTTPProxy(t, backend)
+	proxyURL, err := url.Parse(proxy.url)
+	assert.NoError(t, err)
+	proxyURL.User = url.UserPassword("user", "pass")
+
+	conn, err := connectDialer(proxyURL)(context.Background(), "tcp", proxiedAddress)
+	assert.NoError(t, err)
+	conn.Close()
+	assert.Equal(t, []string{"Basic dXNlcjpwYXNz"}, proxy.auth)
+}
+
+func TestConnectDialer_refused(t *testing.T) {
+	proxy := newHTTPProxy(t, newStatusServer(t))
+	proxyURL, err := url.Parse(proxy.url)
+	assert.NoError(t, err)
+
+	_, err = connectDialer(proxyURL)(context.Background(), "tcp", "refused.test:5000")
+	assert.EqualError(t, err, "proxy refused connection to refused.test:5000: 403 Forbidden")
+}
+
+func TestConnectDialer_unreachable(t *testing.T) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyURL := &url.URL{Scheme: "http", Host: lis.Addr().String()}
+	lis.Close()
+
+	_, err = connectDialer(proxyURL)(context.Background(), "tcp", proxiedAddress)
+	assert.Error(t, err)
+}
@@ -0,0 +1,584 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Filter is a parsed --filter expression, which selects the items of a
+// command's results. It is evaluated against the JSON form of each item.
+//
+// An expression is made up of comparisons of fields and values, e.g.
+// type == "temperature", joined with && and ||, negated with !, and grouped
+// with parentheses. Fields are paths as in JSONPath templates, with the
+// leading dot optional (e.g. unit.symbol, tags[0], @ for the item itself).
+// Values are quoted strings, numbers, true, false and null. The operators
+// are ==, !=, <, <=, >, >=, and =~ and !~, which match a regular
+// expression. A field which selects more than one value (e.g. tags[*])
+// compares true if any of its values do. A field on its own is true if it
+// is set and is not false or null.
+type Filter struct {
+	text string
+	expr filterExpr
+}
+
+// ParseFilter parses a filter expression. An empty expression is no filter.
+func ParseFilter(text string) (*Filter, error) {
+	if strings.TrimSpace(text) == "" {
+		return nil, nil
+	}
+
+	tokens, err := tokenizeFilter(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %v", text, err)
+	}
+	p := &filterParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected '%s'", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid filter '%s': %v", text, err)
+	}
+	return &Filter{text: text, expr: expr}, nil
+}
+
+// String gets the filter expression as it was given.
+func (f *Filter) String() string {
+	return f.text
+}
+
+// Match checks whether an item matches the filter. The item should be as
+// decoded from JSON (see decodeJSONData).
+func (f *Filter) Match(data interface{}) bool {
+	return f.expr.eval(data)
+}
+
+// filterExpr is a node of a parsed filter expression.
+type filterExpr interface {
+	eval(data interface{}) bool
+}
+
+type filterAnd struct{ left, right filterExpr }
+
+func (e filterAnd) eval(data interface{}) bool {
+	return e.left.eval(data) && e.right.eval(data)
+}
+
+type filterOr struct{ left, right filterExpr }
+
+func (e filterOr) eval(data interface{}) bool {
+	return e.left.eval(data) || e.right.eval(data)
+}
+
+type filterNot struct{ expr filterExpr }
+
+func (e filterNot) eval(data interface{}) bool {
+	return !e.expr.eval(data)
+}
+
+// filterSet is true if its operand selects a value which is not false or
+// null.
+type filterSet struct{ operand filterOperand }
+
+func (e filterSet) eval(data interface{}) bool {
+	for _, v := range e.operand.values(data) {
+		if v != nil && v != false {
+			return true
+		}
+	}
+	return false
+}
+
+// filterCompare compares the values of its operands. It is true if any
+// pair of their values compares true.
+type filterCompare struct {
+	left, right filterOperand
+	op          string
+	re          *regexp.Regexp
+}
+
+func (e filterCompare) eval(data interface{}) bool {
+	if e.re != nil {
+		for _, v := range e.left.values(data) {
+			s, err := formatJSONPathValue(v)
+			if err == nil && e.re.MatchString(s) == (e.op == "=~") {
+				return true
+			}
+		}
+		return false
+	}
+
+	right := e.right.values(data)
+	for _, l := range e.left.values(data) {
+		for _, r := range right {
+			if compareJSONPath(l, e.op, r) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// filterOperand is a field or a value in a filter expression.
+type filterOperand struct {
+	path    *jpPath
+	literal interface{}
+}
+
+// values gets the values of the operand for an item. Numbers are float64s,
+// so that they can be compared with the numbers of the expression.
+func (o filterOperand) values(data interface{}) []interface{} {
+	if o.path == nil {
+		return []interface{}{o.literal}
+	}
+	values := o.path.eval(data, data)
+	for i, v := range values {
+		if n, ok := v.(json.Number); ok {
+			if f, err := n.Float64(); err == nil {
+				values[i] = f
+			}
+		}
+	}
+	return values
+}
+
+// filterToken is a token of a filter expression. Strings have their
+// unquoted value.
+type filterToken struct {
+	text  string
+	kind  byte
+	value string
+}
+
+// The kinds of filter tokens.
+const (
+	tokenOp     = 'o'
+	tokenString = 's'
+	tokenWord   = 'w'
+)
+
+// filterOps are the operators of filter expressions. Operators which are
+// prefixes of others come after them.
+var filterOps = []string{"&&", "||", "==", "!=", "=~", "!~", "<=", ">=", "<", ">", "!", "(", ")"}
+
+// tokenizeFilter splits a filter expression into tokens.
+func tokenizeFilter(text string) ([]filterToken, error) {
+	var tokens []filterToken
+	s := text
+	for {
+		s = strings.TrimLeftFunc(s, unicode.IsSpace)
+		if s == "" {
+			return tokens, nil
+		}
+
+		if s[0] == '"' || s[0] == '\'' {
+			end := closingQuote(s)
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed string %s", s)
+			}
+			value, err := unquoteJSONPath(s[:end+1])
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, filterToken{text: s[:end+1], kind: tokenString, value: value})
+			s = s[end+1:]
+			continue
+		}
+
+		op := ""
+		for _, o := range filterOps {
+			if strings.HasPrefix(s, o) {
+				op = o
+				break
+			}
+		}
+		if op != "" {
+			tokens = append(tokens, filterToken{text: op, kind: tokenOp})
+			s = s[len(op):]
+			continue
+		}
+
+		end, err := filterWordEnd(s)
+		if err != nil {
+			return nil, err
+		}
+		if end == 0 {
+			return nil, fmt.Errorf("unexpected '%c'", s[0])
+		}
+		tokens = append(tokens, filterToken{text: s[:end], kind: tokenWord})
+		s = s[end:]
+	}
+}
+
+// closingQuote gets the index of the quote which closes the string at the
+// start of the text, or -1 if it is not closed.
+func closingQuote(s string) int {
+	for i := 1; i < len(s); i++ {
+		if s[i] == '\\' {
+			i++
+		} else if s[i] == s[0] {
+			return i
+		}
+	}
+	return -1
+}
+
+// filterWordEnd gets the end of the field or value at the start of the
+// text. Brackets in fields (e.g. tags[0]) are skipped over as a whole.
+func filterWordEnd(s string) (int, error) {
+	i := 0
+	for i < len(s) {
+		c := rune(s[i])
+		switch {
+		case c == '[':
+			end, err := closingIndex(s[i:], '[', ']')
+			if err != nil {
+				return 0, err
+			}
+			i += end + 1
+		case unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("_.@$*+-", c):
+			i++
+		default:
+			return i, nil
+		}
+	}
+	return i, nil
+}
+
+// filterParser parses the tokens of a filter expression:
+//
+//	or      = and { "||" and }
+//	and     = unary { "&&" unary }
+//	unary   = "!" unary | "(" or ")" | operand [ op operand ]
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+// next gets the next token if it is the given operator.
+func (p *filterParser) next(op string) bool {
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == tokenOp && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.next("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = filterOr{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.next("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = filterAnd{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *filterParser) parseUnary() (filterExpr, error) {
+	if p.next("!") {
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{expr: expr}, nil
+	}
+	if p.next("(") {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.next(")") {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return expr, nil
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos == len(p.tokens) || p.tokens[p.pos].kind != tokenOp {
+		return filterSet{operand: left}, nil
+	}
+
+	op := p.tokens[p.pos].text
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "=~", "!~":
+		p.pos++
+	default:
+		return filterSet{operand: left}, nil
+	}
+
+	if op == "=~" || op == "!~" {
+		if p.pos == len(p.tokens) || p.tokens[p.pos].kind != tokenString {
+			return nil, fmt.Errorf("'%s' must be followed by a quoted regular expression", op)
+		}
+		re, err := regexp.Compile(p.tokens[p.pos].value)
+		if err != nil {
+			return nil, err
+		}
+		p.pos++
+		return filterCompare{left: left, op: op, re: re}, nil
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return filterCompare{left: left, right: right, op: op}, nil
+}
+
+func (p *filterParser) parseOperand() (filterOperand, error) {
+	if p.pos == len(p.tokens) {
+		return filterOperand{}, fmt.Errorf("unexpected end of expression")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+
+	switch t.kind {
+	case tokenString:
+		return filterOperand{literal: t.value}, nil
+	case tokenOp:
+		return filterOperand{}, fmt.Errorf("unexpected '%s'", t.text)
+	}
+
+	// Words which are not fields are values: numbers, true, false and null.
+	if c := t.text[0]; c == '-' || c == '+' || (c >= '0' && c <= '9') || t.text == "true" || t.text == "false" || t.text == "null" {
+		value, err := parseJSONPathLiteral(t.text)
+		if err != nil {
+			return filterOperand{}, fmt.Errorf("invalid value '%s'", t.text)
+		}
+		return filterOperand{literal: value}, nil
+	}
+
+	path, err := parseJSONPathExpr(t.text)
+	if err != nil {
+		return filterOperand{}, err
+	}
+	return filterOperand{path: &path}, nil
+}
+
+// SortKey is a key which the items of a command's results are sorted by,
+// set with the --sort-by flag. It is a field of the JSON form of the items.
+type SortKey struct {
+	// Field is the field as it was given, e.g. "value" or ".unit.symbol".
+	Field string
+
+	// Desc is set if the items are sorted in descending order.
+	Desc bool
+
+	path jpPath
+}
+
+// String gets the sort key as it is given to the --sort-by flag.
+func (k SortKey) String() string {
+	if k.Desc {
+		return k.Field + ":desc"
+	}
+	return k.Field
+}
+
+// ParseSortKeys parses a comma separated list of sort keys, each of which
+// is a field (see Filter) optionally followed by :asc or :desc, e.g.
+// "type,value:desc". An empty list has no keys.
+func ParseSortKeys(value string) ([]SortKey, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var keys []SortKey
+	for _, spec := range splitColumns(value) {
+		field := strings.TrimSpace(spec)
+		key := SortKey{}
+		if i := strings.LastIndex(field, ":"); i != -1 {
+			switch strings.ToLower(field[i+1:]) {
+			case "desc":
+				key.Desc = true
+				field = field[:i]
+			case "asc":
+				field = field[:i]
+			}
+		}
+		if field == "" {
+			return nil, fmt.Errorf("invalid sort key '%s': expected FIELD[:asc|:desc]", spec)
+		}
+
+		path, err := parseJSONPathExpr(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sort key '%s': %v", spec, err)
+		}
+		key.Field = field
+		key.path = path
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// lessBySortKeys checks whether item a comes before item b when they are
+// sorted by the keys. The items should be as decoded from JSON.
+func lessBySortKeys(keys []SortKey, a, b interface{}) bool {
+	for _, k := range keys {
+		cmp := compareSortValues(k.path.eval(a, a), k.path.eval(b, b))
+		if k.Desc {
+			cmp = -cmp
+		}
+		if cmp != 0 {
+			return cmp < 0
+		}
+	}
+	return false
+}
+
+// compareSortValues compares the first of the values selected by a sort
+// key for two items. Missing values come first, then nulls, booleans,
+// numbers, strings, and other values, which are compared by their JSON.
+func compareSortValues(a, b []interface{}) int {
+	ra, rb := sortRank(a), sortRank(b)
+	if ra != rb {
+		return compareOrdered(float64(ra), float64(rb))
+	}
+	if len(a) == 0 {
+		return 0
+	}
+
+	switch x := a[0].(type) {
+	case bool:
+		y := b[0].(bool)
+		if x == y {
+			return 0
+		}
+		if !x {
+			return -1
+		}
+		return 1
+	case json.Number:
+		fx, _ := x.Float64()
+		fy, _ := b[0].(json.Number).Float64()
+		return compareOrdered(fx, fy)
+	case string:
+		return strings.Compare(x, b[0].(string))
+	case nil:
+		return 0
+	}
+	sx, _ := formatJSONPathValue(a[0])
+	sy, _ := formatJSONPathValue(b[0])
+	return strings.Compare(sx, sy)
+}
+
+// sortRank gets the order of the type of a sort key's first value.
+func sortRank(values []interface{}) int {
+	if len(values) == 0 {
+		return 0
+	}
+	switch values[0].(type) {
+	case nil:
+		return 1
+	case bool:
+		return 2
+	case json.Number:
+		return 3
+	case string:
+		return 4
+	}
+	return 5
+}
+
+// queryItem is an item of data, along with its JSON form.
+type queryItem struct {
+	data interface{}
+	form interface{}
+}
+
+// query gets the items of the data which match the output's filter, sorted
+// by its sort keys. Data which is not a slice is returned as it is, as is
+// all data if there is no filter and there are no sort keys.
+func (p *Printer) query(data interface{}) (interface{}, error) {
+	if p.output.Filter == nil && len(p.output.SortBy) == 0 {
+		return data, nil
+	}
+	if data == nil || reflect.TypeOf(data).Kind() != reflect.Slice {
+		return data, nil
+	}
+
+	s := reflect.ValueOf(data)
+	items := make([]queryItem, 0, s.Len())
+	for i := 0; i < s.Len(); i++ {
+		item := queryItem{data: s.Index(i).Interface()}
+		form, err := p.jsonForm(item.data)
+		if err != nil {
+			return nil, err
+		}
+		if p.output.Filter != nil && !p.output.Filter.Match(form) {
+			continue
+		}
+		item.form = form
+		items = append(items, item)
+	}
+
+	if len(p.output.SortBy) != 0 {
+		sort.SliceStable(items, func(i, j int) bool {
+			return lessBySortKeys(p.output.SortBy, items[i].form, items[j].form)
+		})
+	}
+
+	result := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		result = append(result, item.data)
+	}
+	return result, nil
+}
+
+// match checks whether an item of data matches the output's filter, if it
+// has one.
+func (p *Printer) match(data interface{}) (bool, error) {
+	if p.output.Filter == nil {
+		return true, nil
+	}
+	form, err := p.jsonForm(data)
+	if err != nil {
+		return false, err
+	}
+	return p.output.Filter.Match(form), nil
+}
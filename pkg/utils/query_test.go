@@ -0,0 +1,354 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+// queryData is a reading, as decoded from its JSON output.
+var queryData = map[string]interface{}{
+	"id":    "111-222-333",
+	"type":  "temperature",
+	"value": json.Number("36.5"),
+	"unit": map[string]interface{}{
+		"symbol": "C",
+	},
+	"context": map[string]interface{}{
+		"zone": "a",
+	},
+	"tags":  []interface{}{"vapor/fake", "system/type:temperature"},
+	"ok":    true,
+	"error": nil,
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		expr  string
+		match bool
+	}{
+		{expr: `type == "temperature"`, match: true},
+		{expr: `type == 'temperature'`, match: true},
+		{expr: `type != "temperature"`, match: false},
+		{expr: `.type == "temperature"`, match: true},
+		{expr: `@.type == "humidity"`, match: false},
+		{expr: `value > 35`, match: true},
+		{expr: `value >= 36.5`, match: true},
+		{expr: `value < 35`, match: false},
+		{expr: `value <= -1`, match: false},
+		{expr: `value == 36.5`, match: true},
+		{expr: `type == "temperature" && value > 35`, match: true},
+		{expr: `type == "temperature" && value > 40`, match: false},
+		{expr: `type == "humidity" || value > 35`, match: true},
+		{expr: `type == "humidity" || value > 40 && ok`, match: false},
+		{expr: `(type == "humidity" || value > 35) && ok`, match: true},
+		{expr: `!(type == "humidity")`, match: true},
+		{expr: `!ok`, match: false},
+		{expr: `ok`, match: true},
+		{expr: `ok == true`, match: true},
+		{expr: `error`, match: false},
+		{expr: `error == null`, match: true},
+		{expr: `missing`, match: false},
+		{expr: `!missing`, match: true},
+		{expr: `missing == "x"`, match: false},
+		{expr: `unit.symbol == "C"`, match: true},
+		{expr: `context.zone == "a"`, match: true},
+		{expr: `context['zone'] == "a"`, match: true},
+		{expr: `tags[*] == "vapor/fake"`, match: true},
+		{expr: `tags[0] == "system/type:temperature"`, match: false},
+		{expr: `tags[*] =~ "^system/"`, match: true},
+		{expr: `tags[*] !~ "^system/"`, match: true},
+		{expr: `id =~ "^444"`, match: false},
+		{expr: `value =~ "^36"`, match: true},
+		{expr: `type == unit.symbol`, match: false},
+		{expr: `"temperature" == type`, match: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expr, f.String())
+			assert.Equal(t, tt.match, f.Match(queryData))
+		})
+	}
+}
+
+func TestParseFilter_empty(t *testing.T) {
+	f, err := ParseFilter("  ")
+	assert.NoError(t, err)
+	assert.Nil(t, f)
+}
+
+func TestParseFilter_error(t *testing.T) {
+	tests := []struct {
+		expr string
+		err  string
+	}{
+		{expr: `type ==`, err: "invalid filter 'type ==': unexpected end of expression"},
+		{expr: `type == "led`, err: `invalid filter 'type == "led': unclosed string "led`},
+		{expr: `(type == "led"`, err: `invalid filter '(type == "led"': missing ')'`},
+		{expr: `type == "led")`, err: `invalid filter 'type == "led")': unexpected ')'`},
+		{expr: `type "led"`, err: `invalid filter 'type "led"': unexpected '"led"'`},
+		{expr: `== "led"`, err: `invalid filter '== "led"': unexpected '=='`},
+		{expr: `type =~ led`, err: "invalid filter 'type =~ led': '=~' must be followed by a quoted regular expression"},
+		{expr: `type =~ "("`, err: "invalid filter 'type =~ \"(\"': error parsing regexp: missing closing ): `(`"},
+		{expr: `value > 3x`, err: "invalid filter 'value > 3x': invalid value '3x'"},
+		{expr: `tags[0 == "a"`, err: `invalid filter 'tags[0 == "a"': unclosed '[' in '[0 == "a"'`},
+		{expr: `type == "a" & ok`, err: `invalid filter 'type == "a" & ok': unexpected '&'`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			assert.Nil(t, f)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestParseSortKeys(t *testing.T) {
+	keys, err := ParseSortKeys("type, value:desc,.unit.symbol:ASC,tags[0:2]")
+	assert.NoError(t, err)
+	assert.Len(t, keys, 4)
+	assert.Equal(t, "type", keys[0].String())
+	assert.True(t, keys[1].Desc)
+	assert.Equal(t, "value:desc", keys[1].String())
+	assert.Equal(t, ".unit.symbol", keys[2].String())
+	assert.Equal(t, "tags[0:2]", keys[3].String())
+
+	keys, err = ParseSortKeys("")
+	assert.NoError(t, err)
+	assert.Nil(t, keys)
+}
+
+func TestParseSortKeys_error(t *testing.T) {
+	tests := []struct {
+		value string
+		err   string
+	}{
+		{value: ":desc", err: "invalid sort key ':desc': expected FIELD[:asc|:desc]"},
+		{value: "type,", err: "invalid sort key '': expected FIELD[:asc|:desc]"},
+		{value: "tags[0", err: "invalid sort key 'tags[0': unclosed '[' in '[0'"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			keys, err := ParseSortKeys(tt.value)
+			assert.Nil(t, keys)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestCompareSortValues(t *testing.T) {
+	decoded, err := decodeJSONData([]interface{}{1, 2.5, 10})
+	assert.NoError(t, err)
+	numbers := decoded.([]interface{})
+
+	tests := []struct {
+		desc string
+		a, b []interface{}
+		cmp  int
+	}{
+		{desc: "missing", a: nil, b: nil, cmp: 0},
+		{desc: "missing first", a: nil, b: []interface{}{nil}, cmp: -1},
+		{desc: "null before bool", a: []interface{}{nil}, b: []interface{}{false}, cmp: -1},
+		{desc: "bool", a: []interface{}{true}, b: []interface{}{false}, cmp: 1},
+		{desc: "bool equal", a: []interface{}{true}, b: []interface{}{true}, cmp: 0},
+		{desc: "bool before number", a: []interface{}{true}, b: []interface{}{numbers[0]}, cmp: -1},
+		{desc: "numbers", a: []interface{}{numbers[2]}, b: []interface{}{numbers[1]}, cmp: 1},
+		{desc: "number before string", a: []interface{}{numbers[2]}, b: []interface{}{"1"}, cmp: -1},
+		{desc: "strings", a: []interface{}{"abc"}, b: []interface{}{"abd"}, cmp: -1},
+		{desc: "first value", a: []interface{}{"b", "a"}, b: []interface{}{"a", "z"}, cmp: 1},
+		{desc: "objects", a: []interface{}{map[string]interface{}{"a": "1"}}, b: []interface{}{map[string]interface{}{"a": "2"}}, cmp: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			assert.Equal(t, tt.cmp, compareSortValues(tt.a, tt.b))
+		})
+	}
+}
+
+var queryOutputs = []*testOutput{
+	{Foo: "b", Bar: 2},
+	{Foo: "a", Bar: 3},
+	{Foo: "c", Bar: 1},
+	{Foo: "a", Bar: 1},
+}
+
+func TestPrinter_Write_filter(t *testing.T) {
+	filter, err := ParseFilter(`bar >= 2 || foo == "c"`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Filter: filter}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	assert.NoError(t, p.Write(queryOutputs))
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO   BAR
+			b     2
+			a     3
+			c     1
+		`),
+		out.String(),
+	)
+}
+
+func TestPrinter_Write_filterNoMatch(t *testing.T) {
+	filter, err := ParseFilter(`bar > 5`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputJSON, Filter: filter}, false)
+
+	assert.NoError(t, p.Write(queryOutputs))
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestPrinter_Write_sortBy(t *testing.T) {
+	keys, err := ParseSortKeys("foo,bar:desc")
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, SortBy: keys}, true)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	assert.NoError(t, p.Write(queryOutputs))
+	assert.Equal(t, "a,3\na,1\nb,2\nc,1\n", out.String())
+}
+
+func TestPrinter_Write_sortByStable(t *testing.T) {
+	keys, err := ParseSortKeys("foo")
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, SortBy: keys}, true)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	assert.NoError(t, p.Write(queryOutputs))
+	assert.Equal(t, "a,3\na,1\nb,2\nc,1\n", out.String())
+}
+
+func TestPrinter_Write_queryTransform(t *testing.T) {
+	filter, err := ParseFilter(`upper == "A"`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, Filter: filter}, true)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+	p.SetTransformFunc(func(data map[string]interface{}) error {
+		if data["foo"] == "a" {
+			data["upper"] = "A"
+		}
+		return nil
+	})
+
+	assert.NoError(t, p.Write(queryOutputs))
+	assert.Equal(t, "a,3\na,1\n", out.String())
+}
+
+func TestPrinter_Write_queryNotSlice(t *testing.T) {
+	filter, err := ParseFilter(`bar > 5`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Format: OutputCSV, Filter: filter}, true)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	assert.NoError(t, p.Write(queryOutputs[0]))
+	assert.Equal(t, "b,2\n", out.String())
+}
+
+func TestPrinter_WriteContexts_query(t *testing.T) {
+	filter, err := ParseFilter(`bar != 1`)
+	assert.NoError(t, err)
+	keys, err := ParseSortKeys("bar:desc")
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	p := NewPrinter(out, Output{Filter: filter, SortBy: keys}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+
+	err = p.WriteContexts([]ContextResult{
+		{Context: "a", Data: queryOutputs},
+		{Context: "b", Data: []*testOutput{{Foo: "d", Bar: 4}, {Foo: "e", Bar: 5}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			CONTEXT   FOO   BAR
+			a         a     3
+			a         b     2
+			b         e     5
+			b         d     4
+		`),
+		out.String(),
+	)
+}
+
+func TestStream_filter(t *testing.T) {
+	filter, err := ParseFilter(`bar != 3`)
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputCSV, Filter: filter}, false).NewStream()
+	for _, d := range streamData {
+		assert.NoError(t, s.Write(d))
+	}
+	assert.Equal(t, 3, s.Count())
+	assert.NoError(t, s.Close())
+	assert.Equal(t, "FOO,BAR\ntwo,2\none,1\n", out.String())
+}
+
+func TestStream_sortBy(t *testing.T) {
+	keys, err := ParseSortKeys("foo:desc")
+	assert.NoError(t, err)
+
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputNDJSON, SortBy: keys}, false).NewStream()
+	for _, d := range streamData {
+		assert.NoError(t, s.Write(d))
+	}
+	assert.Empty(t, out.String())
+	assert.NoError(t, s.Close())
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			{"foo":"two","bar":2}
+			{"foo":"three","bar":3}
+			{"foo":"one","bar":1}
+		`),
+		out.String(),
+	)
+}
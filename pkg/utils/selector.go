@@ -0,0 +1,54 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// SelectContext gets the name of the single context of the given type which
+// is selected by the label selector (e.g. env=prod,site!=lab). If no type
+// is given, contexts of any type may be selected. It is an error for the
+// selector to match no contexts, or more than one.
+func SelectContext(selector, ctxType string) (string, error) {
+	s, err := config.ParseSelector(selector)
+	if err != nil {
+		return "", err
+	}
+
+	desc := "context"
+	if ctxType != "" {
+		desc = ctxType + " context"
+	}
+
+	var names []string
+	for _, ctx := range config.SelectContexts(s, ctxType) {
+		names = append(names, ctx.Name)
+	}
+	switch len(names) {
+	case 0:
+		return "", fmt.Errorf("no %s matches selector '%s'", desc, selector)
+	case 1:
+		return names[0], nil
+	}
+	sort.Strings(names)
+	return "", fmt.Errorf("selector '%s' matches %d %ss (%s); it must match exactly one", selector, len(names), desc, strings.Join(names, ", "))
+}
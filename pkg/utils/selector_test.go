@@ -0,0 +1,84 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// labeledTestContexts are labeled server and plugin contexts to select from.
+var labeledTestContexts = []config.ContextRecord{
+	{Name: "atl-prod", Type: "server", Context: config.Context{Address: "a:5000"}, Labels: map[string]string{"env": "prod", "site": "atl"}},
+	{Name: "atl-lab", Type: "server", Context: config.Context{Address: "b:5000"}, Labels: map[string]string{"env": "lab", "site": "atl"}},
+	{Name: "ord-prod", Type: "server", Context: config.Context{Address: "c:5000"}, Labels: map[string]string{"env": "prod", "site": "ord"}},
+	{Name: "atl-emulator", Type: "plugin", Context: config.Context{Address: "d:5001"}, Labels: map[string]string{"env": "prod", "site": "atl"}},
+}
+
+func TestSelectContext(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, labeledTestContexts)
+
+	tests := []struct {
+		selector string
+		ctxType  string
+		expected string
+	}{
+		{"env=prod,site=atl", "server", "atl-prod"},
+		{"env=prod,site=atl", "plugin", "atl-emulator"},
+		{"site=ord", "", "ord-prod"},
+		{"site=atl,env!=prod", "server", "atl-lab"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			name, err := SelectContext(tt.selector, tt.ctxType)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, name)
+		})
+	}
+}
+
+func TestSelectContext_errors(t *testing.T) {
+	defer config.Purge()
+	test.AddContexts(t, labeledTestContexts)
+
+	tests := []struct {
+		selector string
+		ctxType  string
+		err      string
+	}{
+		{"env=staging", "server", "no server context matches selector 'env=staging'"},
+		{"site=ord", "plugin", "no plugin context matches selector 'site=ord'"},
+		{"env=staging", "", "no context matches selector 'env=staging'"},
+		{"env=prod", "server", "selector 'env=prod' matches 2 server contexts (atl-prod, ord-prod); it must match exactly one"},
+		{"env=prod,site=atl", "", "selector 'env=prod,site=atl' matches 2 contexts (atl-emulator, atl-prod); it must match exactly one"},
+		{"env=", "server", "no server context matches selector 'env='"},
+		{"env==,", "server", "invalid label selector 'env==,': empty requirement"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err, func(t *testing.T) {
+			name, err := SelectContext(tt.selector, tt.ctxType)
+			assert.EqualError(t, err, tt.err)
+			assert.Empty(t, name)
+		})
+	}
+}
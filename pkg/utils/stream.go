@@ -0,0 +1,199 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// streamFlushRows is the number of rows after which a stream flushes the
+// table rows it has written, so that they are printed.
+const streamFlushRows = 100
+
+// streamFlushInterval is the longest time that a table row written to a
+// stream waits before it is flushed.
+var streamFlushInterval = time.Second
+
+// Stream writes data with a Printer an item at a time, as it is received
+// (e.g. from a gRPC stream or a client channel), rather than all at once.
+//
+// Table, CSV and TSV rows are written as each item is received. They are
+// flushed once enough rows are waiting, and otherwise in the background once
+// they have waited for the flush interval. Table columns are aligned over
+// the rows of each flush, and the rows are not sorted. NDJSON output has a
+// line for each item. Output which cannot be written an item at a time (JSON, YAML and
+// templates), and output for a printer with a less function or with sort
+// keys, is held until the stream is closed and then written as a whole.
+// Items which do not match the output's filter are not written.
+type Stream struct {
+	p *Printer
+
+	// mu guards the stream against flushes from the background.
+	mu sync.Mutex
+
+	// items holds the data which is written when the stream is closed.
+	items []interface{}
+	count int
+
+	// w writes the table rows. It is created, and the header written, when
+	// the first item is received.
+	w         rowWriter
+	rowFunc   func(data interface{}) ([]interface{}, error)
+	fields    []int
+	unflushed int
+
+	// timer flushes the table rows which are waiting, in the background.
+	timer *time.Timer
+	err   error
+}
+
+// NewStream creates a Stream which writes data with the printer.
+func (p *Printer) NewStream() *Stream {
+	return &Stream{p: p}
+}
+
+// Count gets the number of items written to the stream.
+func (s *Stream) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// Write writes an item of data to the stream.
+func (s *Stream) Write(data interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.count++
+	if s.p.lessFunc != nil || len(s.p.output.SortBy) != 0 || !s.p.output.IsStreamable() {
+		s.items = append(s.items, data)
+		return nil
+	}
+
+	if ok, err := s.p.match(data); err != nil || !ok {
+		return err
+	}
+
+	if s.p.output.Format == OutputNDJSON {
+		return s.p.toNDJSON(data)
+	}
+
+	if s.w == nil {
+		if err := s.start(); err != nil {
+			return err
+		}
+	}
+	rows, err := tableRows(s.rowFunc, data)
+	if err != nil {
+		return err
+	}
+	if err := writeRows(s.w, s.fields, rows); err != nil {
+		return err
+	}
+
+	s.unflushed += len(rows)
+	if s.unflushed >= streamFlushRows {
+		return s.flush()
+	}
+	if s.timer == nil && s.unflushed > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(streamFlushInterval, func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			// The timer may have been replaced if the rows were flushed
+			// while it fired.
+			if s.timer == timer && s.err == nil {
+				s.err = s.flush()
+			}
+		})
+		s.timer = timer
+	}
+	return nil
+}
+
+// start creates the row writer for table output and writes the header.
+func (s *Stream) start() error {
+	header, rowFunc := s.p.columns()
+	if rowFunc == nil {
+		return ErrNoRowFunc
+	}
+	fields, err := s.p.fieldIndexes(header)
+	if err != nil {
+		return err
+	}
+
+	s.w = newRowWriter(s.p.out, s.p.output.Format)
+	s.rowFunc = rowFunc
+	s.fields = fields
+	return s.p.writeHeader(s.w, selectFields(fields, header))
+}
+
+// Flush flushes the table rows which have been written. It does not write
+// the data which is held until the stream is closed.
+func (s *Stream) Flush() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.flush()
+}
+
+// flush flushes the table rows which have been written, and stops any
+// pending flush in the background. The caller must hold the lock.
+func (s *Stream) flush() error {
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.w == nil {
+		return nil
+	}
+	s.unflushed = 0
+	return s.w.Flush()
+}
+
+// Close finishes writing the stream. Table rows which have not been flushed
+// are flushed, and the pending flush in the background, if any, is stopped.
+// Data which was held is sorted, if the printer has a less function, and
+// written (see Printer.Write). If no data was written to the stream, nothing
+// is written out.
+func (s *Stream) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	if len(s.items) == 0 {
+		return s.flush()
+	}
+
+	s.p.sortData(s.items)
+	return s.p.Write(s.items)
+}
+
+// sortData sorts the data with the printer's less function, if it has one.
+func (p *Printer) sortData(data []interface{}) {
+	if p.lessFunc != nil {
+		sort.SliceStable(data, func(i, j int) bool {
+			return p.lessFunc(data[i], data[j])
+		})
+	}
+}
@@ -0,0 +1,235 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/stretchr/testify/assert"
+)
+
+// newStreamPrinter creates a printer for testOutput data, for streams.
+func newStreamPrinter(out *bytes.Buffer, output Output, sorted bool) *Printer {
+	p := NewPrinter(out, output, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+	if sorted {
+		p.SetLessFunc(func(a, b interface{}) bool {
+			return a.(*testOutput).Bar < b.(*testOutput).Bar
+		})
+	}
+	return p
+}
+
+var streamData = []*testOutput{
+	{Foo: "two", Bar: 2},
+	{Foo: "three", Bar: 3},
+	{Foo: "one", Bar: 1},
+}
+
+func TestStream_table(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{}, false).NewStream()
+
+	for _, d := range streamData {
+		assert.NoError(t, s.Write(d))
+	}
+	assert.Equal(t, 3, s.Count())
+	assert.NoError(t, s.Close())
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO     BAR
+			two     2
+			three   3
+			one     1
+		`),
+		out.String(),
+	)
+}
+
+func TestStream_tableSorted(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{}, true).NewStream()
+
+	for _, d := range streamData {
+		assert.NoError(t, s.Write(d))
+	}
+	assert.Empty(t, out.String())
+	assert.NoError(t, s.Close())
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			FOO     BAR
+			one     1
+			two     2
+			three   3
+		`),
+		out.String(),
+	)
+}
+
+func TestStream_csv(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputCSV}, false).NewStream()
+
+	assert.NoError(t, s.Write(streamData[0]))
+	assert.NoError(t, s.Flush())
+	assert.Equal(t, "FOO,BAR\ntwo,2\n", out.String())
+
+	assert.NoError(t, s.Write(streamData[1]))
+	assert.NoError(t, s.Close())
+	assert.Equal(t, "FOO,BAR\ntwo,2\nthree,3\n", out.String())
+}
+
+func TestStream_flushRows(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputCSV}, false).NewStream()
+
+	for i := 0; i < streamFlushRows; i++ {
+		assert.NoError(t, s.Write(streamData[0]))
+	}
+	// The rows are flushed once enough have been written, without the
+	// stream being closed.
+	assert.Equal(t, streamFlushRows+1, strings.Count(out.String(), "\n"))
+}
+
+// lockedBuffer is a bytes.Buffer which is safe to read while a stream
+// flushes to it in the background.
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestStream_flushInterval(t *testing.T) {
+	defer func(interval time.Duration) { streamFlushInterval = interval }(streamFlushInterval)
+	streamFlushInterval = 10 * time.Millisecond
+
+	out := &lockedBuffer{}
+	p := NewPrinter(out, Output{}, false)
+	p.SetHeader("FOO", "BAR")
+	p.SetRowFunc(testOutputRowFunc)
+	s := p.NewStream()
+
+	// A single row is printed once it has waited for the flush interval,
+	// without another row being written or the stream being closed.
+	assert.NoError(t, s.Write(streamData[0]))
+	assert.Eventually(t, func() bool {
+		return out.String() == "FOO   BAR\ntwo   2\n"
+	}, time.Second, streamFlushInterval)
+
+	assert.NoError(t, s.Write(streamData[1]))
+	assert.NoError(t, s.Close())
+	assert.Equal(t, "FOO   BAR\ntwo   2\nthree   3\n", out.String())
+}
+
+func TestStream_ndjson(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputNDJSON}, false).NewStream()
+
+	assert.NoError(t, s.Write(streamData[0]))
+	assert.Equal(t, "{\"foo\":\"two\",\"bar\":2}\n", out.String())
+	assert.NoError(t, s.Write(streamData[1]))
+	assert.NoError(t, s.Close())
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			{"foo":"two","bar":2}
+			{"foo":"three","bar":3}
+		`),
+		out.String(),
+	)
+}
+
+func TestStream_json(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputJSON}, true).NewStream()
+
+	for _, d := range streamData[:2] {
+		assert.NoError(t, s.Write(d))
+	}
+	assert.Empty(t, out.String())
+	assert.NoError(t, s.Close())
+	assert.Equal(
+		t,
+		heredoc.Doc(`
+			[
+			  {
+			    "foo": "two",
+			    "bar": 2
+			  },
+			  {
+			    "foo": "three",
+			    "bar": 3
+			  }
+			]
+		`),
+		out.String(),
+	)
+}
+
+func TestStream_empty(t *testing.T) {
+	for _, format := range []string{OutputTable, OutputCSV, OutputNDJSON, OutputJSON} {
+		t.Run(format, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			s := newStreamPrinter(out, Output{Format: format}, false).NewStream()
+
+			assert.NoError(t, s.Close())
+			assert.Equal(t, 0, s.Count())
+			assert.Empty(t, out.String())
+		})
+	}
+}
+
+func TestStream_fields(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Format: OutputTSV, Fields: []string{"bar"}}, false).NewStream()
+
+	assert.NoError(t, s.Write(streamData[0]))
+	assert.NoError(t, s.Close())
+	assert.Equal(t, "BAR\n2\n", out.String())
+}
+
+func TestStream_unknownField(t *testing.T) {
+	out := &bytes.Buffer{}
+	s := newStreamPrinter(out, Output{Fields: []string{"baz"}}, false).NewStream()
+
+	assert.EqualError(t, s.Write(streamData[0]), "unknown field 'baz' (fields: FOO, BAR)")
+}
+
+func TestStream_noRowFunc(t *testing.T) {
+	s := NewPrinter(&bytes.Buffer{}, Output{}, false).NewStream()
+
+	assert.Equal(t, ErrNoRowFunc, s.Write(streamData[0]))
+}
@@ -0,0 +1,86 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+)
+
+// NewTLSConfig creates the TLS configuration for connecting to a Synse
+// component with the given context settings. If the context does not
+// configure TLS, nil is returned and the connection should be insecure.
+//
+// A client certificate without a key can not be used for client authentication.
+// For compatibility with contexts created before client keys were supported,
+// such a certificate is trusted as a CA certificate instead.
+func NewTLSConfig(ctx config.Context) (*tls.Config, error) {
+	if !ctx.TLSEnabled() {
+		return nil, nil
+	}
+
+	caFiles := []string{}
+	if ctx.CACert != "" {
+		caFiles = append(caFiles, ctx.CACert)
+	}
+
+	cfg := &tls.Config{
+		ServerName:         ctx.ServerName,
+		InsecureSkipVerify: ctx.InsecureSkipVerify,
+	}
+
+	switch {
+	case ctx.ClientCert != "" && ctx.ClientKey != "":
+		cert, err := tls.LoadX509KeyPair(ctx.ClientCert, ctx.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client key pair: %v", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case ctx.ClientCert != "":
+		log.WithField("cert", ctx.ClientCert).Debug("tls: client cert has no key, using it as a CA cert")
+		caFiles = append(caFiles, ctx.ClientCert)
+	case ctx.ClientKey != "":
+		return nil, fmt.Errorf("a client key requires a client cert")
+	}
+
+	if len(caFiles) != 0 {
+		pool := x509.NewCertPool()
+		for _, f := range caFiles {
+			pem, err := os.ReadFile(f)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA cert: %v", err)
+			}
+			if !pool.AppendCertsFromPEM(pem) {
+				return nil, fmt.Errorf("failed to load CA cert: no certificates found in %s", f)
+			}
+		}
+		cfg.RootCAs = pool
+	}
+
+	log.WithFields(log.Fields{
+		"ca":          caFiles,
+		"client-cert": ctx.ClientCert,
+		"server-name": ctx.ServerName,
+		"skip-verify": ctx.InsecureSkipVerify,
+	}).Debug("tls: created client config")
+	return cfg, nil
+}
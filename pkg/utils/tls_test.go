@@ -0,0 +1,285 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+)
+
+// newTLSTestServer starts an HTTPS server which responds to the Synse
+// '/test' endpoint.
+func newTLSTestServer(t *testing.T, certs *test.Certs, requireClientCert bool) *httptest.Server {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","timestamp":"2019-04-22T13:30:00Z"}`))
+	}))
+	server.TLS = certs.ServerTLSConfig(t, requireClientCert)
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func addTLSContext(t *testing.T, ctxType string, ctx config.Context) {
+	assert.NoError(t, config.AddContext(&config.ContextRecord{
+		Name:    "testctx",
+		Type:    ctxType,
+		Context: ctx,
+	}))
+}
+
+func TestNewTLSConfig_disabled(t *testing.T) {
+	cfg, err := NewTLSConfig(config.Context{Address: "localhost:5000"})
+	assert.NoError(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestNewTLSConfig_caCert(t *testing.T) {
+	certs := test.NewCerts(t)
+
+	cfg, err := NewTLSConfig(config.Context{
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.RootCAs)
+	assert.Empty(t, cfg.Certificates)
+	assert.Equal(t, test.CertServerName, cfg.ServerName)
+	assert.False(t, cfg.InsecureSkipVerify)
+}
+
+func TestNewTLSConfig_clientKeyPair(t *testing.T) {
+	certs := test.NewCerts(t)
+
+	cfg, err := NewTLSConfig(config.Context{
+		ClientCert: certs.ClientCert,
+		ClientKey:  certs.ClientKey,
+	})
+	assert.NoError(t, err)
+	assert.Nil(t, cfg.RootCAs)
+	assert.Len(t, cfg.Certificates, 1)
+}
+
+func TestNewTLSConfig_clientCertAsCA(t *testing.T) {
+	certs := test.NewCerts(t)
+
+	// A client cert without a key is trusted as a CA cert.
+	cfg, err := NewTLSConfig(config.Context{
+		ClientCert: certs.CACert,
+	})
+	assert.NoError(t, err)
+	assert.NotNil(t, cfg.RootCAs)
+	assert.Empty(t, cfg.Certificates)
+}
+
+func TestNewTLSConfig_skipVerify(t *testing.T) {
+	cfg, err := NewTLSConfig(config.Context{
+		InsecureSkipVerify: true,
+	})
+	assert.NoError(t, err)
+	assert.True(t, cfg.InsecureSkipVerify)
+}
+
+func TestNewTLSConfig_errors(t *testing.T) {
+	certs := test.NewCerts(t)
+
+	cases := []struct {
+		description string
+		ctx         config.Context
+	}{
+		{
+			description: "key without cert",
+			ctx:         config.Context{ClientKey: certs.ClientKey},
+		},
+		{
+			description: "mismatched key pair",
+			ctx:         config.Context{ClientCert: certs.ClientCert, ClientKey: certs.ServerKey},
+		},
+		{
+			description: "missing CA cert",
+			ctx:         config.Context{CACert: "/tmp/test/does-not-exist.pem"},
+		},
+		{
+			description: "CA cert is not a certificate",
+			ctx:         config.Context{CACert: certs.ClientKey},
+		},
+	}
+
+	for _, c := range cases {
+		cfg, err := NewTLSConfig(c.ctx)
+		assert.Error(t, err, c.description)
+		assert.Nil(t, cfg, c.description)
+	}
+}
+
+func TestClientFactory_HTTP_tls(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+	server := newTLSTestServer(t, certs, false)
+
+	addTLSContext(t, "server", config.Context{
+		Address:    strings.TrimPrefix(server.URL, "https://"),
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	status, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func TestClientFactory_HTTP_tlsWrongServerName(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+	server := newTLSTestServer(t, certs, false)
+
+	// The server cert is not valid for the IP address, so verification
+	// should fail without the server name override.
+	addTLSContext(t, "server", config.Context{
+		Address: strings.TrimPrefix(server.URL, "https://"),
+		CACert:  certs.CACert,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	_, err = client.Status()
+	assert.Error(t, err)
+}
+
+func TestClientFactory_HTTP_mutualTLS(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+	server := newTLSTestServer(t, certs, true)
+
+	addTLSContext(t, "server", config.Context{
+		Address:    strings.TrimPrefix(server.URL, "https://"),
+		ClientCert: certs.ClientCert,
+		ClientKey:  certs.ClientKey,
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	status, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func TestClientFactory_HTTP_mutualTLSNoClientCert(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+	server := newTLSTestServer(t, certs, true)
+
+	addTLSContext(t, "server", config.Context{
+		Address:    strings.TrimPrefix(server.URL, "https://"),
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	_, err = client.Status()
+	assert.Error(t, err)
+}
+
+func TestClientFactory_HTTP_skipVerify(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+	server := newTLSTestServer(t, certs, false)
+
+	addTLSContext(t, "server", config.Context{
+		Address:            strings.TrimPrefix(server.URL, "https://"),
+		InsecureSkipVerify: true,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	status, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func TestClientFactory_WebSocket_tls(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+
+	addTLSContext(t, "server", config.Context{
+		Address:    "localhost:5000",
+		ClientCert: certs.ClientCert,
+		ClientKey:  certs.ClientKey,
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).WebSocket()
+	assert.NoError(t, err)
+
+	tlsConfig := client.(*websocketServerClient).dialer.TLSClientConfig
+	assert.NotNil(t, tlsConfig)
+	assert.Equal(t, test.CertServerName, tlsConfig.ServerName)
+	assert.Len(t, tlsConfig.Certificates, 1)
+	assert.NotNil(t, tlsConfig.RootCAs)
+}
+
+func TestClientFactory_GRPC_mutualTLS(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+
+	// The server has no services registered. If the TLS handshake succeeds,
+	// requests fail as unimplemented rather than unavailable.
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	server := grpc.NewServer(grpc.Creds(credentials.NewTLS(certs.ServerTLSConfig(t, true))))
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	addTLSContext(t, "plugin", config.Context{
+		Address:    lis.Addr().String(),
+		ClientCert: certs.ClientCert,
+		ClientKey:  certs.ClientKey,
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = client.Test(context.Background(), &synse.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), err)
+}
@@ -0,0 +1,46 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+)
+
+// unixHost is the host used in place of the address of a component which
+// listens on a unix socket, for the URLs and the gRPC target which are
+// dialed. Connections are made to the socket regardless of the host.
+const unixHost = "localhost"
+
+// unixDialer creates a function which dials the unix socket at the given
+// path, ignoring the network and address it is asked to dial.
+func unixDialer(path string) func(ctx context.Context, network, address string) (net.Conn, error) {
+	return func(ctx context.Context, _, _ string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, "unix", path)
+	}
+}
+
+// unixTransport creates an HTTP transport which makes all of its
+// connections to the unix socket at the given path.
+func unixTransport(path string) *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = unixDialer(path)
+	return transport
+}
@@ -0,0 +1,157 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-cli/internal/test"
+	"github.com/vapor-ware/synse-cli/pkg/config"
+	synse "github.com/vapor-ware/synse-server-grpc/go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// listenUnix listens on a unix socket in a temporary directory, returning
+// the listener and the unix socket address for it.
+func listenUnix(t *testing.T) (net.Listener, string) {
+	path := filepath.Join(t.TempDir(), "synse.sock")
+	lis, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return lis, config.UnixScheme + path
+}
+
+// newUnixTestServer starts an HTTP server on a unix socket which responds
+// to the Synse '/test' endpoint, returning the socket address.
+func newUnixTestServer(t *testing.T, certs *test.Certs) string {
+	lis, address := listenUnix(t)
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","timestamp":"2019-04-22T13:30:00Z"}`))
+	}))
+	server.Listener = lis
+	if certs != nil {
+		server.TLS = certs.ServerTLSConfig(t, false)
+		server.StartTLS()
+	} else {
+		server.Start()
+	}
+	t.Cleanup(server.Close)
+	return address
+}
+
+func TestClientFactory_HTTP_unixSocket(t *testing.T) {
+	defer config.Purge()
+	addTLSContext(t, "server", config.Context{
+		Address: newUnixTestServer(t, nil),
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	s, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", s.Status)
+}
+
+func TestClientFactory_HTTP_unixSocketTLS(t *testing.T) {
+	defer config.Purge()
+	certs := test.NewCerts(t)
+	addTLSContext(t, "server", config.Context{
+		Address:    newUnixTestServer(t, certs),
+		CACert:     certs.CACert,
+		ServerName: test.CertServerName,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).HTTP()
+	assert.NoError(t, err)
+
+	s, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", s.Status)
+}
+
+func TestClientFactory_HTTP_unixSocketNotListening(t *testing.T) {
+	defer config.Purge()
+	addTLSContext(t, "server", config.Context{
+		Address: config.UnixScheme + filepath.Join(t.TempDir(), "missing.sock"),
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx", NoRetry: true}).HTTP()
+	assert.NoError(t, err)
+
+	_, err = client.Status()
+	assert.Error(t, err)
+}
+
+func TestClientFactory_WebSocket_unixSocket(t *testing.T) {
+	defer config.Purge()
+
+	lis, address := listenUnix(t)
+	var upgrader websocket.Upgrader
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/connect", r.URL.Path)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		_ = conn.Close()
+	}))
+	server.Listener = lis
+	server.Start()
+	defer server.Close()
+
+	addTLSContext(t, "server", config.Context{
+		Address: address,
+	})
+
+	client, err := NewClientFactory(ClientOptions{Context: "testctx"}).WebSocket()
+	assert.NoError(t, err)
+	assert.NoError(t, client.Open())
+}
+
+func TestClientFactory_GRPC_unixSocket(t *testing.T) {
+	defer config.Purge()
+
+	// The server has no services registered. If the connection is made,
+	// requests fail as unimplemented rather than unavailable.
+	lis, address := listenUnix(t)
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(lis) }()
+	defer server.Stop()
+
+	addTLSContext(t, "plugin", config.Context{
+		Address: address,
+	})
+
+	conn, client, err := NewClientFactory(ClientOptions{Context: "testctx"}).GRPC()
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	_, err = client.Test(context.Background(), &synse.Empty{})
+	assert.Equal(t, codes.Unimplemented, status.Code(err), err)
+}
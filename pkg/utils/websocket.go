@@ -17,46 +17,53 @@
 package utils
 
 import (
-	"github.com/pkg/errors"
 	"github.com/vapor-ware/synse-cli/pkg/config"
 	"github.com/vapor-ware/synse-client-go/synse"
 )
 
-// Errors relating to WebSocket client creation.
-var (
-	ErrWSNoCurrentServerCtx = errors.New("failed to create server WebSocket client: no current server context")
-	ErrWSInvalidServerCtx   = errors.New("failed to create server WebSocket client: specified context does not exist")
-	ErrWSNotAServerCtx      = errors.New("failed to create server WebSocket client: specified context is not a server context")
-)
+// WebSocket creates a new Synse WebSocket client for communicating with
+// Synse Server via its WebSocket API.
+func (f *contextClients) WebSocket() (synse.Client, error) {
+	serverContext, err := f.resolve("server", "WebSocket")
+	if err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := NewTLSConfig(serverContext.Context)
+	if err != nil {
+		return nil, err
+	}
+
+	headers, err := authHeaders(serverContext)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connections to a server on a unix socket are made to a placeholder
+	// host, with a dialer which dials the socket.
+	address := serverContext.Context.Address
+	socket, unix := config.UnixSocketPath(address)
+	if unix {
+		address = unixHost
+	}
+
+	scheme := "http"
+	if tlsConfig != nil {
+		scheme = "https"
+	}
+	dial, err := proxyDialer(serverContext.Context, scheme)
+	if err != nil {
+		return nil, err
+	}
 
-// NewSynseWebsocketClient creates a new Synse WebSocket client for communicating with
-// Synse Server over its WebSocket API. The CLI only uses this client for WebSocket-only
-// features, such as streaming live readings.
-func NewSynseWebsocketClient(ctx string, certFile string) (synse.Client, error) {
-	var serverContext *config.ContextRecord
-
-	if ctx == "" {
-		// If no specific context is given, get the current context.
-		currentContexts := config.GetCurrentContext()
-		serverContext = currentContexts["server"]
-		if serverContext == nil {
-			return nil, ErrWSNoCurrentServerCtx
-		}
-	} else {
-		// Get the names context.
-		serverContext = config.GetContext(ctx)
-		if serverContext == nil {
-			return nil, ErrWSInvalidServerCtx
-		}
-		if serverContext.Type != "server" {
-			return nil, ErrWSNotAServerCtx
-		}
+	if unix {
+		dial = unixDialer(socket)
 	}
 
-	return synse.NewWebSocketClientV3(&synse.Options{
-		Address: serverContext.Context.Address,
-		TLS: synse.TLSOptions{
-			CertFile: certFile,
-		},
-	})
+	return newWebSocketServerClient(serverClientOptions{
+		address:   address,
+		timeout:   f.opts.Timeout,
+		tlsConfig: tlsConfig,
+		header:    headers,
+	}, dial)
 }
@@ -0,0 +1,370 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"github.com/gorilla/websocket"
+	"github.com/mitchellh/mapstructure"
+	"github.com/pkg/errors"
+	"github.com/vapor-ware/synse-client-go/synse"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+// The Synse Server WebSocket API events, keyed by request event, with the
+// event of their response.
+var serverEvents = map[string]string{
+	"request/status":        "response/status",
+	"request/version":       "response/version",
+	"request/config":        "response/config",
+	"request/plugin":        "response/plugin_info",
+	"request/plugins":       "response/plugin_summary",
+	"request/plugin_health": "response/plugin_health",
+	"request/scan":          "response/device_summary",
+	"request/tags":          "response/tags",
+	"request/info":          "response/device_info",
+	"request/read":          "response/reading",
+	"request/read_device":   "response/reading",
+	"request/read_cache":    "response/reading",
+	"request/read_stream":   "response/reading",
+	"request/write_async":   "response/transaction_info",
+	"request/write_sync":    "response/transaction_status",
+	"request/transactions":  "response/transaction_list",
+	"request/transaction":   "response/transaction_status",
+}
+
+// serverErrorEvent is the event of an error response from the server.
+const serverErrorEvent = "response/error"
+
+// serverRequest is a request event for the Synse Server WebSocket API.
+type serverRequest struct {
+	ID    uint64      `json:"id"`
+	Event string      `json:"event"`
+	Data  interface{} `json:"data,omitempty"`
+}
+
+// websocketServerClient is a Synse Server client for the WebSocket API.
+type websocketServerClient struct {
+	opts    serverClientOptions
+	dialer  *websocket.Dialer
+	conn    *websocket.Conn
+	counter uint64
+	scheme  string
+}
+
+// newWebSocketServerClient creates a Synse Server WebSocket client which
+// connects with the dial function. If it is nil, the connection is direct.
+func newWebSocketServerClient(opts serverClientOptions, dial dialFunc) (synse.Client, error) {
+	if opts.address == "" {
+		return nil, errors.New("failed to create a websocket client: no address is specified")
+	}
+
+	dialer := &websocket.Dialer{
+		HandshakeTimeout: opts.handshakeTimeout(),
+		TLSClientConfig:  opts.tlsConfig,
+	}
+	if dial != nil {
+		dialer.NetDialContext = dial
+	}
+
+	scheme := "ws"
+	if opts.tlsConfig != nil {
+		scheme = "wss"
+	}
+	return &websocketServerClient{
+		opts:   opts,
+		dialer: dialer,
+		scheme: scheme,
+	}, nil
+}
+
+// Open opens the WebSocket connection to the server.
+func (c *websocketServerClient) Open() error {
+	conn, _, err := c.dialer.Dial(c.opts.serverURL(c.scheme, true, serverConnectURI), handshakeHeaders(c.opts.header))
+	if err != nil {
+		return errors.Wrap(err, "failed to open the websocket connection")
+	}
+	c.conn = conn
+	return nil
+}
+
+// Close closes the WebSocket connection to the server, if it is open.
+func (c *websocketServerClient) Close() error {
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.WriteMessage(
+		websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to close the connection gracefully")
+	}
+	return nil
+}
+
+// GetOptions returns the options of the client.
+func (c *websocketServerClient) GetOptions() *synse.Options {
+	return c.opts.options()
+}
+
+// Status returns the status info.
+func (c *websocketServerClient) Status() (*scheme.Status, error) {
+	out := new(scheme.Status)
+	if err := c.request("request/status", nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Version returns the version info.
+func (c *websocketServerClient) Version() (*scheme.Version, error) {
+	out := new(scheme.Version)
+	if err := c.request("request/version", nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Config returns the config info.
+func (c *websocketServerClient) Config() (*scheme.Config, error) {
+	out := new(scheme.Config)
+	if err := c.request("request/config", nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Plugins returns the summary of all plugins registered with the server.
+func (c *websocketServerClient) Plugins() ([]*scheme.PluginMeta, error) {
+	var out []*scheme.PluginMeta
+	if err := c.request("request/plugins", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Plugin returns data from a specific plugin.
+func (c *websocketServerClient) Plugin(id string) (*scheme.Plugin, error) {
+	out := new(scheme.Plugin)
+	if err := c.request("request/plugin", scheme.PluginData{Plugin: id}, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// PluginHealth returns the summary of the health of registered plugins.
+func (c *websocketServerClient) PluginHealth() (*scheme.PluginHealth, error) {
+	out := new(scheme.PluginHealth)
+	if err := c.request("request/plugin_health", nil, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Scan returns the devices which the server knows about.
+func (c *websocketServerClient) Scan(opts scheme.ScanOptions) ([]*scheme.Scan, error) {
+	var out []*scheme.Scan
+	if err := c.request("request/scan", opts, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Tags returns the tags currently associated with devices.
+func (c *websocketServerClient) Tags(opts scheme.TagsOptions) ([]string, error) {
+	var out []string
+	if err := c.request("request/tags", opts, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Info returns the meta info and capabilities of a specific device.
+func (c *websocketServerClient) Info(device string) (*scheme.Info, error) {
+	out := new(scheme.Info)
+	if err := c.request("request/info", scheme.DeviceData{Device: device}, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Read returns data from the devices which match the options.
+func (c *websocketServerClient) Read(opts scheme.ReadOptions) ([]*scheme.Read, error) {
+	var out []*scheme.Read
+	if err := c.request("request/read", opts, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadDevice returns data from a specific device.
+func (c *websocketServerClient) ReadDevice(device string) ([]*scheme.Read, error) {
+	var out []*scheme.Read
+	if err := c.request("request/read_device", scheme.ReadDeviceData{Device: device}, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ReadCache returns cached reading data from the registered plugins.
+func (c *websocketServerClient) ReadCache(opts scheme.ReadCacheOptions, out chan<- *scheme.Read) error {
+	defer close(out)
+
+	var readings []*scheme.Read
+	if err := c.request("request/read_cache", opts, &readings); err != nil {
+		return err
+	}
+	for _, r := range readings {
+		out <- r
+	}
+	return nil
+}
+
+// ReadStream streams current reading data from the registered plugins to
+// the channel, until the stop channel is closed.
+func (c *websocketServerClient) ReadStream(opts scheme.ReadStreamOptions, out chan<- *scheme.Read, stop chan struct{}) error {
+	req := c.newRequest("request/read_stream", opts)
+	if err := c.conn.WriteJSON(req); err != nil {
+		return errors.Wrap(errors.Wrap(err, "failed to send request"), "failed to stream reading data")
+	}
+
+	for {
+		select {
+		case _, open := <-stop:
+			if !open {
+				// The client has stopped listening for readings, but the
+				// server must be told to stop sending them as well.
+				stopReq := c.newRequest("request/read_stream", scheme.ReadStreamOptions{Stop: true})
+				if err := c.conn.WriteJSON(stopReq); err != nil {
+					return errors.Wrap(err, "failed to stop server-side read stream")
+				}
+				return nil
+			}
+		default:
+		}
+
+		read := new(scheme.Read)
+		if err := c.readResponse(req, read); err != nil {
+			return errors.Wrap(err, "failed to stream reading data")
+		}
+		out <- read
+	}
+}
+
+// WriteAsync writes data to a device, without waiting for it to complete.
+func (c *websocketServerClient) WriteAsync(device string, opts []scheme.WriteData) ([]*scheme.Write, error) {
+	var out []*scheme.Write
+	if err := c.request("request/write_async", scheme.RequestWriteData{Device: device, Payload: opts}, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WriteSync writes data to a device, waiting for the write to complete.
+func (c *websocketServerClient) WriteSync(device string, opts []scheme.WriteData) ([]*scheme.Transaction, error) {
+	var out []*scheme.Transaction
+	if err := c.request("request/write_sync", scheme.RequestWriteData{Device: device, Payload: opts}, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Transactions returns the sorted list of all cached transaction IDs.
+func (c *websocketServerClient) Transactions() ([]string, error) {
+	var out []string
+	if err := c.request("request/transactions", nil, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Transaction returns the state and status of a write transaction.
+func (c *websocketServerClient) Transaction(id string) (*scheme.Transaction, error) {
+	out := new(scheme.Transaction)
+	if err := c.request("request/transaction", scheme.WriteData{Transaction: id}, out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// newRequest creates a request event with the next request ID.
+func (c *websocketServerClient) newRequest(event string, data interface{}) serverRequest {
+	return serverRequest{
+		ID:    atomic.AddUint64(&c.counter, 1),
+		Event: event,
+		Data:  data,
+	}
+}
+
+// request sends a request event and decodes the data of its response into
+// the given value.
+func (c *websocketServerClient) request(event string, data interface{}, out interface{}) error {
+	if c.conn == nil {
+		return errors.New("websocket connection is not open")
+	}
+	req := c.newRequest(event, data)
+	if err := c.conn.WriteJSON(req); err != nil {
+		return errors.Wrap(err, "failed to write to connection")
+	}
+	return c.readResponse(req, out)
+}
+
+// readResponse reads the response to the request, decoding its data into
+// the given value.
+func (c *websocketServerClient) readResponse(req serverRequest, out interface{}) error {
+	var resp scheme.Response
+	if err := c.conn.ReadJSON(&resp); err != nil {
+		return errors.Wrap(err, "failed to read response message")
+	}
+
+	if resp.Event == serverErrorEvent {
+		e := new(scheme.Error)
+		if err := mapstructure.Decode(resp.Data, e); err != nil {
+			return errors.Wrap(err, "failed to decode map into a proper scheme")
+		}
+		return serverError(e)
+	}
+	if resp.ID != req.ID {
+		return errors.Errorf("response id mismatch: %v != %v", req.ID, resp.ID)
+	}
+	if expected := serverEvents[req.Event]; resp.Event != expected {
+		return errors.Errorf("(%v) %v did not match %v", req.Event, expected, resp.Event)
+	}
+
+	if err := mapstructure.Decode(resp.Data, out); err != nil {
+		return errors.Wrap(err, "failed to decode map into a proper scheme")
+	}
+	return nil
+}
+
+// handshakeHeaders gets the headers to add to the websocket handshake
+// request. The dialer sets the headers of the handshake itself and fails if
+// they are given, so those are left out.
+func handshakeHeaders(headers http.Header) http.Header {
+	out := http.Header{}
+	for name, values := range headers {
+		switch name {
+		case "Upgrade", "Connection", "Sec-Websocket-Key", "Sec-Websocket-Version", "Sec-Websocket-Extensions":
+			continue
+		}
+		out[name] = values
+	}
+	return out
+}
@@ -0,0 +1,191 @@
+// Synse CLI
+// Copyright (c) 2019 Vapor IO
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program. If not, see <http://www.gnu.org/licenses/>.
+
+package utils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/vapor-ware/synse-client-go/synse/scheme"
+)
+
+// newTestWebSocketServerClient creates a WebSocket client, with an open
+// connection, for a test server which replies to each request with the
+// responses from the respond function.
+func newTestWebSocketServerClient(t *testing.T, respond func(req serverRequest) []scheme.Response) *websocketServerClient {
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/connect", r.URL.Path)
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			var req serverRequest
+			if err := conn.ReadJSON(&req); err != nil {
+				return
+			}
+			for _, resp := range respond(req) {
+				if err := conn.WriteJSON(resp); err != nil {
+					return
+				}
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := newWebSocketServerClient(serverClientOptions{
+		address: strings.TrimPrefix(server.URL, "http://"),
+	}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Open())
+	t.Cleanup(func() { _ = client.Close() })
+	return client.(*websocketServerClient)
+}
+
+func TestWebSocketServerClient_noAddress(t *testing.T) {
+	_, err := newWebSocketServerClient(serverClientOptions{}, nil)
+	assert.EqualError(t, err, "failed to create a websocket client: no address is specified")
+}
+
+func TestWebSocketServerClient_Status(t *testing.T) {
+	client := newTestWebSocketServerClient(t, func(req serverRequest) []scheme.Response {
+		assert.Equal(t, "request/status", req.Event)
+		return []scheme.Response{{
+			EventMeta: scheme.EventMeta{ID: req.ID, Event: "response/status"},
+			Data:      map[string]interface{}{"status": "ok"},
+		}}
+	})
+
+	status, err := client.Status()
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", status.Status)
+}
+
+func TestWebSocketServerClient_Scan(t *testing.T) {
+	client := newTestWebSocketServerClient(t, func(req serverRequest) []scheme.Response {
+		assert.Equal(t, "request/scan", req.Event)
+		assert.Equal(t, map[string]interface{}{"tags": []interface{}{"default/foo"}}, req.Data)
+		return []scheme.Response{{
+			EventMeta: scheme.EventMeta{ID: req.ID, Event: "response/device_summary"},
+			Data:      []interface{}{map[string]interface{}{"id": "1"}},
+		}}
+	})
+
+	devices, err := client.Scan(scheme.ScanOptions{Tags: []string{"default/foo"}})
+	assert.NoError(t, err)
+	assert.Len(t, devices, 1)
+	assert.Equal(t, "1", devices[0].ID)
+}
+
+func TestWebSocketServerClient_errorResponse(t *testing.T) {
+	client := newTestWebSocketServerClient(t, func(req serverRequest) []scheme.Response {
+		return []scheme.Response{{
+			EventMeta: scheme.EventMeta{ID: req.ID, Event: "response/error"},
+			Data:      map[string]interface{}{"http_code": 404, "description": "device not found", "timestamp": "now", "context": "123"},
+		}}
+	})
+
+	_, err := client.Info("123")
+	assert.EqualError(t, err, "got a 404 error response from synse server at now, saying device not found, with context: 123")
+}
+
+func TestWebSocketServerClient_mismatchedResponse(t *testing.T) {
+	client := newTestWebSocketServerClient(t, func(req serverRequest) []scheme.Response {
+		return []scheme.Response{{
+			EventMeta: scheme.EventMeta{ID: req.ID + 1, Event: "response/version"},
+		}}
+	})
+
+	_, err := client.Version()
+	assert.EqualError(t, err, "response id mismatch: 1 != 2")
+}
+
+func TestWebSocketServerClient_ReadStream(t *testing.T) {
+	// The server sends readings until the stream is stopped.
+	stopped := make(chan struct{})
+	var upgrader websocket.Upgrader
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var req serverRequest
+		if err := conn.ReadJSON(&req); err != nil {
+			return
+		}
+		assert.Equal(t, "request/read_stream", req.Event)
+		go func() {
+			for {
+				select {
+				case <-stopped:
+					return
+				default:
+				}
+				err := conn.WriteJSON(scheme.Response{
+					EventMeta: scheme.EventMeta{ID: req.ID, Event: "response/reading"},
+					Data:      map[string]interface{}{"device": "1"},
+				})
+				if err != nil {
+					return
+				}
+			}
+		}()
+
+		var stopReq serverRequest
+		if err := conn.ReadJSON(&stopReq); err != nil {
+			return
+		}
+		assert.Equal(t, map[string]interface{}{"stop": true}, stopReq.Data)
+		close(stopped)
+	}))
+	defer server.Close()
+
+	client, err := newWebSocketServerClient(serverClientOptions{
+		address: strings.TrimPrefix(server.URL, "http://"),
+	}, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, client.Open())
+
+	readings := make(chan *scheme.Read)
+	stop := make(chan struct{})
+	errs := make(chan error, 1)
+	go func() {
+		errs <- client.ReadStream(scheme.ReadStreamOptions{}, readings, stop)
+	}()
+
+	assert.Equal(t, "1", (<-readings).Device)
+	close(stop)
+
+	// Drain the reading the stream may be blocked sending.
+	go func() {
+		for range readings {
+		}
+	}()
+
+	// Once stopped, the server is told to stop sending readings.
+	assert.NoError(t, <-errs)
+	<-stopped
+	close(readings)
+}